@@ -0,0 +1,133 @@
+package coalition
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"sync"
+)
+
+// AliasProvider supplies other names for organizations,
+// such as "Google" for "Alphabet" or "Facebook" for "Meta,"
+// which can't be deduced from the names themselves.
+type AliasProvider interface {
+	// Aliases returns other names for the organization
+	// whose normalized root phrase is norm.
+	// The results are reference strings,
+	// which the Matcher normalizes before use.
+	Aliases(norm []string) []string
+}
+
+// AliasMap is a simple AliasProvider.
+// Its keys are normalized root phrases,
+// with the words separated by spaces.
+type AliasMap map[string][]string
+
+// Aliases implements AliasProvider.
+func (a AliasMap) Aliases(norm []string) []string {
+	return a[strings.Join(norm, " ")]
+}
+
+// Add records that the given names all refer to the same organization.
+// Each name becomes an alias of each of the others.
+// Keys are normalized with a default Matcher's normalization.
+// A Matcher that normalizes differently
+// (as with LegalForms or its own Stop)
+// still finds them,
+// since it also looks up each reference with that normalization
+// (see Matcher.Aliases).
+func (a AliasMap) Add(names ...string) {
+	for _, name := range names {
+		key := strings.Join(defaultMatcher.normalizedRootPhrase(name), " ")
+		for _, other := range names {
+			if other != name {
+				a[key] = append(a[key], other)
+			}
+		}
+	}
+}
+
+// ReadAliasMap reads an AliasMap from r.
+// Each line of the input lists names that refer to the same organization,
+// separated by vertical bars,
+// as in:
+//
+//	Alphabet Inc. | Google
+//
+// Blank lines and lines beginning with # are ignored.
+func ReadAliasMap(r io.Reader) (AliasMap, error) {
+	result := make(AliasMap)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var names []string
+		for _, name := range strings.Split(line, "|") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+		result.Add(names...)
+	}
+	return result, sc.Err()
+}
+
+// This returns the aliases of in.ref from m.Aliases,
+// looked up by in.norm
+// and also by the default normalization of in.ref,
+// which is how AliasMap.Add keys them.
+func (m Matcher) aliases(in *matchInput) []string {
+	result := m.Aliases.Aliases(in.norm)
+
+	def := defaultMatcher.normalizedRootPhrase(in.ref)
+	if strings.Join(def, " ") == strings.Join(in.norm, " ") {
+		return result
+	}
+	seen := make(map[string]bool)
+	for _, alias := range result {
+		seen[alias] = true
+	}
+	for _, alias := range m.Aliases.Aliases(def) {
+		if !seen[alias] {
+			seen[alias] = true
+			result = append(result, alias)
+		}
+	}
+	return result
+}
+
+// domainMemo holds the results of work for a match
+// that depends on the domain but not on the reference,
+// so that the tests for the reference's aliases can reuse it
+// (see Matcher.runAllTests),
+// as they share the fetch of the home page.
+// Its keys are test types,
+// for the outcomes of tests marked perDomain,
+// and other comparable values,
+// like securityTxtKey,
+// for data that tests fetch.
+// Errors are not kept.
+type domainMemo struct {
+	mu      sync.Mutex
+	results map[interface{}]interface{}
+}
+
+func newDomainMemo() *domainMemo {
+	return &domainMemo{results: make(map[interface{}]interface{})}
+}
+
+func (d *domainMemo) get(key interface{}) (interface{}, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	val, ok := d.results[key]
+	return val, ok
+}
+
+func (d *domainMemo) set(key, val interface{}) {
+	d.mu.Lock()
+	d.results[key] = val
+	d.mu.Unlock()
+}
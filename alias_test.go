@@ -0,0 +1,141 @@
+package coalition
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAliases(t *testing.T) {
+	const aliases = `
+# Parent companies and their brands.
+Alphabet Inc. | Google
+Meta Platforms, Inc. | Facebook | Instagram
+`
+
+	aliasMap, err := ReadAliasMap(strings.NewReader(aliases))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		ref, domain   string
+		without, with int
+	}{
+		{ref: "Alphabet, Inc.", domain: "google.com", without: 0, with: 50},
+		{ref: "Google", domain: "alphabet.com", without: 0, with: 50},
+		{ref: "Meta Platforms", domain: "instagram.com", without: 0, with: 50},
+		{ref: "Meta Platforms", domain: "metaplatforms.com", without: 50, with: 50},
+		{ref: "Coalition, Inc", domain: "google.com", without: 0, with: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.ref+"_"+c.domain, func(t *testing.T) {
+			matcher := NewMatcher()
+			delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+			got, err := matcher.doMatch(context.Background(), c.ref, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.without {
+				t.Errorf("without aliases: got %d, want %d", got, c.without)
+			}
+
+			matcher.Aliases = aliasMap
+			got, err = matcher.doMatch(context.Background(), c.ref, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.with {
+				t.Errorf("with aliases: got %d, want %d", got, c.with)
+			}
+		})
+	}
+}
+
+func TestAliasNormalization(t *testing.T) {
+	aliasMap := make(AliasMap)
+	aliasMap.Add("Alphabet Inc.", "Google")
+
+	// This Matcher keeps "inc",
+	// so its normalization of "Alphabet Inc." differs from the key.
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+	matcher.Stop = simpleStopper{}
+	matcher.Aliases = aliasMap
+
+	got, err := matcher.doMatch(context.Background(), "Alphabet Inc.", "google.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 50 {
+		t.Errorf("got %d, want 50", got)
+	}
+}
+
+// countingScorer counts its calls.
+type countingScorer struct {
+	calls int32
+}
+
+func (s *countingScorer) Score(context.Context, string, string) (int, bool, error) {
+	atomic.AddInt32(&s.calls, 1)
+	return 50, true, nil
+}
+
+// countingWhois counts its calls.
+type countingWhois struct {
+	calls int32
+}
+
+func (w *countingWhois) CreationDate(context.Context, string) (time.Time, error) {
+	atomic.AddInt32(&w.calls, 1)
+	return time.Now(), nil
+}
+
+func TestAliasesShareResults(t *testing.T) {
+	aliasMap := make(AliasMap)
+	aliasMap.Add("Meta Platforms, Inc.", "Facebook", "Instagram")
+
+	var (
+		scorer countingScorer
+		whois  countingWhois
+	)
+	matcher := NewMatcher().WithScore(TestExternal, 10).WithScore(TestNewDomain, -20)
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+	matcher.Aliases = aliasMap
+	matcher.External = &scorer
+	matcher.Whois = &whois
+	matcher.MaxRequestsPerMatch = 2
+
+	detail, err := matcher.MatchDetail(context.Background(), "Meta Platforms", "instagram.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.Ref != "Instagram" {
+		t.Errorf("got ref %s, want Instagram", detail.Ref)
+	}
+	if scorer.calls != 1 {
+		t.Errorf("got %d calls to the External scorer, want 1", scorer.calls)
+	}
+	if whois.calls != 1 {
+		t.Errorf("got %d WHOIS lookups, want 1", whois.calls)
+	}
+
+	// The alias's outcomes include the shared results,
+	// without exhausting the request budget.
+	want := []string{"NewDomain passed (-20)", "External passed (+5)"}
+	var got []string
+	for _, o := range detail.Outcomes {
+		if o.Test == "External" || o.Test == "NewDomain" {
+			got = append(got, o.Reason())
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,62 @@
+package coalition
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// ArchiveProvider is an application-supplied source of archived web pages,
+// such as the Wayback Machine,
+// that the ArchivedPageRef test consults.
+// See Matcher.Archive.
+type ArchiveProvider interface {
+	// Snapshot returns the HTML of an archived copy of the page at pageURL,
+	// the one nearest the given time
+	// (or the most recent, if that's zero).
+	// The boolean result is false if there is no copy,
+	// in which case the test does not pass.
+	Snapshot(ctx context.Context, pageURL string, at time.Time) ([]byte, bool, error)
+}
+
+func runArchivedPageRefTest(ctx context.Context, m Matcher, in *matchInput) (bool, error) {
+	if m.Archive == nil {
+		return false, nil
+	}
+	snapshot, err := m.archiveSnapshot(ctx, in)
+	if err != nil || snapshot == nil {
+		return false, err
+	}
+
+	// As with the live page,
+	// a snapshot that can't be parsed simply doesn't pass.
+	tree, err := html.Parse(bytes.NewReader(snapshot))
+	if err != nil {
+		return false, nil
+	}
+	return doWebPageRefTest(&webPage{tree: tree}, m.PageRegions, m.textMatcher(in, in.re)), nil
+}
+
+// archiveKey is the key in a domainMemo
+// for the archived snapshot of the domain's home page.
+type archiveKey struct{}
+
+// This returns the snapshot of in.webURL from m.Archive,
+// or nil if it has none,
+// getting it only once for a reference and its aliases.
+func (m Matcher) archiveSnapshot(ctx context.Context, in *matchInput) ([]byte, error) {
+	if snapshot, ok := in.memo.get(archiveKey{}); ok {
+		return snapshot.([]byte), nil
+	}
+	snapshot, ok, err := m.Archive.Snapshot(ctx, in.webURL.String(), m.ArchiveTime)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		snapshot = nil
+	}
+	in.memo.set(archiveKey{}, snapshot)
+	return snapshot, nil
+}
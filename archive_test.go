@@ -0,0 +1,90 @@
+package coalition
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeArchive serves snapshots from a map of URLs to their archived copies,
+// recording the time of each request.
+type fakeArchive struct {
+	snapshots map[string]string
+	times     []time.Time
+}
+
+func (a *fakeArchive) Snapshot(_ context.Context, pageURL string, at time.Time) ([]byte, bool, error) {
+	a.times = append(a.times, at)
+	if pageURL == "http://broken.example/" {
+		return nil, false, errors.New("archive unavailable")
+	}
+	s, ok := a.snapshots[pageURL]
+	return []byte(s), ok, nil
+}
+
+func TestArchivedPageRefTest(t *testing.T) {
+	archive := &fakeArchive{
+		snapshots: map[string]string{
+			"http://oldbrand.example/": "<html><body>Welcome to coalition</body></html>",
+			"http://acme.example/":     "<html><body>Welcome to acme</body></html>",
+		},
+	}
+	at := time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		domain  string
+		want    int
+		wantErr bool
+	}{
+		{domain: "oldbrand.example", want: 20},
+		{domain: "acme.example", want: 0},
+		{domain: "missing.example", want: 0},
+		{domain: "broken.example", wantErr: true},
+	}
+
+	matcher := NewMatcher()
+	matcher.Scores = map[testType]int{testArchivedPageRef: 20}
+	matcher.Archive = archive
+	matcher.ArchiveTime = at
+
+	for _, c := range cases {
+		t.Run(c.domain, func(t *testing.T) {
+			got, err := matcher.doMatch(context.Background(), "Coalition", c.domain)
+			if c.wantErr {
+				if err == nil {
+					t.Error("got no error, want one")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+
+	for _, got := range archive.times {
+		if !got.Equal(at) {
+			t.Errorf("got snapshot time %s, want %s", got, at)
+		}
+	}
+
+	t.Run("default_off", func(t *testing.T) {
+		matcher := NewMatcher()
+		delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+		matcher.Archive = archive
+
+		_, detail, err := matcher.doMatchDetail(context.Background(), "Coalition", "oldbrand.example")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, o := range detail.Outcomes {
+			if o.Test == string(TestArchivedPageRef) {
+				t.Errorf("got an ArchivedPageRef outcome %+v, want none", o)
+			}
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package coalition
+
+import "context"
+
+// MatchAtLeast tells whether the result of matching ref against domain
+// (as with Match)
+// is at least threshold.
+// It stops early once the string tests decide the question either way,
+// without running the network tests:
+// when their score reaches threshold
+// even if every network test with a negative score passes,
+// or can't reach it
+// even if every network test with a positive score passes.
+// This is faster than Match,
+// but yields no exact result.
+func (m Matcher) MatchAtLeast(ref, domain string, threshold float32) (bool, error) {
+	return m.MatchAtLeastContext(context.Background(), ref, domain, threshold)
+}
+
+// MatchAtLeastContext is like MatchAtLeast but takes a context
+// (see MatchContext).
+func (m Matcher) MatchAtLeastContext(ctx context.Context, ref, domain string, threshold float32) (bool, error) {
+	m.atLeast = &threshold
+	raw, err := m.doMatch(ctx, ref, domain)
+	if err != nil {
+		return false, err
+	}
+	return m.scale(raw) >= threshold, nil
+}
+
+// atLeastDecided tells whether the early string tests among tests
+// (see earlyTests)
+// decide whether the result reaches m.atLeast,
+// whatever the remaining tests do.
+// It is false if m.atLeast is nil
+// (i.e., outside MatchAtLeast).
+// Earned must be safe to read for all the early tests.
+func (m Matcher) atLeastDecided(in *matchInput, tests []testDef, early []bool, earned []float32) bool {
+	if m.atLeast == nil {
+		return false
+	}
+	var (
+		subtotal = m.earlySubtotal(tests, early, earned)
+		lo, hi   = subtotal, subtotal
+	)
+	for i, t := range tests {
+		if early[i] {
+			continue
+		}
+		if score := m.Scores[t.typ]; score < 0 {
+			lo += score
+		} else {
+			hi += score
+		}
+	}
+	return m.scale(m.applyTLDWeight(lo, in)) >= *m.atLeast || m.scale(m.applyTLDWeight(hi, in)) < *m.atLeast
+}
@@ -0,0 +1,61 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestMatchAtLeast(t *testing.T) {
+	// Default scores range from -10 to 110,
+	// so a threshold of 0.5 requires a raw score of 50,
+	// 0.6 one of 62,
+	// and 0.9 one of 98.
+	cases := []struct {
+		ref       string
+		threshold float32
+		want      bool
+		wantFetch bool
+	}{
+		{ref: "Coalition", threshold: 0.5, want: true, wantFetch: false},            // RootPhrase: 50
+		{ref: "Acme", threshold: 0.6, want: false, wantFetch: false},                // at most 50 with WebPageRef
+		{ref: "Coalition Rutabaga", threshold: 0.5, want: true, wantFetch: true},    // AnyRootWord: 5, plus WebPageRef
+		{ref: "Acme", threshold: 0.5, want: false, wantFetch: true},                 // WebPageRef could reach 50, but doesn't
+		{ref: "Coalition", threshold: 0.9, want: true, wantFetch: true},             // RootPhrase and WebPageRef: 100
+		{ref: "Coalition Rutabaga", threshold: 0.95, want: false, wantFetch: false}, // at most 55 with WebPageRef
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case_%02d", i+1), func(t *testing.T) {
+			rec := &RequestRecorder{
+				Responses: map[string]CannedResponse{
+					"http://coalition.com/": {ContentType: "text/html", Body: "<html><body>coalition rutabaga</body></html>"},
+				},
+			}
+
+			matcher := NewMatcher()
+			matcher.Client = &http.Client{Transport: rec}
+
+			got, err := matcher.MatchAtLeast(c.ref, "coalition.com", c.threshold)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+			if gotFetch := len(rec.Requests()) > 0; gotFetch != c.wantFetch {
+				t.Errorf("got fetch %v, want %v", gotFetch, c.wantFetch)
+			}
+
+			// The answer agrees with the full match.
+			score, err := matcher.MatchContext(context.Background(), c.ref, "coalition.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if full := score >= c.threshold; full != c.want {
+				t.Errorf("got %v from the full match (%v), want %v", full, score, c.want)
+			}
+		})
+	}
+}
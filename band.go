@@ -0,0 +1,43 @@
+package coalition
+
+// ScoreBand is a range of raw scores
+// (see Matcher.Score),
+// from Min to Max inclusive.
+type ScoreBand struct {
+	Min, Max int
+}
+
+// Contains tells whether score is within b.
+func (b ScoreBand) Contains(score int) bool {
+	return b.Min <= score && score <= b.Max
+}
+
+// isDecisive tells whether the early string tests among tests
+// (see earlyTests)
+// have a total score outside m.AmbiguousBand,
+// or one that decides a MatchAtLeast (see atLeastDecided),
+// so that the network tests with positive scores need not run.
+// It is false if there is no band,
+// or no early tests to decide,
+// and under m.MinPassingTests
+// (as with isSettled).
+// Earned must be safe to read for all the early tests.
+func (m Matcher) isDecisive(in *matchInput, tests []testDef, early []bool, earned []float32) bool {
+	if m.MinPassingTests > 0 {
+		return false
+	}
+	if m.atLeastDecided(in, tests, early, earned) {
+		return true
+	}
+	if m.AmbiguousBand == nil {
+		return false
+	}
+	var any bool
+	for _, e := range early {
+		any = any || e
+	}
+	if !any {
+		return false
+	}
+	return !m.AmbiguousBand.Contains(m.earlySubtotal(tests, early, earned))
+}
@@ -0,0 +1,55 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestAmbiguousBand(t *testing.T) {
+	// Default scores: RootPhrase 50, AnyRootWord 5, SignificantAffixes -10, and so on.
+	// The band covers the partial matches.
+	band := &ScoreBand{Min: 1, Max: 45}
+
+	cases := []struct {
+		ref       string
+		band      *ScoreBand
+		wantFetch bool
+	}{
+		{ref: "Coalition", band: band, wantFetch: false},         // RootPhrase: 50, decisive
+		{ref: "Acme", band: band, wantFetch: false},              // nothing: 0, decisive
+		{ref: "Coalition Rutabaga", band: band, wantFetch: true}, // AnyRootWord: 5, ambiguous
+		{ref: "Coalition", wantFetch: true},                      // no band
+		{ref: "Acme", wantFetch: true},                           // no band
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case_%02d", i+1), func(t *testing.T) {
+			rec := &RequestRecorder{
+				Responses: map[string]CannedResponse{
+					"http://coalition.com/": {ContentType: "text/html", Body: "<html><body>coalition</body></html>"},
+				},
+			}
+
+			matcher := NewMatcher()
+			matcher.Client = &http.Client{Transport: rec}
+			matcher.AmbiguousBand = c.band
+
+			detail, err := matcher.MatchDetail(context.Background(), c.ref, "coalition.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(rec.Requests()) > 0; got != c.wantFetch {
+				t.Errorf("got fetch %v, want %v (%v)", got, c.wantFetch, detail.Reasons())
+			}
+			if !c.wantFetch {
+				for _, o := range detail.Outcomes {
+					if o.Test == string(TestWebPageRef) && !o.Decided {
+						t.Errorf("got %q, want WebPageRef decided by the string tests", o.Reason())
+					}
+				}
+			}
+		})
+	}
+}
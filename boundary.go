@@ -0,0 +1,73 @@
+package coalition
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultWordBoundaryLength is the length,
+// in characters,
+// up to which a root phrase or word must align with word boundaries in a domain
+// (see Matcher.WordBoundaryLength)
+// when Matcher.WordBoundaryLength is zero.
+// Short brands like "Visa" are often found inside unrelated words like "visage".
+const DefaultWordBoundaryLength = 4
+
+// minBoundaryWordLength is the length,
+// in characters,
+// of the shortest text beside an aligned root phrase or word,
+// in the same domain token,
+// that counts as a separate word
+// (unless it is made of stop words).
+// Anything shorter is taken to be the rest of a longer word,
+// as with the "ge" in "visage".
+const minBoundaryWordLength = 3
+
+// This reports whether domain contains s,
+// as a plain substring if s is longer than m's word-boundary length
+// (see Matcher.WordBoundaryLength),
+// and otherwise only where it aligns with word boundaries
+// (see alignsWithWords).
+func (m Matcher) containsWord(domain, s string) bool {
+	limit := m.WordBoundaryLength
+	if limit == 0 {
+		limit = DefaultWordBoundaryLength
+	}
+	if utf8.RuneCountInString(s) > limit {
+		return strings.Contains(domain, s)
+	}
+	return m.alignsWithWords(domain, s)
+}
+
+// This reports whether s occurs in a token of one of the labels of domain
+// (see DomainTokenizer)
+// with nothing beside it in the token but stop words
+// or text long enough to be a separate word
+// (see minBoundaryWordLength),
+// as in "visa.com", "visa-card.com", and "visacard.com" for "visa",
+// but not "visage.com".
+func (m Matcher) alignsWithWords(domain, s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, label := range strings.Split(domain, ".") {
+		for _, token := range m.domainTokens(label) {
+			for i := 0; i+len(s) <= len(token); i++ {
+				if !strings.HasPrefix(token[i:], s) {
+					continue
+				}
+				if m.isWordRemainder(token[:i]) && m.isWordRemainder(token[i+len(s):]) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// This reports whether the text beside an aligned root phrase or word
+// in a domain token
+// is empty, made of stop words, or long enough to be a word of its own.
+func (m Matcher) isWordRemainder(s string) bool {
+	return s == "" || utf8.RuneCountInString(s) >= minBoundaryWordLength || m.isStopWordRun(s)
+}
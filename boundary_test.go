@@ -0,0 +1,72 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestWordBoundaries(t *testing.T) {
+	cases := []struct {
+		ref, domain string
+		limit       int
+		want        bool
+	}{
+		{ref: "Visa", domain: "visa.com", want: true},
+		{ref: "Visa", domain: "visacard.com", want: true},
+		{ref: "Visa", domain: "visa-card.com", want: true},
+		{ref: "Visa", domain: "visaco.com", want: true}, // "co" is a stop word
+		{ref: "Visa", domain: "visage.com", want: false},
+		{ref: "Visa", domain: "visas.com", want: false},
+		{ref: "Visa", domain: "evisa.com", want: false},
+		{ref: "Visa", domain: "visage.com", limit: -1, want: true},
+		{ref: "Visa", domain: "visage.com", limit: 3, want: true},
+		{ref: "Coalition", domain: "coalitions.com", want: true}, // too long to require alignment
+		{ref: "Coalition", domain: "coalitions.com", limit: 10, want: false},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%s_%s_%d", c.ref, c.domain, c.limit), func(t *testing.T) {
+			matcher := NewMatcher()
+			delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+			matcher.WordBoundaryLength = c.limit
+
+			_, detail, err := matcher.doMatchDetail(context.Background(), c.ref, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, o := range detail.Outcomes {
+				if o.Test == "RootPhrase" && o.Passed != c.want {
+					t.Errorf("got RootPhrase passed %v, want %v", o.Passed, c.want)
+				}
+			}
+		})
+	}
+
+	t.Run("any_root_word", func(t *testing.T) {
+		cases := []struct {
+			domain string
+			want   bool
+		}{
+			{domain: "visa-online.com", want: true},
+			{domain: "visage-online.com", want: false},
+		}
+
+		matcher := NewMatcher()
+		delete(matcher.Scores, testWebPageRef)
+
+		for _, c := range cases {
+			t.Run(c.domain, func(t *testing.T) {
+				_, detail, err := matcher.doMatchDetail(context.Background(), "Visa International Service", c.domain)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, o := range detail.Outcomes {
+					if o.Test == "AnyRootWord" && o.Passed != c.want {
+						t.Errorf("got AnyRootWord passed %v, want %v", o.Passed, c.want)
+					}
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package coalition
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/bobg/htree"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+func runBrandAssetTest(ctx context.Context, m Matcher, in *matchInput) (bool, error) {
+	page, err := in.homePage(ctx, m)
+	if err != nil {
+		return false, err
+	}
+	return doBrandAssetTest(page, m.textMatcher(in, in.re)), nil
+}
+
+func doBrandAssetTest(page *webPage, tm textMatcher) bool {
+	tree := page.htmlTree()
+	if tree == nil {
+		return false
+	}
+	for _, s := range brandAssetStrings(tree) {
+		if tm.match(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// brandAssetStrings returns the filenames of the icons linked from tree
+// and the alt text of its images.
+func brandAssetStrings(tree *html.Node) []string {
+	var result []string
+
+	isAsset := func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.Link:
+			return isIconRel(htree.ElAttr(n, "rel"))
+		case atom.Img:
+			return true
+		}
+		return false
+	}
+	htree.FindAllEls(tree, isAsset, func(n *html.Node) error {
+		if n.DataAtom == atom.Img {
+			if alt := strings.TrimSpace(htree.ElAttr(n, "alt")); alt != "" {
+				result = append(result, alt)
+			}
+			return nil
+		}
+
+		// Only the filename counts,
+		// not the host or directories it comes from.
+		u, err := url.Parse(strings.TrimSpace(htree.ElAttr(n, "href")))
+		if err != nil {
+			return nil
+		}
+		if name := path.Base(u.Path); name != "." && name != "/" {
+			result = append(result, name)
+		}
+		return nil
+	})
+
+	return result
+}
+
+// This tells whether the rel attribute of a <link> element
+// marks it as an icon,
+// as in "icon", "shortcut icon", or "apple-touch-icon".
+func isIconRel(rel string) bool {
+	for _, r := range strings.Fields(strings.ToLower(rel)) {
+		if r == "icon" || r == "apple-touch-icon" || r == "mask-icon" {
+			return true
+		}
+	}
+	return false
+}
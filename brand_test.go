@@ -0,0 +1,50 @@
+package coalition
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBrandAssetTest(t *testing.T) {
+	const page = `<html>
+<head>
+<link rel="shortcut icon" href="https://cdn.example.com/assets/coalition-security-favicon.ico?v=2">
+<link rel="stylesheet" href="/static/acme.css">
+</head>
+<body>
+<img src="/img/header.png" alt="Coalition Insurance logo">
+<img src="/img/spacer.gif">
+<img src="/img/partner.png" alt="SOCIÉTÉ GÉNÉRALE">
+<p>Cyber insurance for everyone</p>
+</body>
+</html>`
+
+	srv, domain := newTestServer("text/html", page)
+	defer srv.Close()
+
+	cases := []struct {
+		ref  string
+		want int
+	}{
+		{ref: "Coalition Insurance", want: 5}, // img alt
+		{ref: "Coalition Security", want: 5},  // favicon filename
+		{ref: "Acme", want: 0},                // stylesheet, not an icon
+		{ref: "Example", want: 0},             // host of the icon URL doesn't count
+		{ref: "Societe Generale", want: 5},    // folded like the reference
+	}
+
+	matcher := NewMatcher()
+	matcher.Scores = map[testType]int{testBrandAsset: 5}
+
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			got, err := matcher.doMatch(context.Background(), c.ref, domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package coalition
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// PageCache stores the web pages fetched by Matchers,
+// so that they can be reused instead of fetched again.
+// See Matcher.PageCache.
+//
+// Implementations must be safe for concurrent use:
+// a Matcher calls its PageCache from multiple goroutines at once,
+// and a single PageCache may be shared by many Matchers.
+type PageCache interface {
+	// Get returns the cached page for the given URL, if any.
+	// Callers must not modify the result.
+	Get(url string) (*CachedPage, bool)
+
+	// Put stores page as the result of fetching the given URL.
+	// The cache must not modify page.
+	Put(url string, page *CachedPage)
+}
+
+// CachedPage is the response to a web request,
+// as stored in a PageCache.
+type CachedPage struct {
+	// URL is the final URL of the page,
+	// after any redirects.
+	URL string
+
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// This returns an *http.Response equivalent to the one that p was made from.
+func (p *CachedPage) response() (*http.Response, error) {
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", p.StatusCode, http.StatusText(p.StatusCode)),
+		StatusCode:    p.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        p.Header,
+		Body:          ioutil.NopCloser(bytes.NewReader(p.Body)),
+		ContentLength: int64(len(p.Body)),
+		Request:       &http.Request{Method: "GET", URL: u},
+	}, nil
+}
+
+// maxCachedPageLen is the size of the largest response body
+// that a Matcher stores in its PageCache.
+// A larger page is read as if there were no cache,
+// so the tests that need only part of it
+// (see Matcher.HeadOnly and Matcher.StreamPageText)
+// still read only that part.
+const maxCachedPageLen = 1 << 20
+
+// This turns resp into a CachedPage,
+// consuming and closing its body.
+// If the body is longer than maxCachedPageLen,
+// the result is nil,
+// and resp's body is replaced with one that yields the whole of it,
+// so resp can be used as if it had not been read.
+func newCachedPage(resp *http.Response) (*CachedPage, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxCachedPageLen+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if len(body) > maxCachedPageLen {
+		resp.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), resp.Body), Closer: resp.Body}
+		return nil, nil
+	}
+	resp.Body.Close()
+
+	return &CachedPage{
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// MemoryPageCache is a PageCache that keeps pages in memory,
+// each for a limited time,
+// up to a limited number of pages
+// (discarding the least recently used ones first).
+// It is safe for concurrent use.
+// Create one with NewMemoryPageCache.
+type MemoryPageCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element // values are *memoryCacheEntry
+	lru     *list.List               // most recently used at the front
+}
+
+type memoryCacheEntry struct {
+	url     string
+	page    *CachedPage
+	expires time.Time
+}
+
+// NewMemoryPageCache returns a new MemoryPageCache
+// that keeps each page for ttl
+// and holds at most maxEntries pages.
+// A non-positive ttl or maxEntries means no limit.
+func NewMemoryPageCache(ttl time.Duration, maxEntries int) *MemoryPageCache {
+	return &MemoryPageCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Get implements PageCache.
+func (c *MemoryPageCache) Get(url string) (*CachedPage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[url]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*memoryCacheEntry)
+	if c.ttl > 0 && time.Now().After(entry.expires) {
+		c.lru.Remove(el)
+		delete(c.entries, url)
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	return entry.page, true
+}
+
+// Put implements PageCache.
+func (c *MemoryPageCache) Put(url string, page *CachedPage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &memoryCacheEntry{url: url, page: page, expires: time.Now().Add(c.ttl)}
+	if el, ok := c.entries[url]; ok {
+		el.Value = entry
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[url] = c.lru.PushFront(entry)
+
+	if c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheEntry).url)
+	}
+}
+
+// Len returns the number of pages in c,
+// including any that have expired but not yet been discarded.
+func (c *MemoryPageCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
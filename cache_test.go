@@ -0,0 +1,187 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryPageCache(t *testing.T) {
+	t.Run("size", func(t *testing.T) {
+		c := NewMemoryPageCache(0, 2)
+		c.Put("a", &CachedPage{URL: "a"})
+		c.Put("b", &CachedPage{URL: "b"})
+		c.Get("a") // now b is the least recently used
+		c.Put("c", &CachedPage{URL: "c"})
+
+		if c.Len() != 2 {
+			t.Errorf("got %d entries, want 2", c.Len())
+		}
+		for _, url := range []string{"a", "c"} {
+			if _, ok := c.Get(url); !ok {
+				t.Errorf("%s missing", url)
+			}
+		}
+		if _, ok := c.Get("b"); ok {
+			t.Error("b not evicted")
+		}
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		c := NewMemoryPageCache(50*time.Millisecond, 0)
+		c.Put("a", &CachedPage{URL: "a"})
+		if _, ok := c.Get("a"); !ok {
+			t.Fatal("a missing")
+		}
+		time.Sleep(100 * time.Millisecond)
+		if _, ok := c.Get("a"); ok {
+			t.Error("a not expired")
+		}
+		if c.Len() != 0 {
+			t.Errorf("got %d entries, want 0", c.Len())
+		}
+	})
+}
+
+func TestPageCache(t *testing.T) {
+	rec := &RequestRecorder{
+		Responses: map[string]CannedResponse{
+			"http://coalitioninc.com/": {ContentType: "text/html", Body: "<html><body>coalition</body></html>"},
+		},
+	}
+	cache := NewMemoryPageCache(time.Minute, 100)
+
+	// Many matchers,
+	// in many goroutines,
+	// share one cache.
+	// Run with -race.
+	var (
+		wg      sync.WaitGroup
+		errs    = make(chan error, 8)
+		domains = []string{"coalitioninc.com", "coalition.net", "coalitioninc.com"}
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			m := NewMatcher()
+			m.Client = &http.Client{Transport: rec}
+			m.PageCache = cache
+
+			scores, err := m.MatchMany(context.Background(), "Coalition", domains)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if scores[0] != scores[2] {
+				errs <- fmt.Errorf("got scores %v, want the first and last equal", scores)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// The 404 for coalition.net is not cached.
+	if got := cache.Len(); got != 1 {
+		t.Errorf("got %d cached pages, want 1", got)
+	}
+
+	// Once cached, pages are not fetched again.
+	rec.Reset()
+	m := NewMatcher()
+	m.Client = &http.Client{Transport: rec}
+	m.PageCache = cache
+	score, err := m.MatchContext(context.Background(), "Coalition", "coalitioninc.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if score < 0.9 {
+		t.Errorf("got score %v, want at least 0.9", score)
+	}
+	if reqs := rec.Requests(); len(reqs) != 0 {
+		t.Errorf("got %d requests, want 0", len(reqs))
+	}
+}
+
+func TestPageCacheLimits(t *testing.T) {
+	t.Run("large", func(t *testing.T) {
+		body := "<html><body>" + strings.Repeat("filler ", maxCachedPageLen/7) + "coalition</body></html>"
+		rec := &RequestRecorder{
+			Responses: map[string]CannedResponse{
+				"http://coalitioninc.com/": {ContentType: "text/html", Body: body},
+			},
+		}
+		cache := NewMemoryPageCache(time.Minute, 100)
+
+		m := NewMatcher()
+		m.Client = &http.Client{Transport: rec}
+		m.PageCache = cache
+
+		// The whole page is read, though it isn't cached.
+		_, detail, err := m.doMatchDetail(context.Background(), "Coalition", "coalitioninc.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, o := range detail.Outcomes {
+			if o.Test == "WebPageRef" && !o.Passed {
+				t.Error("WebPageRef did not pass")
+			}
+		}
+		if got := cache.Len(); got != 0 {
+			t.Errorf("got %d cached pages, want 0", got)
+		}
+	})
+
+	t.Run("connect", func(t *testing.T) {
+		newServer := func(name string) *httptest.Server {
+			return httptest.NewServer(pageHandler("text/html", "<html><body>"+name+"</body></html>"))
+		}
+		srv1 := newServer("server one")
+		defer srv1.Close()
+		srv2 := newServer("server two")
+		defer srv2.Close()
+
+		_, port, err := net.SplitHostPort(strings.TrimPrefix(srv1.URL, "http://"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cache := NewMemoryPageCache(time.Minute, 100)
+
+		// Matchers connecting to different servers for the same URL
+		// don't get each other's cached pages.
+		for _, c := range []struct {
+			srv *httptest.Server
+			ref string
+		}{
+			{srv: srv1, ref: "Server One"},
+			{srv: srv2, ref: "Server Two"},
+		} {
+			m := NewMatcher()
+			m.PageCache = cache
+			m.ConnectAddresses = map[string]string{"coalition.example": strings.TrimPrefix(c.srv.URL, "http://")}
+
+			_, detail, err := m.doMatchDetail(context.Background(), c.ref, "coalition.example:"+port)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, o := range detail.Outcomes {
+				if o.Test == "WebPageRef" && !o.Passed {
+					t.Errorf("WebPageRef did not pass for %s", c.ref)
+				}
+			}
+		}
+		if got := cache.Len(); got != 2 {
+			t.Errorf("got %d cached pages, want 2", got)
+		}
+	})
+}
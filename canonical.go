@@ -0,0 +1,66 @@
+package coalition
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/bobg/htree"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+func runCanonicalHostTest(ctx context.Context, m Matcher, in *matchInput) (bool, error) {
+	page, err := in.homePage(ctx, m)
+	if err != nil {
+		return false, err
+	}
+	host := canonicalHost(page)
+	if host == "" {
+		return false, nil
+	}
+	if m.sameSite(&url.URL{Host: host}, in.webURL) {
+		// A page that is canonical for its own site (the usual case)
+		// is no new evidence:
+		// the string tests have already examined this host.
+		return false, nil
+	}
+
+	canonIn, err := m.newMatchInput(in.ref, host)
+	if err != nil {
+		return false, err
+	}
+	score, err := m.runTests(ctx, canonIn, stringTests)
+	if err != nil {
+		return false, err
+	}
+	return score > 0, nil
+}
+
+// canonicalHost returns the host of the URL in page's <link rel="canonical"> element,
+// or the empty string if there isn't one.
+func canonicalHost(page *webPage) string {
+	tree := page.htmlTree()
+	if tree == nil {
+		return ""
+	}
+	link := htree.FindEl(tree, func(n *html.Node) bool {
+		if n.DataAtom != atom.Link {
+			return false
+		}
+		for _, rel := range strings.Fields(htree.ElAttr(n, "rel")) {
+			if strings.EqualFold(rel, "canonical") {
+				return true
+			}
+		}
+		return false
+	})
+	if link == nil {
+		return ""
+	}
+	u, err := url.Parse(strings.TrimSpace(htree.ElAttr(link, "href")))
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
@@ -0,0 +1,79 @@
+package coalition
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestCanonicalHostTest(t *testing.T) {
+	cases := []struct {
+		name, page string
+		want       int
+	}{
+		{
+			name: "match",
+			page: `<html><head><link rel="canonical" href="https://www.coalitioninc.com/"></head><body>Cyber insurance</body></html>`,
+			want: 20,
+		},
+		{
+			name: "mismatch",
+			page: `<html><head><link rel="canonical" href="https://cdn.example.net/"></head><body>Cyber insurance</body></html>`,
+			want: 0,
+		},
+		{
+			name: "relative",
+			page: `<html><head><link rel="canonical" href="/index.html"></head><body>Cyber insurance</body></html>`,
+			want: 0,
+		},
+		{
+			name: "none",
+			page: `<html><head></head><body>Cyber insurance</body></html>`,
+			want: 0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// The server plays the role of a CDN host for the organization's site.
+			srv, domain := newTestServer("text/html", c.page)
+			defer srv.Close()
+
+			matcher := NewMatcher()
+			delete(matcher.Scores, testWebPageRef)
+			matcher.Scores[testCanonicalHost] = 20
+
+			got, err := matcher.doMatch(context.Background(), "Coalition, Inc", domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+
+	t.Run("self", func(t *testing.T) {
+		// A page that names its own site as canonical
+		// adds nothing to the string tests' evidence.
+		srv, addr := newTestServer("text/html", `<html><head><link rel="canonical" href="https://www.coalitioninc.com/"></head><body>Cyber insurance</body></html>`)
+		defer srv.Close()
+		_, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		matcher := NewMatcher()
+		delete(matcher.Scores, testWebPageRef)
+		matcher.Scores[testCanonicalHost] = 20
+		matcher.ConnectAddresses = map[string]string{"coalitioninc.com": addr}
+
+		got, err := matcher.doMatch(context.Background(), "Coalition, Inc", "coalitioninc.com:"+port)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 50 { // just RootPhrase
+			t.Errorf("got %d, want 50", got)
+		}
+	})
+}
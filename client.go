@@ -0,0 +1,94 @@
+package coalition
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultWebTimeout is the overall timeout for each web request
+// when Matcher.Client is nil and Matcher.WebTimeout is zero.
+//
+// The client used when Matcher.Client is nil also has these transport settings:
+//   - dial timeout: 5s
+//   - TLS handshake timeout: 5s
+//   - response header timeout: 5s
+//   - at most 100 idle connections in total, and 2 per host, closed after 90s
+//   - proxies come from Matcher.ProxyProvider if it is set,
+//     and otherwise from the environment (HTTP_PROXY etc.).
+//   - connections go to the addresses in Matcher.ConnectAddresses, if any,
+//     and are then not kept for reuse.
+//
+// HTTP/2 is attempted where available.
+// (The Go HTTP client never accepts HTTP/2 server push.)
+// To change any of these, supply your own Matcher.Client.
+const DefaultWebTimeout = 10 * time.Second
+
+var (
+	defaultTransportOnce sync.Once
+	defaultTransport     *http.Transport
+
+	connectTransportOnce sync.Once
+	connectTransport     *http.Transport
+)
+
+// This returns the transport shared by all default HTTP clients,
+// so they can share idle connections.
+func getDefaultTransport() *http.Transport {
+	defaultTransportOnce.Do(func() {
+		dialer := &net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}
+		defaultTransport = &http.Transport{
+			Proxy:                 proxyFromContext,
+			DialContext:           dialConnectAddress(dialer.DialContext),
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			MaxIdleConnsPerHost:   2,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   5 * time.Second,
+			ResponseHeaderTimeout: 5 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		}
+	})
+	return defaultTransport
+}
+
+// This returns the transport used by default HTTP clients
+// for Matchers with ConnectAddresses.
+// It is like the default transport but keeps no idle connections,
+// since those are pooled by the host and port in the URL,
+// not the address dialed,
+// and would be reused for Matchers with different overrides, or none.
+func getConnectTransport() *http.Transport {
+	connectTransportOnce.Do(func() {
+		connectTransport = getDefaultTransport().Clone()
+		connectTransport.DisableKeepAlives = true
+	})
+	return connectTransport
+}
+
+// This builds the HTTP client used when Matcher.Client is nil,
+// with the given transport and overall timeout.
+func defaultHTTPClient(transport *http.Transport, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}
+}
+
+func (m Matcher) httpClient() *http.Client {
+	if m.Client != nil {
+		return m.Client
+	}
+	timeout := m.WebTimeout
+	if timeout == 0 {
+		timeout = DefaultWebTimeout
+	}
+	if len(m.ConnectAddresses) > 0 {
+		return defaultHTTPClient(getConnectTransport(), timeout)
+	}
+	return defaultHTTPClient(getDefaultTransport(), timeout)
+}
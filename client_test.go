@@ -0,0 +1,43 @@
+package coalition
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHTTPClient(t *testing.T) {
+	matcher := NewMatcher()
+
+	client := matcher.httpClient()
+	if client.Timeout != DefaultWebTimeout {
+		t.Errorf("got timeout %s, want %s", client.Timeout, DefaultWebTimeout)
+	}
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("got transport of type %T, want *http.Transport", client.Transport)
+	}
+	if transport.TLSHandshakeTimeout != 5*time.Second {
+		t.Errorf("got TLS handshake timeout %s, want 5s", transport.TLSHandshakeTimeout)
+	}
+	if transport.ResponseHeaderTimeout != 5*time.Second {
+		t.Errorf("got response header timeout %s, want 5s", transport.ResponseHeaderTimeout)
+	}
+	if transport.MaxIdleConnsPerHost != 2 {
+		t.Errorf("got %d max idle connections per host, want 2", transport.MaxIdleConnsPerHost)
+	}
+
+	matcher.WebTimeout = 3 * time.Second
+	if got := matcher.httpClient().Timeout; got != 3*time.Second {
+		t.Errorf("got timeout %s, want 3s", got)
+	}
+	if matcher.httpClient().Transport != transport {
+		t.Error("default clients do not share a transport")
+	}
+
+	custom := &http.Client{Timeout: time.Minute}
+	matcher.Client = custom
+	if got := matcher.httpClient(); got != custom {
+		t.Error("custom client not used")
+	}
+}
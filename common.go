@@ -0,0 +1,62 @@
+package coalition
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// DefaultCommonWords are generic words that appear in the names of many unrelated organizations.
+// Assign them (or a list read with ReadCommonWords) to Matcher.CommonWords
+// to keep them from passing the AnyRootWord test on their own.
+var DefaultCommonWords = map[string]bool{
+	"associates":     true,
+	"bank":           true,
+	"capital":        true,
+	"consulting":     true,
+	"digital":        true,
+	"enterprises":    true,
+	"financial":      true,
+	"global":         true,
+	"group":          true,
+	"holdings":       true,
+	"industries":     true,
+	"international":  true,
+	"management":     true,
+	"media":          true,
+	"network":        true,
+	"partners":       true,
+	"security":       true,
+	"services":       true,
+	"software":       true,
+	"solutions":      true,
+	"systems":        true,
+	"technologies":   true,
+	"technology":     true,
+	"ventures":       true,
+	"worldwide":      true,
+	"communications": true,
+}
+
+// ReadCommonWords reads a list of common words
+// (see Matcher.CommonWords)
+// from r,
+// one per line.
+// Blank lines and lines beginning with "#" are ignored.
+// Words are normalized as in reference strings
+// (see Normalize),
+// so "Solutions" and "solutions" are the same.
+func ReadCommonWords(r io.Reader) (map[string]bool, error) {
+	result := make(map[string]bool)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, word := range Normalize(line) {
+			result[word] = true
+		}
+	}
+	return result, sc.Err()
+}
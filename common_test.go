@@ -0,0 +1,60 @@
+package coalition
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommonWords(t *testing.T) {
+	cases := []struct {
+		domain                string
+		wantWith, wantWithout int
+	}{
+		{domain: "acme-solutions.com", wantWith: 0, wantWithout: 5},
+		{domain: "coalition-partners.com", wantWith: 5, wantWithout: 5},
+	}
+
+	for _, c := range cases {
+		t.Run(c.domain, func(t *testing.T) {
+			matcher := NewMatcher()
+			matcher.Scores = map[testType]int{testAnyRootWord: 5}
+
+			got, err := matcher.doMatch(context.Background(), "Coalition Solutions", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.wantWithout {
+				t.Errorf("without common words: got %d, want %d", got, c.wantWithout)
+			}
+
+			matcher.CommonWords = DefaultCommonWords
+			got, err = matcher.doMatch(context.Background(), "Coalition Solutions", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.wantWith {
+				t.Errorf("with common words: got %d, want %d", got, c.wantWith)
+			}
+		})
+	}
+}
+
+func TestReadCommonWords(t *testing.T) {
+	const input = `
+# Generic words
+Solutions
+group
+
+Société
+`
+	got, err := ReadCommonWords(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"solutions": true, "group": true, "societe": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
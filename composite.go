@@ -0,0 +1,126 @@
+package coalition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrMatcherConflict is the error from a CompositeMatcher
+// whose Matchers disagree on how to read the home page they share.
+var ErrMatcherConflict = errors.New("conflicting matchers")
+
+// CompositeMatcher runs several Matchers,
+// such as a strict one and a lenient one,
+// and combines their results into a single score.
+//
+// The domain's home page is fetched just once for all of Matchers,
+// by the first of them to need it,
+// so the settings that govern fetching
+// (Client, ConnectAddresses, PageCache, and so on)
+// are that one's.
+// The settings that govern how the page is read
+// (HeadOnly, StreamPageText, and ContentTypes)
+// must be the same in all of them,
+// or matching fails with ErrMatcherConflict.
+// The other network tests are performed separately by each.
+type CompositeMatcher struct {
+	// Matchers are the constituent Matchers.
+	// Each is called in turn.
+	Matchers []Matcher
+
+	// Aggregate, if non-nil,
+	// combines the scores of Matchers,
+	// in the same order,
+	// into one.
+	// MaxScore, MeanScore, and WeightedScore are suitable values.
+	// The default is MaxScore.
+	Aggregate func(scores []float32) float32
+}
+
+// Match tells how well domain matches ref,
+// as Matcher.Match does,
+// combining the results of c.Matchers.
+func (c CompositeMatcher) Match(ref, domain string) (float32, error) {
+	return c.MatchContext(context.Background(), ref, domain)
+}
+
+// MatchContext is like Match but takes a context.
+// See Matcher.MatchContext.
+func (c CompositeMatcher) MatchContext(ctx context.Context, ref, domain string) (float32, error) {
+	if len(c.Matchers) == 0 {
+		return 0, errors.New("no matchers")
+	}
+	if err := c.checkPageSettings(); err != nil {
+		return 0, err
+	}
+
+	var (
+		scores = make([]float32, 0, len(c.Matchers))
+		fetch  *pageFetch
+	)
+	for _, m := range c.Matchers {
+		score, err := m.compositeScore(ctx, ref, domain, &fetch)
+		m.metrics().Match(score, err)
+		if err != nil {
+			return 0, err
+		}
+		scores = append(scores, score)
+	}
+
+	aggregate := c.Aggregate
+	if aggregate == nil {
+		aggregate = MaxScore
+	}
+	return aggregate(scores), nil
+}
+
+// This computes m's normalized score for one of the Matchers in a CompositeMatcher,
+// sharing *fetch,
+// the fetch of the domain's home page,
+// or setting it if this is the first.
+func (m Matcher) compositeScore(ctx context.Context, ref, domain string, fetch **pageFetch) (float32, error) {
+	in, err := m.newMatchInput(ref, domain)
+	if err != nil {
+		return 0, err
+	}
+	if *fetch == nil {
+		*fetch = in.fetch
+	} else {
+		in.fetch = *fetch
+	}
+	raw, _, err := m.doMatchInput(ctx, in, domain)
+	if err != nil {
+		return 0, err
+	}
+	return m.scale(raw), nil
+}
+
+// This checks that all of c.Matchers read the shared home page the same way,
+// as the first one does.
+func (c CompositeMatcher) checkPageSettings() error {
+	contentTypes := func(m Matcher) map[string]ContentKind {
+		if m.ContentTypes == nil {
+			return DefaultContentTypes
+		}
+		return m.ContentTypes
+	}
+
+	first := c.Matchers[0]
+	for i, m := range c.Matchers[1:] {
+		var field string
+		switch {
+		case m.HeadOnly != first.HeadOnly:
+			field = "HeadOnly"
+		case m.StreamPageText != first.StreamPageText:
+			field = "StreamPageText"
+		case !reflect.DeepEqual(contentTypes(m), contentTypes(first)):
+			field = "ContentTypes"
+		default:
+			continue
+		}
+		return fmt.Errorf("%w: Matchers[%d].%s differs from Matchers[0]", ErrMatcherConflict, i+1, field)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package coalition
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestCompositeMatcher(t *testing.T) {
+	strict := NewMatcher()
+	delete(strict.Scores, testWebPageRef) // No network requests during unit tests.
+	strict.MinAffixLength = -1
+
+	lenient := NewMatcher()
+	delete(lenient.Scores, testWebPageRef)
+
+	ctx := context.Background()
+
+	// The strict matcher penalizes the "x" in coalitionx.com and the lenient one doesn't.
+	strictScore, err := strict.MatchContext(ctx, "Coalition", "coalitionx.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lenientScore, err := lenient.MatchContext(ctx, "Coalition", "coalitionx.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strictScore >= lenientScore {
+		t.Fatalf("got strict score %v, lenient score %v; want strict < lenient", strictScore, lenientScore)
+	}
+
+	cases := []struct {
+		name      string
+		aggregate func([]float32) float32
+		want      float32
+	}{
+		{name: "default", want: lenientScore},
+		{name: "max", aggregate: MaxScore, want: lenientScore},
+		{name: "mean", aggregate: MeanScore, want: (strictScore + lenientScore) / 2},
+		{name: "weighted", aggregate: WeightedScore(3, 1), want: (3*strictScore + lenientScore) / 4},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			composite := CompositeMatcher{
+				Matchers:  []Matcher{strict, lenient},
+				Aggregate: c.aggregate,
+			}
+			got, err := composite.MatchContext(ctx, "Coalition", "coalitionx.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+
+	t.Run("one_fetch", func(t *testing.T) {
+		newRecorder := func() *RequestRecorder {
+			return &RequestRecorder{
+				Responses: map[string]CannedResponse{
+					"http://coalition.com/": {ContentType: "text/html", Body: "<html><body>coalition</body></html>"},
+				},
+			}
+		}
+
+		single := NewMatcher()
+		single.Client = &http.Client{Transport: newRecorder()}
+		want, err := single.MatchContext(ctx, "Coalition", "coalition.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rec := newRecorder()
+		client := &http.Client{Transport: rec}
+
+		a := NewMatcher()
+		a.Client = client
+		b := NewMatcher()
+		b.Client = client
+		b.MinAffixLength = -1
+
+		composite := CompositeMatcher{Matchers: []Matcher{a, b}, Aggregate: MeanScore}
+		got, err := composite.MatchContext(ctx, "Coalition", "coalition.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		if n := len(rec.Requests()); n != 1 {
+			t.Errorf("got %d requests, want 1", n)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		if _, err := (CompositeMatcher{}).MatchContext(ctx, "Coalition", "coalition.com"); err == nil {
+			t.Error("got no error, want one")
+		}
+	})
+}
+
+func TestWeightedScore(t *testing.T) {
+	cases := []struct {
+		weights []float32
+		scores  []float32
+		want    float32
+	}{
+		{scores: []float32{0.5, 1}, want: 0.75},
+		{weights: []float32{1, 3}, scores: []float32{0.5, 1}, want: 0.875},
+		{weights: []float32{0, 0}, scores: []float32{0.5, 1}, want: 0},
+		{weights: []float32{2}, scores: []float32{0.25, 1}, want: 0.5},
+	}
+
+	for _, c := range cases {
+		if got := WeightedScore(c.weights...)(c.scores); got != c.want {
+			t.Errorf("WeightedScore(%v)(%v) = %v, want %v", c.weights, c.scores, got, c.want)
+		}
+	}
+}
+
+func TestCompositeMatcherConflict(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(*Matcher)
+		want   bool
+	}{
+		{name: "same", modify: func(*Matcher) {}},
+		{name: "head_only", modify: func(m *Matcher) { m.HeadOnly = true }, want: true},
+		{name: "stream", modify: func(m *Matcher) { m.StreamPageText = true }, want: true},
+		{name: "content_types", modify: func(m *Matcher) { m.ContentTypes = map[string]ContentKind{"text/html": ContentHTML} }, want: true},
+		{name: "default_content_types", modify: func(m *Matcher) { m.ContentTypes = DefaultContentTypes }},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a := NewMatcher()
+			delete(a.Scores, testWebPageRef) // No network requests during unit tests.
+			b := NewMatcher()
+			delete(b.Scores, testWebPageRef)
+			c.modify(&b)
+
+			composite := CompositeMatcher{Matchers: []Matcher{a, b}}
+			_, err := composite.MatchContext(context.Background(), "Coalition", "coalition.com")
+			if got := errors.Is(err, ErrMatcherConflict); got != c.want {
+				t.Errorf("got conflict %v (%v), want %v", got, err, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package coalition
+
+// Confidence is a named range of match scores.
+// See Matcher.Classify.
+type Confidence int
+
+// Values for Confidence, in increasing order.
+const (
+	ConfidenceNone Confidence = iota
+	ConfidenceLow
+	ConfidenceMedium
+	ConfidenceHigh
+)
+
+func (c Confidence) String() string {
+	switch c {
+	case ConfidenceNone:
+		return "none"
+	case ConfidenceLow:
+		return "low"
+	case ConfidenceMedium:
+		return "medium"
+	case ConfidenceHigh:
+		return "high"
+	}
+	return "unknown"
+}
+
+// ConfidenceThresholds gives the lowest score for each Confidence level above ConfidenceNone.
+type ConfidenceThresholds struct {
+	Low, Medium, High float32
+}
+
+// DefaultConfidenceThresholds are the thresholds used by a Matcher whose Thresholds field is zero.
+var DefaultConfidenceThresholds = ConfidenceThresholds{
+	Low:    0.2,
+	Medium: 0.5,
+	High:   0.8,
+}
+
+// Classify maps score, a value in [0.0..1.0], to a Confidence level.
+func (t ConfidenceThresholds) Classify(score float32) Confidence {
+	switch {
+	case score >= t.High:
+		return ConfidenceHigh
+	case score >= t.Medium:
+		return ConfidenceMedium
+	case score >= t.Low:
+		return ConfidenceLow
+	}
+	return ConfidenceNone
+}
+
+// Classify matches ref against domain (see Match)
+// and maps the resulting score to a Confidence level
+// using m.Thresholds.
+func (m Matcher) Classify(ref, domain string) (Confidence, error) {
+	score, err := m.Match(ref, domain)
+	if err != nil {
+		return ConfidenceNone, err
+	}
+	return m.thresholds().Classify(score), nil
+}
+
+func (m Matcher) thresholds() ConfidenceThresholds {
+	if m.Thresholds == (ConfidenceThresholds{}) {
+		return DefaultConfidenceThresholds
+	}
+	return m.Thresholds
+}
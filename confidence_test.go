@@ -0,0 +1,61 @@
+package coalition
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		ref, domain string
+		want        Confidence
+	}{
+		{ref: "Coalition, Inc", domain: "coalitioninc.com", want: ConfidenceHigh},
+		{ref: "Coalition, Inc", domain: "emphatic.com", want: ConfidenceNone},
+		{ref: "Coalition, Inc", domain: "colition.com", want: ConfidenceLow},
+		{ref: "Coalition, Inc", domain: "coalition-rutabaga.com", want: ConfidenceMedium},
+		{ref: "Coalition Security, Inc.", domain: "coalition.com", want: ConfidenceLow},
+	}
+
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case_%02d", i+1), func(t *testing.T) {
+			got, err := matcher.Classify(c.ref, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %s, want %s", got, c.want)
+			}
+		})
+	}
+
+	t.Run("custom_thresholds", func(t *testing.T) {
+		matcher := matcher.Clone()
+		matcher.Thresholds = ConfidenceThresholds{Low: 0.1, Medium: 0.2, High: 0.7}
+
+		got, err := matcher.Classify("Coalition, Inc", "coalition-rutabaga.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != ConfidenceHigh {
+			t.Errorf("got %s, want %s", got, ConfidenceHigh)
+		}
+	})
+}
+
+func TestConfidenceString(t *testing.T) {
+	for c, want := range map[Confidence]string{
+		ConfidenceNone:   "none",
+		ConfidenceLow:    "low",
+		ConfidenceMedium: "medium",
+		ConfidenceHigh:   "high",
+		Confidence(17):   "unknown",
+	} {
+		if got := c.String(); got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package coalition
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// MatcherConfig is the serializable form of a Matcher's configuration.
+// Tests are identified by name (e.g. "RootPhrase", "WebPageRef").
+// Durations are strings like "3s" (see time.ParseDuration).
+// It can be read from and written to JSON, YAML, and TOML.
+// See LoadMatcherConfig and Matcher.WriteConfig.
+type MatcherConfig struct {
+	// Scores, if present, replaces the default scores entirely.
+	// A test is enabled when it has a non-zero score,
+	// so an empty Scores disables them all.
+	Scores map[string]int `json:"scores" yaml:"scores" toml:"scores"`
+
+	// Disabled lists tests to turn off,
+	// even if they have a score (including a default score).
+	Disabled []string `json:"disabled,omitempty" yaml:"disabled,omitempty" toml:"disabled,omitempty"`
+
+	// StopWords, if present, replaces the default stop words.
+	// They are case-folded, so "Inc" and "inc" are the same
+	// (see FoldingStopper).
+	// An empty StopWords means there are none,
+	// as with a nil Matcher.Stop.
+	StopWords []string `json:"stop_words" yaml:"stop_words" toml:"stop_words"`
+
+	// LegalForms corresponds to Matcher.LegalForms.
+	LegalForms bool `json:"legal_forms,omitempty" yaml:"legal_forms,omitempty" toml:"legal_forms,omitempty"`
+
+	// TestTimeouts corresponds to Matcher.TestTimeouts.
+	TestTimeouts map[string]string `json:"test_timeouts,omitempty" yaml:"test_timeouts,omitempty" toml:"test_timeouts,omitempty"`
+
+	// WebTimeout corresponds to Matcher.WebTimeout.
+	WebTimeout string `json:"web_timeout,omitempty" yaml:"web_timeout,omitempty" toml:"web_timeout,omitempty"`
+
+	// RequestsPerSecond corresponds to Matcher.RequestsPerSecond.
+	RequestsPerSecond float64 `json:"requests_per_second,omitempty" yaml:"requests_per_second,omitempty" toml:"requests_per_second,omitempty"`
+
+	// PerHostRequestsPerSecond corresponds to Matcher.PerHostRequestsPerSecond.
+	PerHostRequestsPerSecond float64 `json:"per_host_requests_per_second,omitempty" yaml:"per_host_requests_per_second,omitempty" toml:"per_host_requests_per_second,omitempty"`
+}
+
+// LoadMatcherConfig reads a MatcherConfig from r
+// in the given format
+// ("json", "yaml", or "toml")
+// and returns the Matcher it describes
+// (see MatcherConfig.Matcher).
+func LoadMatcherConfig(r io.Reader, format string) (Matcher, error) {
+	var conf MatcherConfig
+
+	switch format {
+	case "json":
+		dec := json.NewDecoder(r)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&conf); err != nil {
+			return Matcher{}, fmt.Errorf("decoding JSON config: %w", err)
+		}
+
+	case "yaml":
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return Matcher{}, err
+		}
+		if err := yaml.UnmarshalStrict(data, &conf); err != nil {
+			return Matcher{}, fmt.Errorf("decoding YAML config: %w", err)
+		}
+
+	case "toml":
+		md, err := toml.DecodeReader(r, &conf)
+		if err != nil {
+			return Matcher{}, fmt.Errorf("decoding TOML config: %w", err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return Matcher{}, fmt.Errorf("unknown TOML config key %s", undecoded[0])
+		}
+
+	default:
+		return Matcher{}, fmt.Errorf("unknown config format %q", format)
+	}
+
+	return conf.Matcher()
+}
+
+// Matcher returns a new Matcher with the configuration in c.
+// Settings absent from c get their default values
+// (see NewMatcher).
+// It is an error for c to name an unknown test
+// or to contain a negative or malformed duration.
+func (c MatcherConfig) Matcher() (Matcher, error) {
+	m := NewMatcher()
+
+	if c.Scores != nil {
+		m.Scores = make(map[testType]int)
+		for name, score := range c.Scores {
+			t, ok := testByName(name)
+			if !ok {
+				return Matcher{}, fmt.Errorf("unknown test %q in scores", name)
+			}
+			m.Scores[t] = score
+		}
+	}
+	for _, name := range c.Disabled {
+		t, ok := testByName(name)
+		if !ok {
+			return Matcher{}, fmt.Errorf("unknown test %q in disabled", name)
+		}
+		delete(m.Scores, t)
+	}
+
+	if c.StopWords != nil {
+		stop := make(simpleStopper)
+		for _, word := range c.StopWords {
+			stop[word] = true
+		}
+		m.Stop = FoldingStopper(stop)
+	}
+	m.LegalForms = c.LegalForms
+
+	if c.TestTimeouts != nil {
+		m.TestTimeouts = make(map[testType]time.Duration)
+		for name, s := range c.TestTimeouts {
+			t, ok := testByName(name)
+			if !ok {
+				return Matcher{}, fmt.Errorf("unknown test %q in test_timeouts", name)
+			}
+			d, err := parseConfigDuration(s)
+			if err != nil {
+				return Matcher{}, fmt.Errorf("timeout for test %s: %w", name, err)
+			}
+			m.TestTimeouts[t] = d
+		}
+	}
+
+	if c.WebTimeout != "" {
+		d, err := parseConfigDuration(c.WebTimeout)
+		if err != nil {
+			return Matcher{}, fmt.Errorf("web timeout: %w", err)
+		}
+		m.WebTimeout = d
+	}
+
+	if c.RequestsPerSecond < 0 || c.PerHostRequestsPerSecond < 0 {
+		return Matcher{}, fmt.Errorf("negative request rate")
+	}
+	m.RequestsPerSecond = c.RequestsPerSecond
+	m.PerHostRequestsPerSecond = c.PerHostRequestsPerSecond
+
+	return m, nil
+}
+
+func parseConfigDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative duration %s", s)
+	}
+	return d, nil
+}
+
+// Config returns the serializable form of m's configuration.
+// It is an error if m's Stopper is not one of this package's own
+// (i.e., was not produced by the default configuration or by a MatcherConfig).
+// Scores and StopWords are always present,
+// even if empty,
+// so that loading the result does not restore the defaults.
+func (m Matcher) Config() (MatcherConfig, error) {
+	var c MatcherConfig
+
+	c.Scores = make(map[string]int)
+	for t, score := range m.Scores {
+		c.Scores[testNames[t]] = score
+	}
+
+	c.StopWords = []string{}
+	switch stop := m.Stop.(type) {
+	case nil:
+	case simpleStopper:
+		for word, ok := range stop {
+			if ok {
+				c.StopWords = append(c.StopWords, word)
+			}
+		}
+		sort.Strings(c.StopWords)
+	default:
+		return MatcherConfig{}, fmt.Errorf("cannot serialize stopper of type %T", m.Stop)
+	}
+	c.LegalForms = m.LegalForms
+
+	if len(m.TestTimeouts) > 0 {
+		c.TestTimeouts = make(map[string]string)
+		for t, d := range m.TestTimeouts {
+			c.TestTimeouts[testNames[t]] = d.String()
+		}
+	}
+	if m.WebTimeout != 0 {
+		c.WebTimeout = m.WebTimeout.String()
+	}
+	c.RequestsPerSecond = m.RequestsPerSecond
+	c.PerHostRequestsPerSecond = m.PerHostRequestsPerSecond
+
+	return c, nil
+}
+
+// WriteConfig writes m's configuration (see Matcher.Config) to w
+// in the given format
+// ("json", "yaml", or "toml").
+func (m Matcher) WriteConfig(w io.Writer, format string) error {
+	c, err := m.Config()
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(c)
+
+	case "yaml":
+		data, err := yaml.Marshal(c)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+
+	case "toml":
+		return toml.NewEncoder(w).Encode(c)
+	}
+
+	return fmt.Errorf("unknown config format %q", format)
+}
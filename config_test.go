@@ -0,0 +1,150 @@
+package coalition
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigRoundTrip(t *testing.T) {
+	orig := NewMatcher()
+	orig.Scores[testRootPhrase] = 70
+	orig.Scores[testJSONLDOrganization] = 20
+	delete(orig.Scores, testWebPageRef)
+	orig.Stop = simpleStopper{"the": true, "inc": true, "gmbh": true}
+	orig.TestTimeouts = map[testType]time.Duration{testJSONLDOrganization: 3 * time.Second}
+	orig.WebTimeout = 4 * time.Second
+	orig.RequestsPerSecond = 10
+
+	want, err := orig.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []string{"json", "yaml", "toml"} {
+		t.Run(format, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := orig.WriteConfig(buf, format); err != nil {
+				t.Fatal(err)
+			}
+
+			loaded, err := LoadMatcherConfig(buf, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := loaded.Config()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+			if !reflect.DeepEqual(loaded.Scores, orig.Scores) {
+				t.Errorf("got scores %v, want %v", loaded.Scores, orig.Scores)
+			}
+		})
+	}
+}
+
+func TestConfigRoundTripEmpty(t *testing.T) {
+	orig := NewMatcher()
+	orig.Scores = map[testType]int{}
+	orig.Stop = nil
+
+	for _, format := range []string{"json", "yaml", "toml"} {
+		t.Run(format, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := orig.WriteConfig(buf, format); err != nil {
+				t.Fatal(err)
+			}
+
+			loaded, err := LoadMatcherConfig(buf, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(loaded.Scores) != 0 {
+				t.Errorf("got scores %v, want none", loaded.Scores)
+			}
+			if isStopWord(loaded.Stop, "inc") {
+				t.Error("got default stop words, want none")
+			}
+		})
+	}
+}
+
+func TestLoadMatcherConfig(t *testing.T) {
+	cases := []struct {
+		name, format, config string
+		wantErr              bool
+		check                func(*testing.T, Matcher)
+	}{
+		{
+			name:   "json",
+			format: "json",
+			config: `{"disabled": ["WebPageRef"], "test_timeouts": {"NewDomain": "2s"}}`,
+			check: func(t *testing.T, m Matcher) {
+				if _, ok := m.Scores[testWebPageRef]; ok {
+					t.Error("WebPageRef not disabled")
+				}
+				if got := m.Scores[testRootPhrase]; got != 50 {
+					t.Errorf("got RootPhrase score %d, want the default 50", got)
+				}
+				if got := m.TestTimeouts[testNewDomain]; got != 2*time.Second {
+					t.Errorf("got NewDomain timeout %s, want 2s", got)
+				}
+			},
+		},
+		{
+			name:   "yaml",
+			format: "yaml",
+			config: "scores:\n  RootPhrase: 60\n  AnyRootWord: 10\nstop_words: [the, ag]\n",
+			check: func(t *testing.T, m Matcher) {
+				if want := map[testType]int{testRootPhrase: 60, testAnyRootWord: 10}; !reflect.DeepEqual(m.Scores, want) {
+					t.Errorf("got scores %v, want %v", m.Scores, want)
+				}
+				if !m.Stop.IsStopWord("ag") || m.Stop.IsStopWord("inc") {
+					t.Error("stop words not replaced")
+				}
+			},
+		},
+		{
+			name:   "toml",
+			format: "toml",
+			config: "web_timeout = \"3s\"\n\n[scores]\nRootPhrase = 40\n",
+			check: func(t *testing.T, m Matcher) {
+				if m.WebTimeout != 3*time.Second {
+					t.Errorf("got web timeout %s, want 3s", m.WebTimeout)
+				}
+				if got := m.Scores[testRootPhrase]; got != 40 {
+					t.Errorf("got RootPhrase score %d, want 40", got)
+				}
+			},
+		},
+		{name: "json_unknown_test", format: "json", config: `{"scores": {"Bogus": 5}}`, wantErr: true},
+		{name: "yaml_unknown_test", format: "yaml", config: "disabled: [Bogus]\n", wantErr: true},
+		{name: "toml_unknown_test", format: "toml", config: "[test_timeouts]\nBogus = \"1s\"\n", wantErr: true},
+		{name: "json_negative_timeout", format: "json", config: `{"web_timeout": "-1s"}`, wantErr: true},
+		{name: "yaml_negative_timeout", format: "yaml", config: "test_timeouts:\n  WebPageRef: -5s\n", wantErr: true},
+		{name: "toml_bad_duration", format: "toml", config: "web_timeout = \"soon\"\n", wantErr: true},
+		{name: "json_unknown_field", format: "json", config: `{"bogus": 1}`, wantErr: true},
+		{name: "unknown_format", format: "xml", config: "<config/>", wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m, err := LoadMatcherConfig(strings.NewReader(c.config), c.format)
+			if c.wantErr {
+				if err == nil {
+					t.Error("got no error, want one")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.check(t, m)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package coalition
+
+import (
+	"context"
+	"net"
+	"net/url"
+)
+
+// connectKey is the context key for the Matcher.ConnectAddresses of a request.
+type connectKey struct{}
+
+// This adds m.ConnectAddresses, if any, to ctx,
+// for connectAddress.
+func (m Matcher) withConnectAddresses(ctx context.Context) context.Context {
+	if len(m.ConnectAddresses) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, connectKey{}, m.ConnectAddresses)
+}
+
+// This returns the address to dial in place of addr
+// ("host:port")
+// according to the Matcher.ConnectAddresses in ctx,
+// or addr itself if there is none.
+func connectAddress(ctx context.Context, addr string) string {
+	addrs, ok := ctx.Value(connectKey{}).(map[string]string)
+	if !ok {
+		return addr
+	}
+	if a, ok := addrs[addr]; ok {
+		return a
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	a, ok := addrs[host]
+	if !ok {
+		return addr
+	}
+	if _, _, err := net.SplitHostPort(a); err != nil {
+		// No port in the override, so keep the original one.
+		return net.JoinHostPort(a, port)
+	}
+	return a
+}
+
+// This wraps dial so that it connects to the address given by connectAddress.
+func dialConnectAddress(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dial(ctx, network, connectAddress(ctx, addr))
+	}
+}
+
+// This returns the "host:port" address that a request for u dials,
+// before any override,
+// with the default port for u's scheme if it has none.
+func hostPort(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
@@ -0,0 +1,139 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConnectAddresses(t *testing.T) {
+	// A server with virtual hosts,
+	// serving different content depending on the Host header.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		host := req.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		switch host {
+		case "coalition.example":
+			fmt.Fprint(w, "<html><body>Welcome to coalition</body></html>")
+		case "acme.example":
+			fmt.Fprint(w, "<html><body>Welcome to acme</body></html>")
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer srv.Close()
+
+	addr := strings.TrimPrefix(srv.URL, "http://")
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name, ref, domain string
+		addrs             map[string]string
+		want              bool
+	}{
+		{name: "host", ref: "Coalition", domain: "coalition.example", addrs: map[string]string{"coalition.example": addr}, want: true},
+		{name: "other_host", ref: "Acme", domain: "acme.example", addrs: map[string]string{"acme.example": addr}, want: true},
+		{name: "wrong_content", ref: "Acme", domain: "coalition.example", addrs: map[string]string{"coalition.example": addr}, want: false},
+		{name: "host_and_port", ref: "Coalition", domain: "coalition.example:" + port, addrs: map[string]string{"coalition.example:" + port: addr}, want: true},
+		{name: "keep_port", ref: "Coalition", domain: "coalition.example:" + port, addrs: map[string]string{"coalition.example": host}, want: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			matcher := NewMatcher()
+			matcher.ConnectAddresses = c.addrs
+
+			_, detail, err := matcher.doMatchDetail(context.Background(), c.ref, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, o := range detail.Outcomes {
+				if o.Test == "WebPageRef" && o.Passed != c.want {
+					t.Errorf("got WebPageRef passed %v, want %v", o.Passed, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestConnectAddress(t *testing.T) {
+	addrs := map[string]string{
+		"coalition.com":     "192.0.2.1",
+		"coalition.com:443": "192.0.2.2:8443",
+		"acme.com":          "192.0.2.3:8080",
+	}
+	ctx := Matcher{ConnectAddresses: addrs}.withConnectAddresses(context.Background())
+
+	cases := []struct {
+		addr, want string
+	}{
+		{addr: "coalition.com:80", want: "192.0.2.1:80"},
+		{addr: "coalition.com:443", want: "192.0.2.2:8443"},
+		{addr: "acme.com:80", want: "192.0.2.3:8080"},
+		{addr: "example.com:80", want: "example.com:80"},
+	}
+
+	for _, c := range cases {
+		if got := connectAddress(ctx, c.addr); got != c.want {
+			t.Errorf("connectAddress(%s) = %s, want %s", c.addr, got, c.want)
+		}
+	}
+
+	if got := connectAddress(context.Background(), "coalition.com:80"); got != "coalition.com:80" {
+		t.Errorf("got %s without ConnectAddresses, want coalition.com:80", got)
+	}
+}
+
+func TestConnectAddressesSequence(t *testing.T) {
+	// Two servers for the same host name, with different content.
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprintf(w, "<html><body>Welcome to %s</body></html>", name)
+		}))
+	}
+	srv1 := newServer("server one")
+	defer srv1.Close()
+	srv2 := newServer("server two")
+	defer srv2.Close()
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(srv1.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	domain := "coalition.example:" + port
+
+	cases := []struct {
+		srv *httptest.Server
+		ref string
+	}{
+		{srv: srv1, ref: "Server One"},
+		{srv: srv2, ref: "Server Two"},
+		{srv: srv1, ref: "Server One"},
+	}
+
+	for i, c := range cases {
+		matcher := NewMatcher()
+		matcher.ConnectAddresses = map[string]string{"coalition.example": strings.TrimPrefix(c.srv.URL, "http://")}
+
+		_, detail, err := matcher.doMatchDetail(context.Background(), c.ref, domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, o := range detail.Outcomes {
+			if o.Test == "WebPageRef" && !o.Passed {
+				t.Errorf("case %d: WebPageRef did not pass for %s", i, c.ref)
+			}
+		}
+	}
+}
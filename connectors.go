@@ -0,0 +1,65 @@
+package coalition
+
+import "strings"
+
+// ConnectorPolicy says how normalization treats the connectors
+// that brands write in place of "and":
+// "&" and "+",
+// as in "AT&T" and "R+Co",
+// and a standalone "n" between two words,
+// as in "Chip n Dale" (or "Chip 'n' Dale").
+// See Matcher.Connectors and WithConnectors.
+type ConnectorPolicy int
+
+const (
+	// ConnectorsSeparate treats "&" and "+" like other punctuation,
+	// separating words,
+	// and "n" like any other word.
+	// So "AT&T" is {"at", "t"}
+	// and "Chip n Dale" is {"chip", "n", "dale"}.
+	ConnectorsSeparate ConnectorPolicy = iota
+
+	// ConnectorsRemove removes the connectors,
+	// joining the letters on either side of "&" or "+".
+	// So "AT&T" is {"att"},
+	// "R+Co" is {"rco"},
+	// and "Chip n Dale" is {"chip", "dale"}.
+	ConnectorsRemove
+
+	// ConnectorsAnd replaces the connectors with the word "and".
+	// So "AT&T" is {"at", "and", "t"}
+	// and "Chip n Dale" is {"chip", "and", "dale"}.
+	ConnectorsAnd
+)
+
+// connectorReplacer replaces "&" and "+" per a ConnectorPolicy.
+var connectorReplacer = map[ConnectorPolicy]*strings.Replacer{
+	ConnectorsRemove: strings.NewReplacer("&", "", "+", ""),
+	ConnectorsAnd:    strings.NewReplacer("&", " and ", "+", " and "),
+}
+
+// This applies policy to the connector symbols in ref,
+// before it is split into words.
+func replaceConnectors(ref string, policy ConnectorPolicy) string {
+	if r, ok := connectorReplacer[policy]; ok {
+		return r.Replace(ref)
+	}
+	return ref
+}
+
+// This applies policy to the words of a reference string
+// that are a standalone "n" between two other words.
+func replaceConnectorWords(words []string, policy ConnectorPolicy) []string {
+	if policy == ConnectorsSeparate || len(words) < 3 {
+		return words
+	}
+	result := words[:1:1]
+	for i := 1; i < len(words)-1; i++ {
+		if words[i] != "n" {
+			result = append(result, words[i])
+		} else if policy == ConnectorsAnd {
+			result = append(result, "and")
+		}
+	}
+	return append(result, words[len(words)-1])
+}
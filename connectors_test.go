@@ -0,0 +1,70 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestConnectors(t *testing.T) {
+	t.Run("normalize", func(t *testing.T) {
+		cases := []struct {
+			ref    string
+			policy ConnectorPolicy
+			want   []string
+		}{
+			{ref: "AT&T", policy: ConnectorsSeparate, want: []string{"at", "t"}},
+			{ref: "AT&T", policy: ConnectorsRemove, want: []string{"att"}},
+			{ref: "AT&T", policy: ConnectorsAnd, want: []string{"at", "and", "t"}},
+			{ref: "R+Co", policy: ConnectorsRemove, want: []string{"rco"}},
+			{ref: "R+Co", policy: ConnectorsAnd, want: []string{"r", "and", "co"}},
+			{ref: "Chip n Dale", policy: ConnectorsSeparate, want: []string{"chip", "n", "dale"}},
+			{ref: "Chip n Dale", policy: ConnectorsRemove, want: []string{"chip", "dale"}},
+			{ref: "Chip 'n' Dale", policy: ConnectorsRemove, want: []string{"chip", "dale"}},
+			{ref: "Chip n Dale", policy: ConnectorsAnd, want: []string{"chip", "and", "dale"}},
+			{ref: "Procter & Gamble", policy: ConnectorsRemove, want: []string{"procter", "gamble"}},
+			{ref: "N Brands", policy: ConnectorsRemove, want: []string{"n", "brands"}},
+		}
+
+		for _, c := range cases {
+			t.Run(fmt.Sprintf("%s_%d", c.ref, c.policy), func(t *testing.T) {
+				got := Normalize(c.ref, WithConnectors(c.policy))
+				if !reflect.DeepEqual(got, c.want) {
+					t.Errorf("got %v, want %v", got, c.want)
+				}
+			})
+		}
+	})
+
+	t.Run("match", func(t *testing.T) {
+		cases := []struct {
+			ref, domain string
+			policy      ConnectorPolicy
+			want        int
+		}{
+			{ref: "AT&T", domain: "att.com", policy: ConnectorsRemove, want: 50},
+			{ref: "AT&T", domain: "atandt.com", policy: ConnectorsAnd, want: 50},
+			{ref: "R+Co", domain: "rco.com", policy: ConnectorsRemove, want: 50},
+			{ref: "Chip n Dale", domain: "chipdale.com", policy: ConnectorsSeparate, want: 10},
+			{ref: "Chip n Dale", domain: "chipdale.com", policy: ConnectorsRemove, want: 50},
+			{ref: "Chip n Dale", domain: "chipanddale.com", policy: ConnectorsAnd, want: 50},
+		}
+
+		for _, c := range cases {
+			t.Run(fmt.Sprintf("%s_%s_%d", c.ref, c.domain, c.policy), func(t *testing.T) {
+				matcher := NewMatcher()
+				delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+				matcher.Connectors = c.policy
+
+				got, err := matcher.doMatch(context.Background(), c.ref, c.domain)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != c.want {
+					t.Errorf("got %d, want %d", got, c.want)
+				}
+			})
+		}
+	})
+}
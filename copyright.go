@@ -0,0 +1,56 @@
+package coalition
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// copyrightRegex matches a copyright notice,
+// like "© 2024 Coalition, Inc." or "Copyright (c) 2019-2024 Coalition",
+// capturing the text after the year(s),
+// which normally begins with the name of the copyright holder.
+var copyrightRegex = regexp.MustCompile(`(?i)(?:©|\(c\)|copyright)(?:\s*(?:©|\(c\)))?\s*\d{4}(?:\s*[-–]\s*\d{4})?[,.]?\s*([^\n|©]{1,100})`)
+
+// copyrightEnd matches where the name in a copyright notice ends,
+// if not at the end of the captured text.
+var copyrightEnd = regexp.MustCompile(`(?i)all rights reserved|\.\s`)
+
+func runCopyrightTest(ctx context.Context, m Matcher, in *matchInput) (bool, error) {
+	page, err := in.homePage(ctx, m)
+	if err != nil {
+		return false, err
+	}
+
+	text := page.text
+	if tree := page.htmlTree(); tree != nil {
+		// As with WebPageRef,
+		// a page whose text can't be extracted simply doesn't pass.
+		if text, err = extractText(tree); err != nil {
+			return false, nil
+		}
+	}
+
+	tm := m.textMatcher(in, in.re)
+	for _, holder := range copyrightHolders(text) {
+		if tm.match(holder) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// This returns the names of the copyright holders in the copyright notices in text.
+func copyrightHolders(text string) []string {
+	var result []string
+	for _, match := range copyrightRegex.FindAllStringSubmatch(text, -1) {
+		holder := match[1]
+		if loc := copyrightEnd.FindStringIndex(holder); loc != nil {
+			holder = holder[:loc[0]]
+		}
+		if holder = strings.TrimSpace(holder); holder != "" {
+			result = append(result, holder)
+		}
+	}
+	return result
+}
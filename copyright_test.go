@@ -0,0 +1,65 @@
+package coalition
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCopyrightHolders(t *testing.T) {
+	cases := []struct {
+		text string
+		want []string
+	}{
+		{text: "© 2024 Coalition, Inc. All rights reserved.", want: []string{"Coalition, Inc"}},
+		{text: "Copyright (c) 2019-2024 Coalition Insurance Solutions | Privacy", want: []string{"Coalition Insurance Solutions"}},
+		{text: "Copyright © 2020, Acme Corp all rights reserved", want: []string{"Acme Corp"}},
+		{text: "Home\n© 2024 Acme\nContact", want: []string{"Acme"}},
+		{text: "Copyright law protects coalition members", want: nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.text, func(t *testing.T) {
+			if got := copyrightHolders(c.text); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestCopyrightTest(t *testing.T) {
+	const page = `<html>
+<body>
+<main>Cyber insurance from our partners at Acme</main>
+<footer><p>&copy; 2024 Coalition, Inc. All rights reserved. | Site &copy; 2024 SOCIÉTÉ GÉNÉRALE</p></footer>
+</body>
+</html>`
+
+	srv, domain := newTestServer("text/html", page)
+	defer srv.Close()
+
+	cases := []struct {
+		ref  string
+		want int
+	}{
+		{ref: "Coalition, Inc", want: 30},
+		{ref: "Coalition", want: 30},
+		{ref: "Acme", want: 0}, // on the page, but not the copyright holder
+		{ref: "Societe Generale", want: 30},
+	}
+
+	matcher := NewMatcher()
+	matcher.Scores = map[testType]int{testCopyright: 30}
+
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			got, err := matcher.doMatch(context.Background(), c.ref, domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package coalition
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/bobg/htree"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// DefaultCrawlLinks is the number of links followed from each page
+// when Matcher.CrawlDepth is positive
+// and Matcher.CrawlLinks is zero.
+const DefaultCrawlLinks = 3
+
+// crawlHints are words that,
+// in a link's URL path or text,
+// suggest a page about the organization itself.
+// Links with them are followed first.
+var crawlHints = []string{"about", "company", "who", "team", "contact", "imprint", "impressum", "legal"}
+
+// This searches the pages linked from the home page,
+// up to m.CrawlDepth links away,
+// for the text of in.re,
+// as the WebPageRef test does with the home page itself.
+// Only links within the same site are followed
+// (see sameSite),
+// at most m.CrawlLinks of them from each page,
+// and each page takes a request from in.budget.
+// A page that can't be fetched is skipped.
+// The result is the URL of the first page found with the text,
+// or nil if there is none.
+func (m Matcher) crawlForRef(ctx context.Context, in *matchInput, home *webPage) *url.URL {
+	maxLinks := m.CrawlLinks
+	if maxLinks == 0 {
+		maxLinks = DefaultCrawlLinks
+	}
+
+	seen := map[string]bool{crawlKey(home.url): true}
+	level := []*webPage{home}
+	for depth := 0; depth < m.CrawlDepth && len(level) > 0; depth++ {
+		var next []*webPage
+		for _, page := range level {
+			for _, link := range m.crawlLinks(page, maxLinks, seen) {
+				if ctx.Err() != nil || !in.budget.take() {
+					return nil
+				}
+				resp, err := m.get(ctx, link)
+				if err != nil {
+					continue
+				}
+				linked, err := m.readPage(resp)
+				if err != nil {
+					continue
+				}
+				if doWebPageRefTest(linked, m.PageRegions, m.textMatcher(in, in.re)) {
+					return linked.url
+				}
+				next = append(next, linked)
+			}
+		}
+		level = next
+	}
+	return nil
+}
+
+// This returns up to max same-site links from page
+// that are not already in seen,
+// adding them to it.
+// Those suggesting a page about the organization
+// (see crawlHints)
+// come first;
+// the rest are in document order.
+func (m Matcher) crawlLinks(page *webPage, max int, seen map[string]bool) []*url.URL {
+	tree := page.htmlTree()
+	if tree == nil {
+		return nil
+	}
+
+	type candidate struct {
+		u      *url.URL
+		hinted bool
+	}
+	var candidates []candidate
+	htree.FindAllEls(tree, func(n *html.Node) bool { return n.DataAtom == atom.A }, func(n *html.Node) error {
+		target, err := page.url.Parse(strings.TrimSpace(htree.ElAttr(n, "href")))
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || !m.sameSite(target, page.url) {
+			return nil
+		}
+		key := crawlKey(target)
+		if seen[key] {
+			return nil
+		}
+		seen[key] = true
+
+		text, _ := htree.Text(n)
+		candidates = append(candidates, candidate{u: target, hinted: hasCrawlHint(target.Path + " " + text)})
+		return nil
+	})
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].hinted && !candidates[j].hinted
+	})
+	if len(candidates) > max {
+		candidates = candidates[:max]
+	}
+	result := make([]*url.URL, 0, len(candidates))
+	for _, c := range candidates {
+		result = append(result, c.u)
+	}
+	return result
+}
+
+// This tells whether s contains one of crawlHints.
+func hasCrawlHint(s string) bool {
+	s = strings.ToLower(s)
+	for _, hint := range crawlHints {
+		if strings.Contains(s, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// This identifies the page at u for deduplication,
+// disregarding any fragment.
+func crawlKey(u *url.URL) string {
+	v := *u
+	v.Fragment = ""
+	return v.String()
+}
@@ -0,0 +1,106 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCrawl(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/", pageHandler("text/html", `<html><body>
+<nav><a href="/products">Products</a> <a href="/news">News</a> <a href="https://elsewhere.example/">Partner</a> <a href="/company">Our company</a></nav>
+<p>Welcome</p>
+</body></html>`))
+	mux.Handle("/products", pageHandler("text/html", `<html><body>Products</body></html>`))
+	mux.Handle("/news", pageHandler("text/html", `<html><body>News</body></html>`))
+	mux.Handle("/company", pageHandler("text/html", `<html><body>Founded by <a href="/company/history">the Coalition</a> team. <a href="/company/acme">More</a></body></html>`))
+	mux.Handle("/company/acme", pageHandler("text/html", `<html><body>Acme Widgets</body></html>`))
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	domain := strings.TrimPrefix(srv.URL, "http://")
+
+	cases := []struct {
+		ref         string
+		depth       int
+		links       int
+		maxRequests int
+		want        bool
+		wantPath    string
+	}{
+		{ref: "Coalition", depth: 0, want: false},
+		{ref: "Coalition", depth: 1, want: true, wantPath: "/company"},
+		{ref: "Coalition", depth: 1, links: 1, want: true, wantPath: "/company"}, // "/company" comes first despite being last
+		{ref: "Coalition", depth: 1, maxRequests: 1, want: false},
+		{ref: "Acme", depth: 1, want: false},
+		{ref: "Acme", depth: 2, want: true, wantPath: "/company/acme"},
+		{ref: "Welcome", depth: 1, want: true, wantPath: "/"}, // on the home page itself
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%s_%d_%d_%d", c.ref, c.depth, c.links, c.maxRequests), func(t *testing.T) {
+			matcher := NewMatcher()
+			matcher.CrawlDepth = c.depth
+			matcher.CrawlLinks = c.links
+			matcher.MaxRequestsPerMatch = c.maxRequests
+
+			_, detail, err := matcher.doMatchDetail(context.Background(), c.ref, domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, o := range detail.Outcomes {
+				if o.Test != "WebPageRef" {
+					continue
+				}
+				if o.Passed != c.want {
+					t.Errorf("got WebPageRef passed %v, want %v", o.Passed, c.want)
+				}
+				var wantURL string
+				if c.wantPath != "" {
+					wantURL = srv.URL + c.wantPath
+				}
+				if o.URL != wantURL {
+					t.Errorf("got URL %q, want %q", o.URL, wantURL)
+				}
+			}
+		})
+	}
+}
+
+func TestCrawlLinks(t *testing.T) {
+	rec := &RequestRecorder{
+		Responses: map[string]CannedResponse{
+			"http://coalition.com/": {ContentType: "text/html", Body: `<html><body>
+<a href="/a">A</a> <a href="/b#top">B</a> <a href="/b">B again</a> <a href="/">Home</a>
+<a href="https://www.coalition.com/about-us">About us</a> <a href="mailto:info@coalition.com">Mail</a>
+<a href="https://example.com/about">Elsewhere</a>
+</body></html>`},
+		},
+	}
+	matcher := NewMatcher()
+	matcher.Client = &http.Client{Transport: rec}
+
+	in, err := matcher.newMatchInput("Coalition", "coalition.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := in.homePage(context.Background(), matcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := map[string]bool{crawlKey(page.url): true}
+	var got []string
+	for _, u := range matcher.crawlLinks(page, 10, seen) {
+		got = append(got, u.String())
+	}
+	want := []string{"https://www.coalition.com/about-us", "http://coalition.com/a", "http://coalition.com/b#top"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,190 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+)
+
+// Detail explains the result of matching a reference against a domain.
+// See Matcher.MatchDetail.
+type Detail struct {
+	// Score is the result of the match,
+	// the same as from Matcher.MatchContext.
+	Score float32
+
+	// Ref is the reference string that produced the score.
+	// It is the one given to MatchDetail,
+	// or one of its aliases (see Matcher.Aliases).
+	Ref string
+
+	// Outcomes are the outcomes of the tests that have a score in Matcher.Scores,
+	// in the order the Matcher defines its tests
+	// (which is the order of the test constants, e.g. RootPhrase before WebPageRef).
+	// The order does not depend on the order in which the tests finish.
+	Outcomes []TestOutcome
+
+	// Official tells whether the domain is known to be official for the reference
+	// (see Matcher.Official).
+	// If so, and Matcher.OfficialBoost is zero,
+	// no tests ran and Outcomes is empty.
+	Official bool
+
+	// TooFewPassed tells whether fewer than Matcher.MinPassingTests tests passed,
+	// so that the result is 0
+	// however well the others scored.
+	TooFewPassed bool
+
+	// Fetch describes the fetch of the domain's home page.
+	// It is nil if no test fetched it
+	// (e.g. because the web tests are disabled or were skipped).
+	Fetch *FetchInfo
+}
+
+// FetchInfo describes the fetch of a domain's home page during a match.
+type FetchInfo struct {
+	// URL is the final URL of the page,
+	// after any redirects
+	// (including those via <meta http-equiv="refresh">).
+	URL string
+
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// ContentType is the media type of the page,
+	// without parameters,
+	// e.g. "text/html".
+	ContentType string
+
+	// Err is the error that prevented fetching the page, if any.
+	// When it is set,
+	// the other fields are empty.
+	Err error
+}
+
+// TestOutcome is the outcome of one test in a match.
+type TestOutcome struct {
+	// Test is the name of the test, e.g. "RootPhrase".
+	Test string
+
+	// Skipped tells whether the test did not run.
+	// This happens when another test,
+	// named in SkippedBy,
+	// passed;
+	// when a test it requires,
+	// named in Unmet,
+	// did not pass;
+	// when the match's request budget ran out
+	// (see OverBudget);
+	// when the string tests decided the match
+	// (see Decided);
+	// when the domain is an IP address
+	// (see IPAddress);
+	// or otherwise when the test could not have changed the result.
+	Skipped   bool
+	SkippedBy string
+	Unmet     string
+
+	// OverBudget tells whether the test was skipped
+	// because no outbound requests remained for it
+	// (see Matcher.MaxRequestsPerMatch).
+	OverBudget bool
+
+	// Decided tells whether the test was skipped
+	// because the string tests were decisive
+	// (see Matcher.AmbiguousBand and Matcher.MatchAtLeast).
+	Decided bool
+
+	// IPAddress tells whether the test was skipped
+	// because the domain is an IP address
+	// (see Matcher.SkipIPNetworkTests).
+	IPAddress bool
+
+	// Passed tells whether the test passed.
+	Passed bool
+
+	// URL is the URL of the page that a test examining a web page
+	// (such as WebPageRef)
+	// passed on,
+	// after any redirects.
+	// It is empty if the test did not pass,
+	// or does not examine a page.
+	URL string
+
+	// Score is the test's contribution to the total score
+	// (before any TLD weight is applied):
+	// its value in Matcher.Scores if it passed
+	// (or the part of it that it earned, for a test that can pass partially,
+	// such as External),
+	// otherwise zero.
+	Score int
+
+	// Confidence is the fraction of its value in Matcher.Scores that the test earned,
+	// from 0 to 1:
+	// 1 for a test that simply passed,
+	// less for one that passed partially
+	// (such as MisspelledRootPhrase under Matcher.ScaleMisspellings),
+	// and 0 for one that did not pass.
+	// Score is this times the test's value,
+	// rounded.
+	Confidence float32
+}
+
+// Reason returns a human-readable description of o.
+func (o TestOutcome) Reason() string {
+	switch {
+	case o.OverBudget:
+		return fmt.Sprintf("%s skipped because the request budget was exhausted", o.Test)
+	case o.IPAddress:
+		return fmt.Sprintf("%s skipped because the domain is an IP address", o.Test)
+	case o.Decided:
+		return fmt.Sprintf("%s skipped because the string tests were decisive", o.Test)
+	case o.Skipped && o.Unmet != "":
+		return fmt.Sprintf("%s skipped because %s did not pass", o.Test, o.Unmet)
+	case o.Skipped && o.SkippedBy == "":
+		return fmt.Sprintf("%s skipped because it could not change the result", o.Test)
+	case o.Skipped:
+		return fmt.Sprintf("%s skipped because %s passed", o.Test, o.SkippedBy)
+	case o.Passed:
+		return fmt.Sprintf("%s passed (%+d)", o.Test, o.Score)
+	default:
+		return fmt.Sprintf("%s did not pass", o.Test)
+	}
+}
+
+// EvidenceURL returns the URL of the page
+// that corroborated the match,
+// i.e. the one that the first of d's passing page tests
+// (such as WebPageRef)
+// passed on.
+// The boolean result is false if no page test passed.
+func (d *Detail) EvidenceURL() (string, bool) {
+	for _, o := range d.Outcomes {
+		if o.URL != "" {
+			return o.URL, true
+		}
+	}
+	return "", false
+}
+
+// Reasons returns the Reason for each of d's outcomes,
+// in the same order as d.Outcomes.
+func (d *Detail) Reasons() []string {
+	result := make([]string, 0, len(d.Outcomes))
+	for _, o := range d.Outcomes {
+		result = append(result, o.Reason())
+	}
+	return result
+}
+
+// MatchDetail is like MatchContext
+// but explains its result with the outcome of each test.
+func (m Matcher) MatchDetail(ctx context.Context, ref, domain string) (*Detail, error) {
+	score, detail, err := m.doMatchDetail(ctx, ref, domain)
+	if err != nil {
+		m.metrics().Match(0, err)
+		return nil, err
+	}
+	detail.Score = m.scale(score)
+	m.metrics().Match(detail.Score, nil)
+	return detail, nil
+}
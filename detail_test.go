@@ -0,0 +1,128 @@
+package coalition
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchDetail(t *testing.T) {
+	srv, domain := newTestServer("text/html", "<html><body>welcome to coalition</body></html>")
+	defer srv.Close()
+
+	matcher := NewMatcher()
+	matcher.Scores[testJSONLDOrganization] = 20
+
+	// The test server's domain is an IP address.
+	want := []string{
+		"RootPhrase skipped because the domain is an IP address",
+		"AnyRootWord skipped because the domain is an IP address",
+		"MisspelledRootPhrase skipped because the domain is an IP address",
+		"SignificantAffixes skipped because the domain is an IP address",
+		"WebPageRef passed (+50)",
+		"JSONLDOrganization did not pass",
+	}
+
+	// The network tests finish in an unpredictable order.
+	// The outcomes must not.
+	for i := 0; i < 10; i++ {
+		detail, err := matcher.MatchDetail(context.Background(), "Coalition", domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := detail.Reasons(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("run %d: got %v, want %v", i, got, want)
+		}
+
+		score, err := matcher.MatchContext(context.Background(), "Coalition", domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if detail.Score != score {
+			t.Errorf("run %d: got score %v, want %v", i, detail.Score, score)
+		}
+	}
+
+	t.Run("skipped", func(t *testing.T) {
+		matcher := NewMatcher()
+		delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+		detail, err := matcher.MatchDetail(context.Background(), "Coalition", "coalition.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{
+			"RootPhrase passed (+50)",
+			"AnyRootWord skipped because RootPhrase passed",
+			"MisspelledRootPhrase skipped because RootPhrase passed",
+			"SignificantAffixes did not pass",
+		}
+		if got := detail.Reasons(); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		if detail.Ref != "Coalition" {
+			t.Errorf("got ref %q, want Coalition", detail.Ref)
+		}
+	})
+}
+
+func TestMatchDetailFetch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/", http.RedirectHandler("/home", http.StatusFound))
+	mux.Handle("/home", pageHandler("text/html; charset=utf-8", "<html><body>coalition</body></html>"))
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	domain := strings.TrimPrefix(srv.URL, "http://")
+
+	matcher := NewMatcher()
+	detail, err := matcher.MatchDetail(context.Background(), "Coalition", domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &FetchInfo{
+		URL:         srv.URL + "/home",
+		StatusCode:  http.StatusOK,
+		ContentType: "text/html",
+	}
+	if !reflect.DeepEqual(detail.Fetch, want) {
+		t.Errorf("got %+v, want %+v", detail.Fetch, want)
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		matcher := matcher.WithoutTest(TestWebPageRef)
+		detail, err := matcher.MatchDetail(context.Background(), "Coalition", domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if detail.Fetch != nil {
+			t.Errorf("got %+v, want nil", detail.Fetch)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			select {
+			case <-req.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}))
+		defer srv.Close()
+
+		matcher := matcher.Clone()
+		matcher.TestTimeouts = map[testType]time.Duration{testWebPageRef: 50 * time.Millisecond}
+
+		detail, err := matcher.MatchDetail(context.Background(), "Coalition", strings.TrimPrefix(srv.URL, "http://"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if detail.Fetch == nil || detail.Fetch.Err == nil {
+			t.Errorf("got %+v, want an error", detail.Fetch)
+		}
+	})
+}
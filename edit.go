@@ -0,0 +1,89 @@
+package coalition
+
+// editKind is the kind of an edit operation
+// (see editScript).
+type editKind int
+
+const (
+	editSubstitute editKind = iota + 1
+	editInsert
+	editDelete
+)
+
+// editOp is an operation in an edit script
+// transforming one string into another.
+type editOp struct {
+	kind editKind
+
+	// Pos is the position in the source string where the operation applies.
+	// For an insertion,
+	// it is the position before which the new character goes.
+	pos int
+}
+
+// editScript returns a shortest sequence of operations
+// (substitutions, insertions, and deletions)
+// that transforms a into b.
+// Its length is the Levenshtein distance between them.
+// Of the shortest scripts,
+// it chooses one whose edits come as late in a as possible,
+// so that e.g. "coallition" is "coalition" with an insertion after the "l",
+// not before it.
+// Positions are rune offsets.
+func editScript(a, b string) []editOp {
+	ar, br := []rune(a), []rune(b)
+
+	// d[i][j] is the edit distance between ar[i:] and br[j:].
+	d := make([][]int, len(ar)+1)
+	for i := range d {
+		d[i] = make([]int, len(br)+1)
+	}
+	for i := len(ar); i >= 0; i-- {
+		for j := len(br); j >= 0; j-- {
+			switch {
+			case i == len(ar):
+				d[i][j] = len(br) - j
+			case j == len(br):
+				d[i][j] = len(ar) - i
+			default:
+				best := d[i+1][j+1]
+				if ar[i] != br[j] {
+					best++
+				}
+				if v := d[i+1][j] + 1; v < best {
+					best = v
+				}
+				if v := d[i][j+1] + 1; v < best {
+					best = v
+				}
+				d[i][j] = best
+			}
+		}
+	}
+
+	// Walk forward,
+	// taking a match whenever it stays on a shortest path,
+	// which pushes edits toward the end.
+	var (
+		ops  []editOp
+		i, j int
+	)
+	for i < len(ar) || j < len(br) {
+		switch {
+		case i < len(ar) && j < len(br) && ar[i] == br[j] && d[i][j] == d[i+1][j+1]:
+			i++
+			j++
+		case i < len(ar) && j < len(br) && d[i][j] == d[i+1][j+1]+1:
+			ops = append(ops, editOp{kind: editSubstitute, pos: i})
+			i++
+			j++
+		case i < len(ar) && d[i][j] == d[i+1][j]+1:
+			ops = append(ops, editOp{kind: editDelete, pos: i})
+			i++
+		default:
+			ops = append(ops, editOp{kind: editInsert, pos: i})
+			j++
+		}
+	}
+	return ops
+}
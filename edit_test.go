@@ -0,0 +1,30 @@
+package coalition
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditScript(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want []editOp
+	}{
+		{a: "coalition", b: "coalition"},
+		{a: "coalition", b: "xoalition", want: []editOp{{kind: editSubstitute, pos: 0}}},
+		{a: "coalition", b: "oalition", want: []editOp{{kind: editDelete, pos: 0}}},
+		{a: "coalition", b: "coallition", want: []editOp{{kind: editInsert, pos: 4}}},
+		{a: "coalition", b: "coalitoin", want: []editOp{{kind: editSubstitute, pos: 6}, {kind: editSubstitute, pos: 7}}},
+		{a: "coalition", b: "coalitions", want: []editOp{{kind: editInsert, pos: 9}}},
+		{a: "société", b: "societe", want: []editOp{{kind: editSubstitute, pos: 4}, {kind: editSubstitute, pos: 6}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.b, func(t *testing.T) {
+			got := editScript(c.a, c.b)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package coalition
+
+import "context"
+
+// ExternalScorer is an application-supplied source of evidence,
+// such as a machine-learned model,
+// that the External test consults.
+// See Matcher.External.
+type ExternalScorer interface {
+	// Score rates how strongly domain exhibits whatever the scorer measures
+	// for the organization named by ref,
+	// as a percentage from 0 to 100.
+	// The External test earns that percentage of its score in Matcher.Scores.
+	// The boolean result is false if the scorer has no opinion,
+	// in which case the test does not pass.
+	// It is consulted once per match,
+	// with the reference as given,
+	// not again for each of the reference's aliases
+	// (see Matcher.Aliases).
+	Score(ctx context.Context, ref, domain string) (int, bool, error)
+}
+
+func runExternalTest(ctx context.Context, m Matcher, in *matchInput) (float32, error) {
+	if m.External == nil {
+		return 0, nil
+	}
+	pct, ok, err := m.External.Score(ctx, in.ref, in.domain)
+	if err != nil || !ok {
+		return 0, err
+	}
+	if pct < 0 {
+		pct = 0
+	}
+	if pct > 100 {
+		pct = 100
+	}
+	return float32(pct) / 100, nil
+}
@@ -0,0 +1,110 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// fakeScorer maps each domain to the percentage it scores.
+// Domains not in the map get no opinion.
+type fakeScorer map[string]int
+
+func (s fakeScorer) Score(_ context.Context, _, domain string) (int, bool, error) {
+	pct, ok := s[domain]
+	return pct, ok, nil
+}
+
+func TestExternalTest(t *testing.T) {
+	// A typosquat-likelihood model: the higher the percentage, the less likely the match.
+	scorer := fakeScorer{
+		"coalitioninc.com":       0,
+		"coalition-rutabaga.com": 75,
+		"coalitioninc.net":       150, // clamped to 100
+	}
+
+	cases := []struct {
+		domain string
+		want   int
+	}{
+		{domain: "coalitioninc.com", want: 50},
+		{domain: "coalition-rutabaga.com", want: 10},
+		{domain: "coalition-help.com", want: 40}, // no opinion
+		{domain: "coalitioninc.net", want: 10},
+	}
+
+	for _, c := range cases {
+		t.Run(c.domain, func(t *testing.T) {
+			matcher := NewMatcher().WithScore(TestExternal, -40)
+			delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+			matcher.External = scorer
+
+			got, err := matcher.doMatch(context.Background(), "Coalition, Inc", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+
+	t.Run("influences_result", func(t *testing.T) {
+		matcher := NewMatcher().WithScore(TestExternal, -40)
+		delete(matcher.Scores, testWebPageRef)
+
+		without, err := matcher.Match("Coalition, Inc", "coalition-rutabaga.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		matcher.External = scorer
+		with, err := matcher.Match("Coalition, Inc", "coalition-rutabaga.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if with >= without {
+			t.Errorf("got %v with the scorer, want less than %v without", with, without)
+		}
+	})
+
+	t.Run("detail", func(t *testing.T) {
+		matcher := NewMatcher().WithScore(TestExternal, 20)
+		delete(matcher.Scores, testWebPageRef)
+		matcher.External = fakeScorer{"coalition-help.com": 50}
+
+		_, detail, err := matcher.doMatchDetail(context.Background(), "Coalition, Inc", "coalition-help.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var found bool
+		for _, o := range detail.Outcomes {
+			if o.Test != string(TestExternal) {
+				continue
+			}
+			found = true
+			if !o.Passed || o.Score != 10 {
+				t.Errorf("got %s, want External passed (+10)", o.Reason())
+			}
+		}
+		if !found {
+			t.Error("no outcome for the External test")
+		}
+	})
+}
+
+type errScorer struct{}
+
+func (errScorer) Score(context.Context, string, string) (int, bool, error) {
+	return 0, false, fmt.Errorf("model unavailable")
+}
+
+func TestExternalError(t *testing.T) {
+	matcher := NewMatcher().WithScore(TestExternal, -40)
+	delete(matcher.Scores, testWebPageRef)
+	matcher.External = errScorer{}
+
+	if _, err := matcher.Match("Coalition, Inc", "coalitioninc.com"); err == nil {
+		t.Error("got no error, want one")
+	}
+}
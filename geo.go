@@ -0,0 +1,96 @@
+package coalition
+
+import "strings"
+
+// DefaultGeoTerms is a Stopper for common geographic terms,
+// in English and some other languages:
+// countries, regions,
+// and words like "global" and "international"
+// (see Matcher.GeoTerms).
+// Multi-word terms appear without spaces,
+// so "United States" is "unitedstates".
+var DefaultGeoTerms Stopper = simpleStopper{
+	// Scope
+	"global":        true,
+	"international": true,
+	"intl":          true,
+	"worldwide":     true,
+
+	// Regions
+	"africa":       true,
+	"america":      true,
+	"americas":     true,
+	"apac":         true,
+	"asia":         true,
+	"asiapacific":  true,
+	"emea":         true,
+	"eu":           true,
+	"europe":       true,
+	"latam":        true,
+	"latinamerica": true,
+	"middleeast":   true,
+	"nordic":       true,
+	"nordics":      true,
+	"northamerica": true,
+
+	// Countries
+	"argentina":     true,
+	"australia":     true,
+	"austria":       true,
+	"belgium":       true,
+	"brasil":        true,
+	"brazil":        true,
+	"canada":        true,
+	"china":         true,
+	"denmark":       true,
+	"deutschland":   true,
+	"espana":        true,
+	"finland":       true,
+	"france":        true,
+	"germany":       true,
+	"hongkong":      true,
+	"india":         true,
+	"ireland":       true,
+	"israel":        true,
+	"italia":        true,
+	"italy":         true,
+	"japan":         true,
+	"korea":         true,
+	"mexico":        true,
+	"nederland":     true,
+	"netherlands":   true,
+	"newzealand":    true,
+	"norway":        true,
+	"poland":        true,
+	"portugal":      true,
+	"schweiz":       true,
+	"singapore":     true,
+	"southafrica":   true,
+	"spain":         true,
+	"suisse":        true,
+	"sweden":        true,
+	"switzerland":   true,
+	"uae":           true,
+	"uk":            true,
+	"unitedkingdom": true,
+	"unitedstates":  true,
+	"us":            true,
+	"usa":           true,
+}
+
+// maxGeoTermWords is the most words a geographic term may span,
+// as in "Asia Pacific".
+const maxGeoTermWords = 2
+
+// trailingGeoTerm returns the number of words at the end of words
+// that together spell a geographic term according to geo,
+// or zero if there is none.
+// At least one word is always left over.
+func trailingGeoTerm(words []string, geo Stopper) int {
+	for n := 1; n <= maxGeoTermWords && n < len(words); n++ {
+		if geo.IsStopWord(strings.Join(words[len(words)-n:], "")) {
+			return n
+		}
+	}
+	return 0
+}
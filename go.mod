@@ -3,7 +3,12 @@ module coalition
 go 1.13
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/agnivade/levenshtein v1.0.3
 	github.com/bobg/htree v1.2.0
 	golang.org/x/net v0.0.0-20200226121028-0de0cce0169b
+	golang.org/x/sync v0.0.0-20190911185100-cd5d95a43a6e
+	golang.org/x/text v0.3.2
+	golang.org/x/time v0.0.0-20191024005414-555d28b269f0
+	gopkg.in/yaml.v2 v2.2.8
 )
@@ -0,0 +1,47 @@
+package coalition
+
+import (
+	"bytes"
+	"io"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxHeadLen is the most of an HTML page that readHead reads.
+const maxHeadLen = 256 << 10
+
+// This reads the HTML in r up to the end of its <head>,
+// the start of its <body>,
+// or maxHeadLen bytes,
+// whichever comes first,
+// and returns what it read,
+// leaving the rest of r unread
+// (apart from what the tokenizer has buffered).
+// See Matcher.HeadOnly.
+func readHead(r io.Reader) ([]byte, error) {
+	var (
+		buf bytes.Buffer
+		z   = html.NewTokenizer(io.TeeReader(io.LimitReader(r, maxHeadLen), &buf))
+		n   int // the length of the tokens before the stopping point
+	)
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); err != io.EOF {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		}
+
+		name, _ := z.TagName()
+		switch a := atom.Lookup(name); {
+		case tt == html.StartTagToken && a == atom.Body:
+			return buf.Bytes()[:n], nil
+		case tt == html.EndTagToken && a == atom.Head:
+			n += len(z.Raw())
+			return buf.Bytes()[:n], nil
+		}
+		n += len(z.Raw())
+	}
+}
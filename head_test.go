@@ -0,0 +1,122 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// countingTransport counts the bytes read from the bodies of its responses.
+type countingTransport struct {
+	n int64
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &countingBody{ReadCloser: resp.Body, n: &t.n}
+	return resp, nil
+}
+
+type countingBody struct {
+	io.ReadCloser
+	n *int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	atomic.AddInt64(b.n, int64(n))
+	return n, err
+}
+
+func TestHeadOnly(t *testing.T) {
+	const bodyLen = 4 << 20
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>Welcome to coalition</title></head><body><p>acme</p>`)
+		filler := strings.Repeat("<p>filler</p>", 1000)
+		for written := 0; written < bodyLen; written += len(filler) {
+			if _, err := io.WriteString(w, filler); err != nil {
+				return
+			}
+		}
+		fmt.Fprint(w, `</body></html>`)
+	}))
+	defer srv.Close()
+
+	domain := strings.TrimPrefix(srv.URL, "http://")
+
+	cases := []struct {
+		ref      string
+		headOnly bool
+		want     int
+	}{
+		{ref: "Coalition", headOnly: false, want: 50},
+		{ref: "Coalition", headOnly: true, want: 50},
+		{ref: "Acme", headOnly: false, want: 50},
+		{ref: "Acme", headOnly: true, want: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%s_%v", c.ref, c.headOnly), func(t *testing.T) {
+			transport := new(countingTransport)
+
+			matcher := NewMatcher()
+			matcher.Client = &http.Client{Transport: transport}
+			matcher.HeadOnly = c.headOnly
+
+			got, err := matcher.doMatch(context.Background(), c.ref, domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+
+			read := atomic.LoadInt64(&transport.n)
+			if c.headOnly && read >= bodyLen/2 {
+				t.Errorf("read %d bytes, want much less than %d", read, bodyLen)
+			}
+			if !c.headOnly && read < bodyLen {
+				t.Errorf("read %d bytes, want at least %d", read, bodyLen)
+			}
+		})
+	}
+}
+
+func TestReadHead(t *testing.T) {
+	cases := []struct {
+		page, want string
+	}{
+		{
+			page: `<html><head><title>Coalition</title></head><body>more</body></html>`,
+			want: `<html><head><title>Coalition</title></head>`,
+		},
+		{
+			page: `<html><HEAD><title>Coalition</title><BODY>more</body></html>`,
+			want: `<html><HEAD><title>Coalition</title>`,
+		},
+		{
+			page: `<title>Coalition</title>`,
+			want: `<title>Coalition</title>`,
+		},
+	}
+
+	for _, c := range cases {
+		got, err := readHead(strings.NewReader(c.page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != c.want {
+			t.Errorf("got %q, want %q", got, c.want)
+		}
+	}
+}
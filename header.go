@@ -0,0 +1,34 @@
+package coalition
+
+import (
+	"context"
+	"net/http"
+)
+
+// DefaultResponseHeaders are the response headers inspected by the ResponseHeader test
+// when Matcher.ResponseHeaders is nil.
+var DefaultResponseHeaders = []string{"Server", "X-Powered-By", "X-Served-By", "Via"}
+
+func runResponseHeaderTest(ctx context.Context, m Matcher, in *matchInput) (bool, error) {
+	page, err := in.homePage(ctx, m)
+	if err != nil {
+		return false, err
+	}
+
+	names := m.ResponseHeaders
+	if names == nil {
+		names = DefaultResponseHeaders
+	}
+	return doResponseHeaderTest(page, names, m.textMatcher(in, in.re)), nil
+}
+
+func doResponseHeaderTest(page *webPage, names []string, tm textMatcher) bool {
+	for _, name := range names {
+		for _, val := range page.header[http.CanonicalHeaderKey(name)] {
+			if tm.match(val) {
+				return true
+			}
+		}
+	}
+	return false
+}
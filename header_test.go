@@ -0,0 +1,51 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponseHeaderTest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Server", "Coalition-Edge/2.1")
+		w.Header().Set("X-Organization", "Acme Widgets")
+		w.Header().Set("X-Operator", "SOCIÉTÉ GÉNÉRALE")
+		fmt.Fprint(w, "\x00\x01\x02")
+	}))
+	defer srv.Close()
+
+	domain := strings.TrimPrefix(srv.URL, "http://")
+
+	cases := []struct {
+		ref     string
+		headers []string
+		want    int
+	}{
+		{ref: "Coalition", want: 5},
+		{ref: "Acme Widgets", want: 0}, // not a default header
+		{ref: "Acme Widgets", headers: []string{"x-organization"}, want: 5},
+		{ref: "Coalition", headers: []string{"x-organization"}, want: 0},
+		{ref: "Societe Generale", headers: []string{"x-operator"}, want: 5},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%s_%v", c.ref, c.headers), func(t *testing.T) {
+			matcher := NewMatcher().WithScore(TestResponseHeader, 5)
+			delete(matcher.Scores, testWebPageRef)
+			matcher.ResponseHeaders = c.headers
+
+			got, err := matcher.doMatch(context.Background(), c.ref, domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package coalition
+
+import (
+	"context"
+	"strings"
+)
+
+// MatchHints carries what is known about the organization being matched,
+// beyond its name.
+// See Matcher.MatchWithContext.
+type MatchHints struct {
+	// Country is the ISO 3166-1 alpha-2 code of the organization's country,
+	// like "DE" or "FR".
+	// When set,
+	// legal forms are removed from the reference
+	// (as with Matcher.LegalForms),
+	// the articles of the country's language
+	// (e.g. "der", "die", and "das" for Germany)
+	// are treated as stop words,
+	// and domains under the country's top-level domain
+	// get CountryTLDWeight.
+	Country string
+
+	// CountryTLDWeight is the TLD weight
+	// (see Matcher.TLDWeights)
+	// for domains under the top-level domain of Country,
+	// unless Matcher.TLDWeights already has an entry for it.
+	// If zero, DefaultCountryTLDWeight is used.
+	CountryTLDWeight float32
+}
+
+// DefaultCountryTLDWeight is the weight for a domain under the top-level domain of MatchHints.Country
+// when MatchHints.CountryTLDWeight is zero.
+const DefaultCountryTLDWeight = 1.1
+
+// countryStopWords are stop words to add for a given country
+// (see MatchHints.Country),
+// namely the articles of its main language(s).
+var countryStopWords = map[string][]string{
+	"at": {"der", "die", "das"},
+	"be": {"de", "het", "le", "la", "les"},
+	"br": {"o", "a", "os", "as"},
+	"ch": {"der", "die", "das", "le", "la", "les"},
+	"de": {"der", "die", "das"},
+	"es": {"el", "la", "los", "las"},
+	"fr": {"le", "la", "les"},
+	"it": {"il", "lo", "la", "gli", "le"},
+	"mx": {"el", "la", "los", "las"},
+	"nl": {"de", "het"},
+	"pt": {"o", "a", "os", "as"},
+}
+
+// MatchWithContext is like MatchContext
+// but takes into account what is known about the organization,
+// as given in hints.
+func (m Matcher) MatchWithContext(ctx context.Context, ref, domain string, hints MatchHints) (float32, error) {
+	return m.withHints(hints).MatchContext(ctx, ref, domain)
+}
+
+// This returns a copy of m adjusted for hints.
+func (m Matcher) withHints(hints MatchHints) Matcher {
+	country := strings.ToLower(strings.TrimSpace(hints.Country))
+	if country == "" {
+		return m
+	}
+
+	result := m.Clone()
+	result.LegalForms = true
+
+	if words := countryStopWords[country]; len(words) > 0 {
+		stop := make(simpleStopper)
+		for _, word := range words {
+			stop[word] = true
+		}
+		if m.Stop != nil {
+			result.Stop = multiStopper{m.Stop, stop}
+		} else {
+			result.Stop = stop
+		}
+	}
+
+	tld := country
+	if tld == "gb" {
+		tld = "uk"
+	}
+	if _, ok := result.TLDWeights[tld]; !ok {
+		weight := hints.CountryTLDWeight
+		if weight == 0 {
+			weight = DefaultCountryTLDWeight
+		}
+		if result.TLDWeights == nil {
+			result.TLDWeights = make(map[string]float32)
+		}
+		result.TLDWeights[tld] = weight
+	}
+
+	return result
+}
@@ -0,0 +1,75 @@
+package coalition
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMatchWithContext(t *testing.T) {
+	cases := []struct {
+		ref, domain string
+		country     string
+	}{
+		// Legal forms are removed and the .de TLD is weighted.
+		{ref: "Deutsche Bank AG", domain: "deutschebank.de", country: "DE"},
+
+		// The German article is a stop word.
+		{ref: "Die Zeit", domain: "zeit.de", country: "DE"},
+
+		// Only the TLD weight applies.
+		{ref: "Coalition", domain: "coalition.de", country: "de"},
+	}
+
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			without, err := matcher.MatchWithContext(context.Background(), c.ref, c.domain, MatchHints{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			with, err := matcher.MatchWithContext(context.Background(), c.ref, c.domain, MatchHints{Country: c.country})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if with <= without {
+				t.Errorf("got %v with country hint, %v without; want more with", with, without)
+			}
+		})
+	}
+
+	t.Run("other_country", func(t *testing.T) {
+		without, err := matcher.MatchWithContext(context.Background(), "Coalition", "coalition.fr", MatchHints{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		with, err := matcher.MatchWithContext(context.Background(), "Coalition", "coalition.fr", MatchHints{Country: "DE"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if with != without {
+			t.Errorf("got %v with DE hint for a .fr domain, want %v", with, without)
+		}
+	})
+
+	t.Run("explicit_weight", func(t *testing.T) {
+		matcher := matcher.Clone()
+		matcher.TLDWeights = map[string]float32{"de": 0.5}
+
+		with, err := matcher.MatchWithContext(context.Background(), "Coalition", "coalition.de", MatchHints{Country: "DE"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		without, err := matcher.MatchContext(context.Background(), "Coalition", "coalition.de")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if with != without {
+			t.Errorf("got %v with hint, want %v (the explicit TLD weight)", with, without)
+		}
+		if matcher.LegalForms {
+			t.Error("hints changed the receiver")
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package coalition
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/bobg/htree"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// titleSeparator splits a page title into segments,
+// as in "Coalition | Cyber Insurance".
+var titleSeparator = regexp.MustCompile(`\s+[-|–—:·]\s+|\s*[|·]\s*`)
+
+// minStrippedLen is the shortest remainder
+// that InferName leaves when removing a stop word glued to a domain label,
+// so that "getcoalition" becomes "coalition"
+// but "theo" stays "theo".
+const minStrippedLen = 3
+
+// InferName guesses the name of the organization that owns domain,
+// for the reverse of the usual matching:
+// from a domain to a reference string.
+// It prefers the name of an organization described in JSON-LD
+// on the domain's home page
+// (as with the JSONLDOrganization test),
+// then a segment of the page's title that matches the domain,
+// as in "Coalition, Inc." for coalitioninc.com
+// from the title "Coalition, Inc. | Cyber Insurance".
+// Failing those,
+// it guesses from the registrable label of the domain alone,
+// split into words
+// (see DomainTokenizer)
+// and stripped of stop words,
+// so "getcoalition.com" yields "coalition".
+//
+// If the home page can't be fetched,
+// the guess comes from the domain alone.
+// It is an error if there is no guess to make.
+func (m Matcher) InferName(ctx context.Context, domain string) (string, error) {
+	in, err := m.newMatchInput("", domain)
+	if err != nil {
+		return "", err
+	}
+
+	label := m.registrableLabel(in.domain)
+	if in.ip {
+		label = ""
+	}
+
+	if in.budget.reserve(testDef{homePage: true}) {
+		page, err := in.homePage(ctx, m)
+		if err != nil && ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if err == nil {
+			if tree := page.htmlTree(); tree != nil {
+				if name := m.pageName(tree, label, in.ip); name != "" {
+					return name, nil
+				}
+			}
+		}
+	}
+
+	if name := m.labelName(label); name != "" {
+		return name, nil
+	}
+	return "", errors.New("cannot infer a name")
+}
+
+// This returns the label of domain just before its public suffix,
+// as in "coalition" for "www.coalition.co.uk".
+func (m Matcher) registrableLabel(domain string) string {
+	site := m.registrable(domain)
+	suffix := m.publicSuffixList().PublicSuffix(site)
+	if label := strings.TrimSuffix(site, "."+suffix); label != site {
+		return label
+	}
+	return strings.Split(domain, ".")[0]
+}
+
+// This returns the organization name found in tree
+// (see InferName),
+// or the empty string.
+// A title segment must match label,
+// unless the domain is an IP address,
+// in which case the first segment is used.
+func (m Matcher) pageName(tree *html.Node, label string, ip bool) string {
+	for _, name := range jsonLDOrgNames(tree) {
+		if name = strings.TrimSpace(name); name != "" {
+			return name
+		}
+	}
+
+	title := htree.FindEl(tree, func(n *html.Node) bool { return n.DataAtom == atom.Title })
+	if title == nil {
+		return ""
+	}
+	text, err := htree.Text(title)
+	if err != nil {
+		return ""
+	}
+	joinedLabel := strings.Join(m.domainTokens(label), "")
+	for _, seg := range titleSeparator.Split(text, -1) {
+		seg = strings.TrimSpace(seg)
+		if seg == "" {
+			continue
+		}
+		if ip {
+			return seg
+		}
+		if joined := strings.Join(m.normalizedRootPhrase(seg), ""); joined != "" && strings.Contains(joinedLabel, joined) {
+			return seg
+		}
+	}
+	return ""
+}
+
+// This guesses an organization name from label
+// (see InferName),
+// or returns the empty string.
+func (m Matcher) labelName(label string) string {
+	words := m.domainTokens(label)
+	for len(words) > 1 && m.isStopWordRun(words[0]) {
+		words = words[1:]
+	}
+	for len(words) > 1 && m.isStopWordRun(words[len(words)-1]) {
+		words = words[:len(words)-1]
+	}
+	if len(words) == 0 {
+		return ""
+	}
+
+	// Stop words are often glued on,
+	// as in "getcoalition" and "coalitioninc".
+	for _, prefix := range suggestionPrefixes {
+		if first := words[0]; m.isStopWord(prefix) && strings.HasPrefix(first, prefix) && len(first)-len(prefix) >= minStrippedLen {
+			words[0] = first[len(prefix):]
+			break
+		}
+	}
+	for _, suffix := range suggestionSuffixes {
+		if last := words[len(words)-1]; m.isStopWord(suffix) && strings.HasSuffix(last, suffix) && len(last)-len(suffix) >= minStrippedLen {
+			words[len(words)-1] = last[:len(last)-len(suffix)]
+			break
+		}
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,63 @@
+package coalition
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestInferName(t *testing.T) {
+	const (
+		titlePage  = `<html><head><title>Home | Coalition, Inc. | Cyber Insurance</title></head><body>Welcome</body></html>`
+		otherPage  = `<html><head><title>Welcome - Cyber Insurance</title></head><body>Welcome</body></html>`
+		jsonLDPage = `<html><head><title>Coalition</title>
+<script type="application/ld+json">{"@context": "https://schema.org", "@type": "Organization", "name": "Coalition Insurance Solutions"}</script>
+</head><body>Welcome</body></html>`
+	)
+
+	cases := []struct {
+		domain, page, want string
+	}{
+		{domain: "getcoalition.com", want: "coalition"},
+		{domain: "coalition-inc.com", want: "coalition"},
+		{domain: "www.coalitioninc.co.uk", want: "coalition"},
+		{domain: "acme-widgets.com", want: "acme widgets"},
+		{domain: "theo.com", want: "theo"},
+		{domain: "coalitioninc.com", page: titlePage, want: "Coalition, Inc."},
+		{domain: "getcoalition.com", page: otherPage, want: "coalition"},
+		{domain: "coalition.com", page: jsonLDPage, want: "Coalition Insurance Solutions"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.domain, func(t *testing.T) {
+			rec := &RequestRecorder{Responses: map[string]CannedResponse{}}
+			if c.page != "" {
+				rec.Responses["http://"+c.domain+"/"] = CannedResponse{ContentType: "text/html", Body: c.page}
+			}
+
+			matcher := NewMatcher()
+			matcher.Client = &http.Client{Transport: rec}
+
+			got, err := matcher.InferName(context.Background(), c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+
+	t.Run("ip", func(t *testing.T) {
+		srv, domain := newTestServer("text/html", `<html><head><title>Coalition | Cyber Insurance</title></head></html>`)
+		defer srv.Close()
+
+		got, err := NewMatcher().InferName(context.Background(), domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "Coalition" {
+			t.Errorf("got %q, want Coalition", got)
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package coalition
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/bobg/htree"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// orgTypes are the schema.org types treated as organizations by the JSONLDOrganization test.
+// See https://schema.org/Organization for the full hierarchy.
+var orgTypes = map[string]bool{
+	"Organization":            true,
+	"Corporation":             true,
+	"LocalBusiness":           true,
+	"NGO":                     true,
+	"EducationalOrganization": true,
+	"GovernmentOrganization":  true,
+	"NewsMediaOrganization":   true,
+	"SportsOrganization":      true,
+	"MedicalOrganization":     true,
+}
+
+func runJSONLDOrganizationTest(ctx context.Context, m Matcher, in *matchInput) (bool, error) {
+	page, err := in.homePage(ctx, m)
+	if err != nil {
+		return false, err
+	}
+	return doJSONLDOrganizationTest(page, m.textMatcher(in, in.re)), nil
+}
+
+func doJSONLDOrganizationTest(page *webPage, tm textMatcher) bool {
+	tree := page.htmlTree()
+	if tree == nil {
+		return false
+	}
+	for _, name := range jsonLDOrgNames(tree) {
+		if tm.match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonLDOrgNames finds the <script type="application/ld+json"> blocks in tree
+// and returns the name and legalName of each organization they describe.
+// Blocks that cannot be decoded are skipped.
+func jsonLDOrgNames(tree *html.Node) []string {
+	var names []string
+
+	isJSONLD := func(n *html.Node) bool {
+		return n.DataAtom == atom.Script && strings.EqualFold(strings.TrimSpace(htree.ElAttr(n, "type")), "application/ld+json")
+	}
+	htree.FindAllEls(tree, isJSONLD, func(n *html.Node) error {
+		var text strings.Builder
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.TextNode {
+				text.WriteString(child.Data)
+			}
+		}
+		var data interface{}
+		if err := json.Unmarshal([]byte(text.String()), &data); err != nil {
+			return nil // skip malformed blocks
+		}
+		names = appendJSONLDOrgNames(names, data)
+		return nil
+	})
+
+	return names
+}
+
+// appendJSONLDOrgNames walks decoded JSON-LD data,
+// appending the names of organizations to names.
+// Organizations may appear at the top level,
+// in arrays and "@graph" lists,
+// or nested in other objects (e.g. as the "publisher" of a WebSite).
+func appendJSONLDOrgNames(names []string, data interface{}) []string {
+	switch data := data.(type) {
+	case []interface{}:
+		for _, elt := range data {
+			names = appendJSONLDOrgNames(names, elt)
+		}
+
+	case map[string]interface{}:
+		if isJSONLDOrg(data["@type"]) {
+			for _, key := range []string{"name", "legalName"} {
+				if name, ok := data[key].(string); ok && name != "" {
+					names = append(names, name)
+				}
+			}
+		}
+		for key, val := range data {
+			if key == "@type" || key == "@context" {
+				continue
+			}
+			names = appendJSONLDOrgNames(names, val)
+		}
+	}
+	return names
+}
+
+// isJSONLDOrg tells whether typ,
+// the value of a JSON-LD "@type" field,
+// names an organization type.
+// The value may be a single type or a list of them,
+// and may be a full URL like "https://schema.org/Organization".
+func isJSONLDOrg(typ interface{}) bool {
+	switch typ := typ.(type) {
+	case string:
+		if i := strings.LastIndexAny(typ, "/#:"); i >= 0 {
+			typ = typ[i+1:]
+		}
+		return orgTypes[typ]
+
+	case []interface{}:
+		for _, elt := range typ {
+			if isJSONLDOrg(elt) {
+				return true
+			}
+		}
+	}
+	return false
+}
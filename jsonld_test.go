@@ -0,0 +1,54 @@
+package coalition
+
+import (
+	"context"
+	"testing"
+)
+
+func TestJSONLDOrganizationTest(t *testing.T) {
+	const page = `<html>
+<head>
+<script type="application/ld+json">{"@context": "https://schema.org", "@type": "WebSite", "name": "Home"}</script>
+<script type="application/ld+json">this is not JSON</script>
+<script type="application/ld+json">
+{
+  "@context": "https://schema.org",
+  "@graph": [
+    {"@type": "WebPage", "name": "Welcome"},
+    {"@type": ["Organization", "Corporation"], "name": "CI", "legalName": "Coalition, Inc."},
+    {"@type": "Organization", "name": "SOCIÉTÉ GÉNÉRALE"}
+  ]
+}
+</script>
+</head>
+<body>Cyber insurance for everyone</body>
+</html>`
+
+	srv, domain := newTestServer("text/html", page)
+	defer srv.Close()
+
+	cases := []struct {
+		ref  string
+		want int
+	}{
+		{ref: "Coalition", want: 20},
+		{ref: "Coalition Security", want: 0},
+		{ref: "Welcome", want: 0}, // not an organization
+		{ref: "Societe Generale", want: 20},
+	}
+
+	matcher := NewMatcher()
+	matcher.Scores = map[testType]int{testJSONLDOrganization: 20}
+
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			got, err := matcher.doMatch(context.Background(), c.ref, domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package coalition
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bobg/htree"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/text/language"
+)
+
+func runPageLanguageTest(ctx context.Context, m Matcher, in *matchInput) (bool, error) {
+	same, ok, err := m.samePageLanguage(ctx, in)
+	return ok && same, err
+}
+
+func runPageLanguageMismatchTest(ctx context.Context, m Matcher, in *matchInput) (bool, error) {
+	same, ok, err := m.samePageLanguage(ctx, in)
+	return ok && !same, err
+}
+
+// This tells whether the home page for in declares the language in m.ExpectedPageLanguage.
+// The second result is false
+// if there is no expected language
+// or the page declares none.
+func (m Matcher) samePageLanguage(ctx context.Context, in *matchInput) (same, ok bool, err error) {
+	if m.ExpectedPageLanguage == language.Und {
+		return false, false, nil
+	}
+	page, err := in.homePage(ctx, m)
+	if err != nil {
+		return false, false, err
+	}
+	tag, ok := pageLanguage(page)
+	if !ok {
+		return false, false, nil
+	}
+	want, _ := m.ExpectedPageLanguage.Base()
+	got, _ := tag.Base()
+	return got == want, true, nil
+}
+
+// pageLanguage returns the language that page declares:
+// in the lang attribute of its <html> element,
+// or failing that,
+// in the Content-Language header of its response.
+// It is false if there is none,
+// or none that can be parsed.
+func pageLanguage(page *webPage) (language.Tag, bool) {
+	var decl string
+	if tree := page.htmlTree(); tree != nil {
+		if el := htree.FindEl(tree, func(n *html.Node) bool { return n.DataAtom == atom.Html }); el != nil {
+			decl = htree.ElAttr(el, "lang")
+		}
+	}
+	if decl == "" && page.header != nil {
+		// The header may list several languages;
+		// use the first.
+		decl = strings.Split(page.header.Get("Content-Language"), ",")[0]
+	}
+	decl = strings.TrimSpace(decl)
+	if decl == "" {
+		return language.Und, false
+	}
+	tag, err := language.Parse(decl)
+	if err != nil || tag == language.Und {
+		return language.Und, false
+	}
+	return tag, true
+}
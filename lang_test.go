@@ -0,0 +1,60 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestPageLanguageTest(t *testing.T) {
+	page := func(htmlTag string) string {
+		return htmlTag + "<body>Willkommen bei Coalition</body></html>"
+	}
+
+	cases := []struct {
+		name     string
+		html     string
+		header   string
+		expected language.Tag
+		want     int
+	}{
+		{name: "match", html: page(`<html lang="de">`), expected: language.MustParse("de-CH"), want: 5},
+		{name: "match_region", html: page(`<html lang="de-DE">`), expected: language.German, want: 5},
+		{name: "mismatch", html: page(`<html lang="en-US">`), expected: language.German, want: -5},
+		{name: "undeclared", html: page(`<html>`), expected: language.German, want: 0},
+		{name: "malformed", html: page(`<html lang="!!">`), expected: language.German, want: 0},
+		{name: "header", html: page(`<html>`), header: "de, en", expected: language.German, want: 5},
+		{name: "attribute_first", html: page(`<html lang="fr">`), header: "de", expected: language.German, want: -5},
+		{name: "no_expectation", html: page(`<html lang="de">`), want: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				if c.header != "" {
+					w.Header().Set("Content-Language", c.header)
+				}
+				fmt.Fprint(w, c.html)
+			}))
+			defer srv.Close()
+
+			matcher := NewMatcher()
+			matcher.Scores = map[testType]int{testPageLanguage: 5, testPageLanguageMismatch: -5}
+			matcher.ExpectedPageLanguage = c.expected
+
+			got, err := matcher.doMatch(context.Background(), "Coalition", strings.TrimPrefix(srv.URL, "http://"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
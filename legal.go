@@ -0,0 +1,88 @@
+package coalition
+
+import "strings"
+
+// legalForms are the abbreviations and words,
+// in several languages,
+// that denote the legal form of an organization,
+// like "Inc", "GmbH", and "SARL".
+// Abbreviations appear without punctuation,
+// so "s.a.r.l." is "sarl".
+// See Matcher.LegalForms.
+var legalForms = map[string]bool{
+	// English
+	"co":           true,
+	"company":      true,
+	"corp":         true,
+	"corporation":  true,
+	"inc":          true,
+	"incorporated": true,
+	"llc":          true,
+	"llp":          true,
+	"lp":           true,
+	"ltd":          true,
+	"limited":      true,
+	"plc":          true,
+	"pllc":         true,
+	"pty":          true,
+
+	// German
+	"ag":   true,
+	"eg":   true,
+	"ev":   true,
+	"gmbh": true,
+	"kg":   true,
+	"kgaa": true,
+	"ohg":  true,
+	"ug":   true,
+
+	// French
+	"eurl": true,
+	"sa":   true,
+	"sarl": true,
+	"sas":  true,
+	"sasu": true,
+	"sca":  true,
+	"sci":  true,
+	"snc":  true,
+
+	// Italian, Spanish, Portuguese
+	"ltda": true,
+	"sapa": true,
+	"sau":  true,
+	"sl":   true,
+	"slu":  true,
+	"spa":  true,
+	"srl":  true,
+
+	// Dutch, Nordic, and others
+	"ab":    true,
+	"aps":   true,
+	"as":    true,
+	"asa":   true,
+	"bv":    true,
+	"kk":    true,
+	"nv":    true,
+	"oy":    true,
+	"oyj":   true,
+	"se":    true,
+	"spzoo": true,
+}
+
+// maxLegalFormWords is the most words that a single legal form can span
+// after normalization,
+// as in "S.à r.l." (normalized to {"s", "a", "r", "l"}).
+const maxLegalFormWords = 4
+
+// trailingLegalForm returns the number of words at the end of words
+// that together spell a legal form,
+// or zero if there is none.
+// At least one word is always left over.
+func trailingLegalForm(words []string) int {
+	for n := 1; n <= maxLegalFormWords && n < len(words); n++ {
+		if legalForms[strings.Join(words[len(words)-n:], "")] {
+			return n
+		}
+	}
+	return 0
+}
@@ -0,0 +1,222 @@
+package coalition
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// limiterSet holds the rate limiters for a Matcher's outbound web requests.
+// It is shared by all copies of the Matcher it belongs to,
+// so that a Matcher passed by value still honors a single set of limits.
+type limiterSet struct {
+	mu      sync.Mutex
+	global  *rate.Limiter
+	perHost map[string]*hostLimiter
+}
+
+// hostLimiter is the rate limiter for a single host.
+type hostLimiter struct {
+	*rate.Limiter
+
+	// Waiting is the number of waits in progress on the limiter,
+	// and last is when the most recent one ended.
+	waiting int
+	last    time.Time
+}
+
+// maxHostLimiters is the number of per-host limiters in a limiterSet
+// above which the idle ones are evicted
+// (see limiterSet.evictIdle).
+const maxHostLimiters = 1000
+
+// This is used by Matchers that were not produced by NewMatcher.
+var defaultLimiterSet = new(limiterSet)
+
+func (m Matcher) limiters() *limiterSet {
+	if m.limits != nil {
+		return m.limits
+	}
+	return defaultLimiterSet
+}
+
+// waitToFetch blocks until m's rate limits permit a request to host,
+// or until ctx is done, whichever comes first.
+// If the limits can't permit the request before ctx's deadline,
+// the error is errBudgetExceeded,
+// so the test waiting does not pass
+// (see Matcher.runTest).
+func (m Matcher) waitToFetch(ctx context.Context, host string) error {
+	limits := m.limiters()
+	global, perHost := limits.get(m.RequestsPerSecond, m.PerHostRequestsPerSecond, host)
+	if perHost != nil {
+		defer limits.release(perHost)
+	}
+	if global != nil {
+		if err := global.Wait(ctx); err != nil {
+			return limiterWaitErr(ctx, err)
+		}
+	}
+	if perHost != nil {
+		if err := perHost.Wait(ctx); err != nil {
+			return limiterWaitErr(ctx, err)
+		}
+	}
+	return nil
+}
+
+// This is the error to report for a failed wait on a rate limiter.
+// A wait fails without ctx being done
+// when it would outlast ctx's deadline.
+func limiterWaitErr(ctx context.Context, err error) error {
+	if ctx.Err() == nil {
+		return errBudgetExceeded
+	}
+	return err
+}
+
+// get returns the global limiter and the limiter for host,
+// creating or adjusting them as needed to match the given rates.
+// Either result is nil when the corresponding rate is not positive.
+// The caller must release a non-nil perHost when done waiting on it.
+func (s *limiterSet) get(globalRate, perHostRate float64, host string) (global *rate.Limiter, perHost *hostLimiter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if globalRate > 0 {
+		s.global = adjustLimiter(s.global, globalRate)
+		global = s.global
+	}
+	if perHostRate > 0 {
+		if s.perHost == nil {
+			s.perHost = make(map[string]*hostLimiter)
+		}
+		perHost = s.perHost[host]
+		if perHost == nil {
+			if len(s.perHost) >= maxHostLimiters {
+				s.evictIdle(perHostRate)
+			}
+			perHost = &hostLimiter{Limiter: adjustLimiter(nil, perHostRate)}
+			s.perHost[host] = perHost
+		} else {
+			adjustLimiter(perHost.Limiter, perHostRate)
+		}
+		perHost.waiting++
+	}
+	return global, perHost
+}
+
+// release records the end of a wait on h.
+func (s *limiterSet) release(h *hostLimiter) {
+	s.mu.Lock()
+	h.waiting--
+	h.last = time.Now()
+	s.mu.Unlock()
+}
+
+// evictIdle removes the per-host limiters that nobody is waiting on
+// and that have not been used for long enough,
+// at the given rate,
+// to permit another request at once.
+// Such a limiter is no different from a new one.
+// The caller must hold s.mu.
+func (s *limiterSet) evictIdle(r float64) {
+	interval := time.Duration(float64(time.Second) / r)
+	now := time.Now()
+	for host, h := range s.perHost {
+		if h.waiting == 0 && now.Sub(h.last) >= interval {
+			delete(s.perHost, host)
+		}
+	}
+}
+
+// adjustLimiter returns l with its limit set to r,
+// or a new limiter if l is nil.
+// Bursts are not permitted:
+// requests are spaced evenly at the given rate.
+func adjustLimiter(l *rate.Limiter, r float64) *rate.Limiter {
+	if l == nil {
+		return rate.NewLimiter(rate.Limit(r), 1)
+	}
+	if l.Limit() != rate.Limit(r) {
+		l.SetLimit(rate.Limit(r))
+	}
+	return l
+}
+
+// requestBudget counts the outbound requests that remain
+// for a single match
+// (see Matcher.MaxRequestsPerMatch).
+// A nil *requestBudget is unlimited.
+type requestBudget struct {
+	mu   sync.Mutex
+	left int
+
+	// HomePage tells whether the request for the home page has been reserved.
+	// It is shared by all the tests that examine the home page.
+	homePage bool
+}
+
+func newRequestBudget(max int) *requestBudget {
+	if max <= 0 {
+		return nil
+	}
+	return &requestBudget{left: max}
+}
+
+// reserve reserves the request that t needs
+// and reports whether there was one left.
+func (b *requestBudget) reserve(t testDef) bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if t.homePage && b.homePage {
+		return true
+	}
+	if b.left == 0 {
+		return false
+	}
+	b.left--
+	if t.homePage {
+		b.homePage = true
+	}
+	return true
+}
+
+// take takes a request from b,
+// beyond those reserved,
+// and reports whether there was one left.
+func (b *requestBudget) take() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.left == 0 {
+		return false
+	}
+	b.left--
+	return true
+}
+
+// nextPage readies b for the tests of another page,
+// which need a request of their own
+// (see Matcher.MatchURLs).
+func (b *requestBudget) nextPage() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.homePage = false
+}
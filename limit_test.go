@@ -0,0 +1,145 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	srv, domain := newTestServer("text/html", "<html><body>coalition</body></html>")
+	defer srv.Close()
+
+	cases := []struct {
+		name                                string
+		requestsPerSecond, perHostPerSecond float64
+		wantMin                             time.Duration
+	}{
+		{name: "unlimited"},
+		{name: "global", requestsPerSecond: 20, wantMin: 150 * time.Millisecond},
+		{name: "per_host", perHostPerSecond: 20, wantMin: 150 * time.Millisecond},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			matcher := NewMatcher()
+			matcher.RequestsPerSecond = c.requestsPerSecond
+			matcher.PerHostRequestsPerSecond = c.perHostPerSecond
+
+			// A burst of 4 requests at 20/sec must take at least 3/20 sec.
+			start := time.Now()
+			for i := 0; i < 4; i++ {
+				if _, err := matcher.doMatch(context.Background(), "Coalition", domain); err != nil {
+					t.Fatal(err)
+				}
+			}
+			elapsed := time.Since(start)
+
+			if elapsed < c.wantMin {
+				t.Errorf("burst took %s, want at least %s", elapsed, c.wantMin)
+			}
+		})
+	}
+}
+
+func TestMaxRequestsPerMatch(t *testing.T) {
+	srv, domain := newTestServer("text/html", `<html><head><script type="application/ld+json">{"@type": "Organization", "name": "Coalition"}</script></head><body>coalition</body></html>`)
+	defer srv.Close()
+
+	matcher := NewMatcher()
+	matcher.Scores[testJSONLDOrganization] = 20
+	matcher.Scores[testNewDomain] = -20
+	matcher.Whois = fakeWhois{hostname(domain): time.Now()}
+
+	cases := []struct {
+		max  int
+		want []string
+	}{
+		{
+			max: 1,
+			want: []string{
+				"WebPageRef passed (+50)",
+				"JSONLDOrganization passed (+20)", // shares the home page request
+				"NewDomain skipped because the request budget was exhausted",
+			},
+		},
+		{
+			max: 2,
+			want: []string{
+				"WebPageRef passed (+50)",
+				"JSONLDOrganization passed (+20)",
+				"NewDomain passed (-20)",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("max_%d", c.max), func(t *testing.T) {
+			matcher := matcher.Clone()
+			matcher.MaxRequestsPerMatch = c.max
+
+			detail, err := matcher.MatchDetail(context.Background(), "Coalition", domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := detail.Reasons()
+			got = got[len(got)-len(c.want):] // just the network tests
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitDeadline(t *testing.T) {
+	srv, domain := newTestServer("text/html", "<html><body>coalition</body></html>")
+	defer srv.Close()
+
+	matcher := NewMatcher()
+	matcher.PerHostRequestsPerSecond = 0.5
+	matcher.TestTimeouts = map[testType]time.Duration{testWebPageRef: 100 * time.Millisecond}
+
+	// The second match can't fetch the page before its test's deadline,
+	// so WebPageRef does not pass,
+	// but the match does not fail.
+	for i, want := range []bool{true, false} {
+		detail, err := matcher.MatchDetail(context.Background(), "Coalition", domain)
+		if err != nil {
+			t.Fatalf("match %d: %s", i, err)
+		}
+		for _, o := range detail.Outcomes {
+			if o.Test == "WebPageRef" && o.Passed != want {
+				t.Errorf("match %d: got WebPageRef passed %v, want %v", i, o.Passed, want)
+			}
+		}
+	}
+}
+
+func TestHostLimiterEviction(t *testing.T) {
+	var s limiterSet
+
+	for i := 0; i < 3*maxHostLimiters; i++ {
+		if i > 0 && i%maxHostLimiters == 0 {
+			time.Sleep(2 * time.Millisecond) // long enough for the earlier limiters to go idle
+		}
+		_, h := s.get(0, 1000, fmt.Sprintf("host%d.example", i))
+		s.release(h)
+	}
+	if n := len(s.perHost); n > maxHostLimiters {
+		t.Errorf("got %d per-host limiters, want at most %d", n, maxHostLimiters)
+	}
+
+	// A limiter being waited on is not evicted.
+	_, busy := s.get(0, 1000, "busy.example")
+	time.Sleep(2 * time.Millisecond)
+	for i := 0; i < maxHostLimiters; i++ {
+		_, h := s.get(0, 1000, fmt.Sprintf("other%d.example", i))
+		s.release(h)
+	}
+	if s.perHost["busy.example"] != busy {
+		t.Error("busy limiter was evicted")
+	}
+	s.release(busy)
+}
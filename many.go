@@ -0,0 +1,176 @@
+package coalition
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// MatchMany matches ref against each of domains in turn,
+// returning the scores in the same order as the domains.
+// See Matcher.MatchContext.
+func (m Matcher) MatchMany(ctx context.Context, ref string, domains []string) ([]float32, error) {
+	result := make([]float32, 0, len(domains))
+	for _, domain := range domains {
+		score, err := m.MatchContext(ctx, ref, domain)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, score)
+	}
+	return result, nil
+}
+
+// DomainScore is a domain and its score for some reference.
+// See Matcher.Rank.
+type DomainScore struct {
+	Domain string
+	Score  float32
+}
+
+// Rank matches ref against each of domains
+// (see MatchMany)
+// and returns them all with their scores,
+// best first.
+// Domains with equal scores keep their order in domains.
+func (m Matcher) Rank(ctx context.Context, ref string, domains []string) ([]DomainScore, error) {
+	scores, err := m.MatchMany(ctx, ref, domains)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]DomainScore, 0, len(domains))
+	for i, domain := range domains {
+		result = append(result, DomainScore{Domain: domain, Score: scores[i]})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+	return result, nil
+}
+
+// MatchAny matches domain against each of refs,
+// which are taken to be names of the same organization,
+// and combines the results into a single score
+// using m.Aggregate
+// (or, if that is nil, by taking the best of them).
+// It also returns the score for each of refs,
+// in the same order.
+// The domain's home page is fetched just once for all of refs.
+// See Matcher.MatchContext.
+func (m Matcher) MatchAny(ctx context.Context, refs []string, domain string) (float32, []float32, error) {
+	result, scores, err := m.matchAny(ctx, refs, domain)
+	m.metrics().Match(result, err)
+	return result, scores, err
+}
+
+func (m Matcher) matchAny(ctx context.Context, refs []string, domain string) (float32, []float32, error) {
+	if len(refs) == 0 {
+		return 0, nil, errors.New("no references")
+	}
+
+	var (
+		scores = make([]float32, 0, len(refs))
+		first  *matchInput
+	)
+	for _, ref := range refs {
+		in, err := m.newMatchInput(ref, domain)
+		if err != nil {
+			return 0, nil, err
+		}
+		if first == nil {
+			first = in
+		} else {
+			in.fetch = first.fetch
+			in.budget = first.budget
+		}
+		score, _, err := m.doMatchInput(ctx, in, domain)
+		if err != nil {
+			return 0, nil, err
+		}
+		scores = append(scores, m.scale(score))
+	}
+
+	aggregate := m.Aggregate
+	if aggregate == nil {
+		aggregate = MaxScore
+	}
+	return aggregate(scores), scores, nil
+}
+
+// MaxScore returns the largest of scores,
+// or 0 if there are none.
+// It is the default for Matcher.Aggregate.
+func MaxScore(scores []float32) float32 {
+	var result float32
+	for _, s := range scores {
+		if s > result {
+			result = s
+		}
+	}
+	return result
+}
+
+// MeanScore returns the mean of scores,
+// or 0 if there are none.
+func MeanScore(scores []float32) float32 {
+	if len(scores) == 0 {
+		return 0
+	}
+	var sum float32
+	for _, s := range scores {
+		sum += s
+	}
+	return sum / float32(len(scores))
+}
+
+// WeightedScore returns a function that computes the weighted mean of scores,
+// with the weight of each given by the corresponding element of weights.
+// A score with no corresponding weight has a weight of 1.
+// The function returns 0 if the weights total 0.
+// This is useful with Matcher.Aggregate and CompositeMatcher.Aggregate.
+func WeightedScore(weights ...float32) func(scores []float32) float32 {
+	return func(scores []float32) float32 {
+		var sum, total float32
+		for i, s := range scores {
+			w := float32(1)
+			if i < len(weights) {
+				w = weights[i]
+			}
+			sum += w * s
+			total += w
+		}
+		if total == 0 {
+			return 0
+		}
+		return sum / total
+	}
+}
+
+// DefaultTLDs is the list of top-level domains that MatchTLDVariants uses when none are given.
+var DefaultTLDs = []string{"com", "net", "org", "io", "co"}
+
+// MatchTLDVariants matches ref against registrableName under each of the given top-level domains,
+// e.g. "coalition.com", "coalition.io", etc. for registrableName "coalition".
+// If tlds is empty, DefaultTLDs is used.
+// The result maps each resulting domain to its score.
+func (m Matcher) MatchTLDVariants(ctx context.Context, ref, registrableName string, tlds []string) (map[string]float32, error) {
+	if len(tlds) == 0 {
+		tlds = DefaultTLDs
+	}
+	domains := make([]string, 0, len(tlds))
+	for _, tld := range tlds {
+		domains = append(domains, registrableName+"."+strings.TrimPrefix(tld, "."))
+	}
+
+	scores, err := m.MatchMany(ctx, ref, domains)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]float32)
+	for i, domain := range domains {
+		result[domain] = scores[i]
+	}
+	return result, nil
+}
@@ -0,0 +1,167 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMatchTLDVariants(t *testing.T) {
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+	t.Run("given", func(t *testing.T) {
+		got, err := matcher.MatchTLDVariants(context.Background(), "Coalition, Inc", "coalition", []string{"com", ".io", "insurance"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := matcher.Match("Coalition, Inc", "coalition.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 3 {
+			t.Errorf("got %d results, want 3", len(got))
+		}
+		for _, domain := range []string{"coalition.com", "coalition.io", "coalition.insurance"} {
+			if score, ok := got[domain]; !ok {
+				t.Errorf("no result for %s", domain)
+			} else if score != want {
+				t.Errorf("got %f for %s, want %f", score, domain, want)
+			}
+		}
+	})
+
+	t.Run("default", func(t *testing.T) {
+		got, err := matcher.MatchTLDVariants(context.Background(), "Coalition, Inc", "colition", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(DefaultTLDs) {
+			t.Errorf("got %d results, want %d", len(got), len(DefaultTLDs))
+		}
+		for _, tld := range DefaultTLDs {
+			if _, ok := got["colition."+tld]; !ok {
+				t.Errorf("no result for colition.%s", tld)
+			}
+		}
+	})
+}
+
+func TestMatchAny(t *testing.T) {
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+	refs := []string{"Acme Widgets", "Coalition, Inc", "Rutabaga Partners"}
+
+	var want []float32
+	for _, ref := range refs {
+		score, err := matcher.Match(ref, "coalitioninc.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, score)
+	}
+	if want[1] <= want[0] || want[1] <= want[2] {
+		t.Fatalf("got scores %v, want the second to be the best", want)
+	}
+
+	got, perRef, err := matcher.MatchAny(context.Background(), refs, "coalitioninc.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want[1] {
+		t.Errorf("got aggregate %v, want %v", got, want[1])
+	}
+	if !reflect.DeepEqual(perRef, want) {
+		t.Errorf("got per-reference scores %v, want %v", perRef, want)
+	}
+
+	t.Run("aggregate", func(t *testing.T) {
+		matcher := matcher.Clone()
+		matcher.Aggregate = func(scores []float32) float32 {
+			var sum float32
+			for _, s := range scores {
+				sum += s
+			}
+			return sum / float32(len(scores))
+		}
+		got, _, err := matcher.MatchAny(context.Background(), refs, "coalitioninc.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wantMean := (want[0] + want[1] + want[2]) / 3; got != wantMean {
+			t.Errorf("got %v, want %v", got, wantMean)
+		}
+	})
+
+	t.Run("one_fetch", func(t *testing.T) {
+		var fetches int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			atomic.AddInt32(&fetches, 1)
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, "<html><body>coalition</body></html>")
+		}))
+		defer srv.Close()
+
+		matcher := NewMatcher()
+		got, perRef, err := matcher.MatchAny(context.Background(), []string{"Acme", "Coalition"}, strings.TrimPrefix(srv.URL, "http://"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perRef[0] >= perRef[1] || got != perRef[1] {
+			t.Errorf("got %v and %v, want the second reference to be the best", got, perRef)
+		}
+		if n := atomic.LoadInt32(&fetches); n != 1 {
+			t.Errorf("got %d fetches, want 1", n)
+		}
+	})
+}
+
+func TestRank(t *testing.T) {
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+	domains := []string{
+		"xyzzy.com",
+		"coalition-rutabaga.com",
+		"coalition.io",
+		"plugh.net",
+		"coalitioninc.com",
+		"colition.com",
+	}
+	got, err := matcher.Rank(context.Background(), "Coalition, Inc", domains)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ties (coalition.io and coalitioninc.com; xyzzy.com and plugh.net)
+	// keep their input order.
+	wantDomains := []string{
+		"coalition.io",
+		"coalitioninc.com",
+		"coalition-rutabaga.com",
+		"colition.com",
+		"xyzzy.com",
+		"plugh.net",
+	}
+	var gotDomains []string
+	for _, ds := range got {
+		gotDomains = append(gotDomains, ds.Domain)
+
+		want, err := matcher.Match("Coalition, Inc", ds.Domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ds.Score != want {
+			t.Errorf("got %v for %s, want %v", ds.Score, ds.Domain, want)
+		}
+	}
+	if !reflect.DeepEqual(gotDomains, wantDomains) {
+		t.Errorf("got %v, want %v", gotDomains, wantDomains)
+	}
+}
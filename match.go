@@ -2,16 +2,21 @@ package coalition
 
 import (
 	"context"
-	"mime"
+	"errors"
+	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"net/http/cookiejar"
+	"net/url"
 	"regexp"
 	"strings"
 	"time"
-	"unicode"
+	"unicode/utf8"
 
 	"github.com/agnivade/levenshtein"
-	"github.com/bobg/htree"
-	"golang.org/x/net/html"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/text/language"
 )
 
 // MatchDomain matches ref,
@@ -31,7 +36,8 @@ const (
 	// RootPhrase tests whether the normalized root phrase of the input appears in the domain name.
 	testRootPhrase
 
-	// AnyRootWord tests whether any word of the normalized root phrase of the input appears in the domain name.
+	// AnyRootWord tests whether any word of the normalized root phrase of the input appears in the domain name,
+	// apart from common words (see Matcher.CommonWords).
 	// Only runs when RootPhrase does not pass.
 	testAnyRootWord
 
@@ -45,6 +51,276 @@ const (
 
 	// WebPageRef tests whether the normalized root phrase of the input appears on the home page for the domain.
 	testWebPageRef
+
+	// JSONLDOrganization tests whether the normalized root phrase of the input appears
+	// in the name or legal name of a schema.org Organization
+	// described by JSON-LD data on the home page for the domain.
+	// Off by default.
+	testJSONLDOrganization
+
+	// NewDomain tests whether the domain was registered recently,
+	// according to the Matcher's WhoisProvider
+	// (see Matcher.Whois, Matcher.NewDomainAge, and Matcher.NewDomainWeights).
+	// Newly registered domains are more likely to be typosquats.
+	// This is a negative test: passing subtracts from the overall score.
+	// Off by default.
+	testNewDomain
+
+	// Hyphenated tests whether the root phrase appears in a domain label
+	// only when hyphens are disregarded,
+	// or together with hyphens,
+	// when the input itself contains no hyphens
+	// (as in "coali-tion.com" or "coalition-inc.com" for "Coalition, Inc").
+	// Official domains seldom add hyphens that the organization's name lacks.
+	// This is a negative test: passing subtracts from the overall score.
+	// Off by default.
+	testHyphenated
+
+	// CanonicalHost tests whether the host in the <link rel="canonical"> element
+	// of the domain's home page passes the string tests
+	// (RootPhrase, AnyRootWord, etc.),
+	// i.e. whether their total score for that host is positive.
+	// This corroborates a domain that is a mirror or CDN for the organization's primary domain.
+	// A canonical host on the domain's own site
+	// (with the same registrable domain)
+	// does not pass,
+	// since the string tests have already examined it.
+	// Off by default.
+	testCanonicalHost
+
+	// NegativeKeyword tests whether the domain contains a word,
+	// apart from the root phrase,
+	// that disqualifies it as belonging to the organization,
+	// like "sucks" or "unofficial"
+	// (see Matcher.NegativeKeywords).
+	// This is a negative test: passing subtracts from the overall score.
+	// Off by default.
+	testNegativeKeyword
+
+	// WebPageShortName tests whether the first significant word of the normalized root phrase,
+	// as a whole word,
+	// appears on the home page for the domain,
+	// for pages that use a shortened form of the organization's name
+	// (e.g. "Coalition" for "Coalition Insurance Solutions, Inc.").
+	// It runs only when the root phrase has more than one word
+	// and WebPageRef does not pass.
+	// Its score should be lower than WebPageRef's.
+	// Off by default.
+	testWebPageShortName
+
+	// BrandAsset tests whether the root phrase appears
+	// in the filename of an icon linked from the domain's home page
+	// (<link rel="icon"> and similar)
+	// or in the alt text of an image on that page
+	// (as in <img alt="Coalition logo">).
+	// This is a weak signal, so its score should be small.
+	// Off by default.
+	testBrandAsset
+
+	// LeadWord tests whether the first significant word of the normalized root phrase
+	// begins a token of the domain
+	// (as with "coalition-foo.com" for "Coalition Security",
+	// but not "security-foo.com").
+	// It is stricter than AnyRootWord and looser than RootPhrase.
+	// It runs only when RootPhrase does not pass.
+	// Off by default.
+	testLeadWord
+
+	// ResponseHeader tests whether the root phrase appears
+	// in selected headers of the HTTP response for the domain's home page
+	// (see Matcher.ResponseHeaders),
+	// such as Server.
+	// It works for pages of any content type.
+	// This is a weak signal, so its score should be small.
+	// Off by default.
+	testResponseHeader
+
+	// External consults Matcher.External,
+	// an application-supplied scorer such as a machine-learned model.
+	// Unlike the other tests it can pass partially:
+	// it earns the fraction of its score given by the scorer's result.
+	// Its score may be negative,
+	// for a scorer that measures the likelihood of impersonation, for instance.
+	// Off by default,
+	// and does nothing when Matcher.External is nil.
+	testExternal
+
+	// Copyright tests whether the root phrase appears in the organization name
+	// of a copyright notice on the domain's home page
+	// (as in "© 2024 Coalition, Inc. All rights reserved.").
+	// This is more precise than WebPageRef,
+	// since the owner of a site usually names itself there.
+	// Off by default.
+	testCopyright
+
+	// SecurityTxt tests whether the root phrase appears in the domain's security.txt file
+	// (see RFC 9116),
+	// which often names the organization responsible for the site.
+	// It is a short, low-noise document,
+	// so this is precise corroboration,
+	// but one that few sites offer;
+	// a small score is appropriate.
+	// Off by default.
+	testSecurityTxt
+
+	// PageLanguage tests whether the domain's home page declares
+	// the language in Matcher.ExpectedPageLanguage.
+	// It is weak corroboration
+	// for a suspected regional domain.
+	// Off by default.
+	testPageLanguage
+
+	// PageLanguageMismatch tests whether the domain's home page declares
+	// a language other than Matcher.ExpectedPageLanguage.
+	// It should have a negative score.
+	// Off by default.
+	testPageLanguageMismatch
+
+	// SelfLinkedRef tests whether the root phrase appears on the domain's home page
+	// in or next to a link to the same site,
+	// as in a logo or a footer that links home.
+	// That is stronger evidence than an incidental mention,
+	// so it can add to the score for WebPageRef.
+	// Off by default.
+	testSelfLinkedRef
+
+	// ArchivedPageRef tests whether the root phrase appears on an archived copy
+	// of the domain's home page,
+	// as supplied by Matcher.Archive.
+	// This helps with a domain that belonged to the organization
+	// but is now parked or redirects elsewhere.
+	// Off by default.
+	testArchivedPageRef
+)
+
+// testNames gives the name of each test,
+// as used in configuration files
+// (see MatcherConfig).
+var testNames = map[testType]string{
+	testRootPhrase:           string(TestRootPhrase),
+	testAnyRootWord:          string(TestAnyRootWord),
+	testMisspelledRootPhrase: string(TestMisspelledRootPhrase),
+	testSignificantAffixes:   string(TestSignificantAffixes),
+	testWebPageRef:           string(TestWebPageRef),
+	testJSONLDOrganization:   string(TestJSONLDOrganization),
+	testNewDomain:            string(TestNewDomain),
+	testHyphenated:           string(TestHyphenated),
+	testCanonicalHost:        string(TestCanonicalHost),
+	testNegativeKeyword:      string(TestNegativeKeyword),
+	testWebPageShortName:     string(TestWebPageShortName),
+	testBrandAsset:           string(TestBrandAsset),
+	testLeadWord:             string(TestLeadWord),
+	testResponseHeader:       string(TestResponseHeader),
+	testExternal:             string(TestExternal),
+	testCopyright:            string(TestCopyright),
+	testSecurityTxt:          string(TestSecurityTxt),
+	testPageLanguage:         string(TestPageLanguage),
+	testPageLanguageMismatch: string(TestPageLanguageMismatch),
+	testSelfLinkedRef:        string(TestSelfLinkedRef),
+	testArchivedPageRef:      string(TestArchivedPageRef),
+}
+
+// This returns the test with the given name.
+func testByName(name string) (testType, bool) {
+	for t, n := range testNames {
+		if n == name {
+			return t, true
+		}
+	}
+	return testNone, false
+}
+
+// TestName is the name of one of a Matcher's tests,
+// as used by WithScore and WithoutTest,
+// and in configuration files
+// (see MatcherConfig).
+type TestName string
+
+// These are the names of the tests a Matcher can run.
+const (
+	// TestRootPhrase tests whether the normalized root phrase of the input appears in the domain name.
+	TestRootPhrase TestName = "RootPhrase"
+
+	// TestAnyRootWord tests whether any word of the root phrase appears in the domain name.
+	TestAnyRootWord TestName = "AnyRootWord"
+
+	// TestMisspelledRootPhrase tests whether the root phrase appears in misspelled form in the domain name.
+	TestMisspelledRootPhrase TestName = "MisspelledRootPhrase"
+
+	// TestSignificantAffixes tests whether non-ignorable affixes surround the root phrase in the domain name.
+	// It should have a negative score.
+	TestSignificantAffixes TestName = "SignificantAffixes"
+
+	// TestWebPageRef tests whether the root phrase appears on the domain's home page.
+	TestWebPageRef TestName = "WebPageRef"
+
+	// TestJSONLDOrganization tests whether the root phrase names a schema.org Organization
+	// in JSON-LD data on the domain's home page.
+	TestJSONLDOrganization TestName = "JSONLDOrganization"
+
+	// TestNewDomain tests whether the domain was registered recently
+	// (see Matcher.Whois).
+	// It should have a negative score.
+	TestNewDomain TestName = "NewDomain"
+
+	// TestHyphenated tests whether the root phrase appears in the domain name only with added hyphens.
+	// It should have a negative score.
+	TestHyphenated TestName = "Hyphenated"
+
+	// TestCanonicalHost tests whether the canonical host named on the domain's home page
+	// passes the string tests.
+	TestCanonicalHost TestName = "CanonicalHost"
+
+	// TestNegativeKeyword tests whether the domain name contains a disqualifying word
+	// (see Matcher.NegativeKeywords).
+	// It should have a negative score.
+	TestNegativeKeyword TestName = "NegativeKeyword"
+
+	// TestWebPageShortName tests whether the first significant word of the root phrase
+	// appears on the domain's home page.
+	TestWebPageShortName TestName = "WebPageShortName"
+
+	// TestBrandAsset tests whether the root phrase appears in icon filenames or image alt text
+	// on the domain's home page.
+	TestBrandAsset TestName = "BrandAsset"
+
+	// TestLeadWord tests whether the first significant word of the root phrase
+	// begins a token of the domain name.
+	TestLeadWord TestName = "LeadWord"
+
+	// TestResponseHeader tests whether the root phrase appears in selected headers
+	// of the response for the domain's home page
+	// (see Matcher.ResponseHeaders).
+	TestResponseHeader TestName = "ResponseHeader"
+
+	// TestExternal consults the application-supplied scorer in Matcher.External.
+	TestExternal TestName = "External"
+
+	// TestCopyright tests whether the root phrase appears in a copyright notice
+	// on the domain's home page.
+	TestCopyright TestName = "Copyright"
+
+	// TestSecurityTxt tests whether the root phrase appears
+	// in the domain's /.well-known/security.txt file.
+	TestSecurityTxt TestName = "SecurityTxt"
+
+	// TestPageLanguage tests whether the domain's home page declares
+	// the language in Matcher.ExpectedPageLanguage.
+	TestPageLanguage TestName = "PageLanguage"
+
+	// TestPageLanguageMismatch tests whether the domain's home page declares
+	// a language other than Matcher.ExpectedPageLanguage.
+	// It should have a negative score.
+	TestPageLanguageMismatch TestName = "PageLanguageMismatch"
+
+	// TestSelfLinkedRef tests whether the root phrase appears on the domain's home page
+	// in or next to a link to the same site.
+	TestSelfLinkedRef TestName = "SelfLinkedRef"
+
+	// TestArchivedPageRef tests whether the root phrase appears
+	// on an archived copy of the domain's home page
+	// (see Matcher.Archive).
+	TestArchivedPageRef TestName = "ArchivedPageRef"
 )
 
 // Matcher is a configuration object for performing matches.
@@ -53,8 +329,560 @@ const (
 type Matcher struct {
 	Scores map[testType]int
 	Stop   Stopper
+
+	// RequestsPerSecond, if positive,
+	// limits the rate of outbound web requests made by the Matcher,
+	// across all hosts.
+	RequestsPerSecond float64
+
+	// PerHostRequestsPerSecond, if positive,
+	// limits the rate of outbound web requests made by the Matcher
+	// to any single host.
+	PerHostRequestsPerSecond float64
+
+	// TestTimeouts gives the time budget for each network test.
+	// A network test with no entry here gets a default budget of 5 seconds.
+	// A test that exceeds its budget does not pass,
+	// but does not prevent the remaining tests from running.
+	// All tests are also bounded by the context passed to MatchContext.
+	TestTimeouts map[testType]time.Duration
+
+	// Client is the HTTP client for the web tests.
+	// If nil, a client with the settings described at DefaultWebTimeout is used.
+	Client *http.Client
+
+	// WebTimeout is the overall timeout for each web request
+	// when Client is nil.
+	// If zero, DefaultWebTimeout is used.
+	WebTimeout time.Duration
+
+	// ProxyProvider, if non-nil,
+	// chooses the proxy for each web request
+	// when Client is nil,
+	// in place of the environment (HTTP_PROXY etc.).
+	// RoundRobinProxies spreads requests among a pool of proxies.
+	// A custom Client must be given its own proxy settings.
+	ProxyProvider ProxyProvider
+
+	// ConnectAddresses, if non-empty,
+	// maps host names to the addresses to connect to in their place
+	// when Client is nil,
+	// as for hosts behind a known load balancer,
+	// or for testing.
+	// A key is a host name,
+	// or a host name and port ("coalition.com:443"),
+	// and a value is an IP address or host name,
+	// with or without a port
+	// (which defaults to that of the original address).
+	// The Host header and the TLS server name (SNI)
+	// still name the host in the URL,
+	// so virtual hosts are served correctly.
+	ConnectAddresses map[string]string
+
+	// Metrics, if non-nil,
+	// is told about the matches and web fetches the Matcher performs.
+	// It may be shared with other Matchers.
+	// See Counters for a simple implementation.
+	Metrics Metrics
+
+	// PageCache, if non-nil,
+	// holds web pages for reuse,
+	// so that they are not fetched again.
+	// It may be shared with other Matchers.
+	// Pages from the cache do not count against the rate limits
+	// (but do count against MaxRequestsPerMatch).
+	// Only successful responses of moderate size are cached,
+	// separately for each address (see ConnectAddresses)
+	// and proxy (see ProxyProvider) they were fetched through.
+	PageCache PageCache
+
+	// MaxMetaRefreshes is the number of <meta http-equiv="refresh"> redirects
+	// that the web tests follow, at most,
+	// when fetching a home page.
+	// Only redirects within the same site
+	// (the same registrable domain, e.g. "example.com" for "www.example.com")
+	// are followed.
+	// Ordinary HTTP redirects are governed by the Client instead.
+	// If zero, DefaultMaxMetaRefreshes is used.
+	// If negative, none are followed.
+	MaxMetaRefreshes int
+
+	// PageRegions, if non-empty,
+	// limits the WebPageRef and WebPageShortName tests
+	// to the text of the given regions of a home page,
+	// e.g. IdentityRegions.
+	// If empty, the whole page is used.
+	PageRegions []PageRegion
+
+	// ContentTypes tells how the web tests treat home pages of different media types,
+	// like "text/html".
+	// Pages of types not listed are ignored.
+	// If nil, DefaultContentTypes is used.
+	ContentTypes map[string]ContentKind
+
+	// DocumentExtractor, if non-nil,
+	// extracts the text of home pages whose media types are mapped to ContentDocument
+	// in ContentTypes,
+	// such as PDFs.
+	DocumentExtractor DocumentExtractor
+
+	// ResponseHeaders are the names of the HTTP response headers
+	// that the ResponseHeader test inspects.
+	// If nil, DefaultResponseHeaders is used.
+	ResponseHeaders []string
+
+	// MaxRequestsPerMatch, if positive,
+	// is the most outbound requests
+	// (web fetches, WHOIS lookups, and calls to External)
+	// that a single match may make,
+	// across all network tests.
+	// Network tests are granted requests in the order the Matcher defines its tests;
+	// those that can't be are skipped, contributing nothing.
+	// The tests that examine the home page share a single request.
+	// Additional requests (see MaxMetaRefreshes) are made only if the budget allows.
+	MaxRequestsPerMatch int
+
+	// Whois, if non-nil, supplies domain registration data for the NewDomain test.
+	Whois WhoisProvider
+
+	// MaxMisspellingComparisons caps the number of substring comparisons
+	// made by the MisspelledRootPhrase test,
+	// so that a single pathologically long domain can't dominate the CPU.
+	// If the cap is reached,
+	// the test does not pass.
+	// If zero, DefaultMaxMisspellingComparisons is used.
+	// If negative, there is no cap.
+	MaxMisspellingComparisons int
+
+	// MisspellingFixedPrefix, if positive,
+	// is the number of letters at the start of the root phrase
+	// that the MisspelledRootPhrase test requires to be unaltered.
+	// A brand's distinctive first letters are seldom changed in legitimate variants,
+	// so e.g. with a value of 1,
+	// "coallition.com" passes for "Coalition"
+	// but "xoalition.com" does not.
+	MisspellingFixedPrefix int
+
+	// ScaleMisspellings, if true,
+	// makes the MisspelledRootPhrase test earn only part of its score,
+	// in proportion to the similarity of the closest misspelling in the domain
+	// to the root phrase:
+	// 1 - distance/length,
+	// where distance is the Levenshtein edit distance (1 or 2)
+	// and length is the number of letters in the root phrase.
+	// So a single typo in a long name earns more than two in a short one.
+	// If false, any misspelling earns the full score.
+	ScaleMisspellings bool
+
+	// MaxMisspellingCandidates caps the number of variants
+	// returned by MisspellingCandidates,
+	// which grows quickly with the length of the root phrase.
+	// If zero, DefaultMaxMisspellingCandidates is used.
+	// If negative, there is no cap.
+	MaxMisspellingCandidates int
+
+	// SubdomainPolicy says whether the string tests
+	// examine the labels of the domain below its registrable part
+	// (as in "coalition" in "coalition.example.com").
+	// The default is SubdomainInclude.
+	SubdomainPolicy SubdomainPolicy
+
+	// SubdomainWeight is the fraction of their scores
+	// that the RootPhrase, AnyRootWord, and MisspelledRootPhrase tests earn
+	// when they pass only because of a subdomain label,
+	// under SubdomainIncludeButPenalize.
+	// If zero, DefaultSubdomainWeight is used.
+	SubdomainWeight float32
+
+	// PublicSuffixList, if non-nil,
+	// decides the registrable part of a domain
+	// (see SubdomainPolicy),
+	// and whether a meta-refresh target is on the same site as the home page
+	// (see MaxMetaRefreshes).
+	// Supply one to recognize newer suffixes than this package knows,
+	// or to treat private suffixes
+	// (like "github.io", where every subdomain belongs to a different owner)
+	// differently.
+	// If nil, publicsuffix.List is used,
+	// which is compiled into the program
+	// and includes such private suffixes.
+	PublicSuffixList cookiejar.PublicSuffixList
+
+	// DomainTokenizer, if non-nil,
+	// splits domain labels into words for the AnyRootWord and SignificantAffixes tests.
+	// If nil, labels are split on the characters in Separators.
+	DomainTokenizer DomainTokenizer
+
+	// Separators are the characters that separate words in a domain label,
+	// as in "coalition-security" or "coalition_security",
+	// when DomainTokenizer is nil.
+	// If empty, DefaultSeparators is used.
+	Separators string
+
+	// Collapse maps punctuation in reference strings to replacements,
+	// applied during normalization before the reference is split into words.
+	// A replacement of "" joins the surrounding letters into one word;
+	// a replacement of " " separates them.
+	// (Other punctuation separates words in any case.)
+	// The default, when Collapse is nil,
+	// collapses apostrophes,
+	// so "Tom's" is one word, "toms".
+	// An empty map collapses nothing.
+	Collapse map[string]string
+
+	// Connectors says how normalization treats "&", "+",
+	// and a standalone "n" between words,
+	// as in "AT&T", "R+Co", and "Chip n Dale".
+	// The default, ConnectorsSeparate,
+	// treats them like other punctuation and words.
+	Connectors ConnectorPolicy
+
+	// Language is the language of reference strings,
+	// for language-specific case mapping during normalization
+	// (see WithLanguage).
+	// The zero value, language.Und,
+	// means language-neutral Unicode case folding.
+	Language language.Tag
+
+	// CompatibilityFolding tells whether to map full-width forms and ligatures
+	// in reference strings and domains
+	// to their plain equivalents,
+	// as in "Ｃｏａｌｉｔｉｏｎ" to "Coalition" and "ﬁ" to "fi"
+	// (see WithCompatibilityFolding).
+	CompatibilityFolding bool
+
+	// ExpectedPageLanguage is the language
+	// that the PageLanguage and PageLanguageMismatch tests
+	// expect the domain's home page to declare,
+	// as in its <html lang="..."> attribute.
+	// Only the base languages are compared,
+	// so "de-CH" and "de" are the same.
+	// If it is language.Und,
+	// neither test passes.
+	ExpectedPageLanguage language.Tag
+
+	// MaxLetterRun, if positive,
+	// is the length to which runs of the same letter are shortened,
+	// in both reference strings and domains,
+	// before the string tests
+	// (see WithMaxLetterRun).
+	// With a value of 2,
+	// "Coooool Co" becomes "cool co"
+	// and "cooalition.com" still differs from "coalition.com",
+	// a typo for MisspelledRootPhrase to catch;
+	// with 1,
+	// "coaliiition.com" becomes "coalition.com".
+	// The default is to leave runs alone.
+	MaxLetterRun int
+
+	// RefNoise are regular expressions matching parts of reference strings
+	// to remove before normalization
+	// (see WithNoise),
+	// such as the jurisdiction and file number in
+	// "COALITION, INC. (Delaware, File #1234567)".
+	// If nil, DefaultRefNoise is used.
+	// To remove nothing, use an empty, non-nil slice.
+	RefNoise []*regexp.Regexp
+
+	// LegalForms tells whether to disregard legal forms of organization,
+	// in several languages,
+	// at the end of reference strings and in domain affixes,
+	// as with stop words.
+	// This lets "Deutsche Bank AG" match deutschebank.com,
+	// and "Coalition" match coalition-gmbh.de.
+	// The legal forms include "Inc", "Ltd", "GmbH", "AG", "SA", "SARL", "BV", and many others.
+	LegalForms bool
+
+	// GeoTerms, if non-nil,
+	// reports geographic terms to disregard
+	// at the end of reference strings and in domain affixes,
+	// as with LegalForms.
+	// This lets "Coalition USA" and "Coalition International" match coalition.com,
+	// and "Coalition" match coalition-europe.com.
+	// DefaultGeoTerms is a suitable value.
+	// The default is nil,
+	// since some brands include such a term
+	// (as in "Air Canada").
+	GeoTerms Stopper
+
+	// RegionAffixes, if non-nil,
+	// reports country codes and region tokens
+	// that the SignificantAffixes test ignores in domain labels,
+	// as with stop words,
+	// since official regional domains often include them
+	// (as in "coalition-us.com" and "coalitionuk.com").
+	// Unlike stop words,
+	// one must make up the whole of an affix
+	// (or of one of its tokens; see DomainTokenizer),
+	// so "coalitionusde.com" is still penalized.
+	// NewMatcher sets it to DefaultRegionAffixes.
+	RegionAffixes Stopper
+
+	// MinAffixLength is the length,
+	// in characters,
+	// below which the SignificantAffixes test disregards an affix
+	// (or one of its tokens; see DomainTokenizer)
+	// as noise rather than a real word,
+	// as with the "x" in "coalitionx.com".
+	// If zero, DefaultMinAffixLength is used.
+	// If negative, affixes of any length count.
+	MinAffixLength int
+
+	// WordBoundaryLength is the length,
+	// in characters,
+	// up to which the root phrase
+	// (for the RootPhrase test)
+	// or a root word
+	// (for AnyRootWord)
+	// must align with word boundaries in the domain,
+	// instead of appearing anywhere in it,
+	// so that "Visa" matches visa.com and visacard.com
+	// but not visage.com.
+	// Nothing but stop words may adjoin it in a domain token
+	// (see DomainTokenizer),
+	// except text of three or more characters,
+	// which is taken to be another word.
+	// If zero, DefaultWordBoundaryLength is used.
+	// If negative, plain substring matching is used at any length.
+	WordBoundaryLength int
+
+	// TrailingNumbers says how a number at the end of the reference string,
+	// or after the root phrase in a domain label,
+	// is treated,
+	// as in "Coalition 2.0" and "coalition247.com".
+	// The default, NumbersIgnore,
+	// drops it from the reference string
+	// and doesn't penalize it in a domain.
+	TrailingNumbers NumberPolicy
+
+	// TLDWeights maps top-level domains to multipliers
+	// for the raw score of a domain under that TLD,
+	// reflecting that (e.g.) a match on a restricted gTLD like ".bank"
+	// is stronger evidence than the same match on ".xyz".
+	// The keys have no leading dot.
+	// A key may also be a multi-label suffix like "co.uk",
+	// in which case the longest matching suffix applies.
+	// (The string tests themselves see the whole domain,
+	// including its public suffix.)
+	// Domains with no matching entry are unweighted, as if by 1.0.
+	// The final score is clamped to [0.0..1.0].
+	TLDWeights map[string]float32
+
+	// CommonWords are generic words,
+	// like "solutions" and "group",
+	// that do not pass the AnyRootWord test on their own,
+	// since they appear in the names of too many unrelated organizations.
+	// If nil, there are none.
+	// See DefaultCommonWords and ReadCommonWords.
+	CommonWords map[string]bool
+
+	// NegativeKeywords is the set of words that the NegativeKeyword test looks for.
+	// They must be whole words of the domain,
+	// alone or run together with each other or with stop words
+	// (as in "thereviews"),
+	// so "review" is not found in "preview".
+	// If nil, DefaultNegativeKeywords is used.
+	NegativeKeywords map[string]bool
+
+	// AmbiguousBand, if non-nil,
+	// makes the network tests with positive scores,
+	// such as WebPageRef,
+	// run only when the string tests are inconclusive:
+	// when their total score
+	// (before any TLD weight)
+	// is within the band.
+	// Otherwise the string tests decide the match by themselves,
+	// saving the time of the network requests.
+	AmbiguousBand *ScoreBand
+
+	// RootPhraseTiers gives the fraction of its score that the RootPhrase test earns
+	// for an exact label match (as in coalition.com),
+	// a whole-word match (coalition-group.com),
+	// and any other match (getcoalitionnow.com).
+	// If zero, DefaultRootPhraseTiers is used,
+	// so every tier earns the full score.
+	RootPhraseTiers RootPhraseTiers
+
+	// Thresholds are the score thresholds used by Classify.
+	// If zero, DefaultConfidenceThresholds is used.
+	Thresholds ConfidenceThresholds
+
+	// Official, if non-nil,
+	// supplies domains known to be official for organizations.
+	// A domain official for the reference
+	// or for any of its aliases (see Aliases)
+	// gets OfficialBoost added to its score,
+	// or, if OfficialBoost is zero,
+	// the maximum result (1.0) without running any tests.
+	Official OfficialDomainProvider
+
+	// OfficialBoost is the amount added to the score of an official domain
+	// (see Official),
+	// after any TLD weight.
+	// If zero, official domains get the maximum result.
+	OfficialBoost int
+
+	// External, if non-nil,
+	// is an application-supplied scorer
+	// that the External test consults.
+	// Give that test a score in Scores to enable it.
+	External ExternalScorer
+
+	// Archive, if non-nil,
+	// supplies archived copies of web pages
+	// for the ArchivedPageRef test,
+	// as of ArchiveTime.
+	// Give that test a score in Scores to enable it.
+	Archive ArchiveProvider
+
+	// ArchiveTime is the time for which the ArchivedPageRef test requests
+	// an archived copy of the home page.
+	// The zero value means the most recent copy.
+	ArchiveTime time.Time
+
+	// Aggregate, if non-nil,
+	// combines the scores that MatchAny gets for the names of an organization
+	// into one.
+	// If nil, MaxScore is used.
+	Aggregate func(scores []float32) float32
+
+	// Calibration, if non-nil,
+	// maps a raw score
+	// (see Score)
+	// to the normalized result in [0.0..1.0],
+	// given the minimum and maximum possible raw scores under Scores.
+	// This allows e.g. a sigmoid-like curve fitted to known outcomes.
+	// The raw score may fall outside [min..max]
+	// (see TLDWeights),
+	// and a result outside [0.0..1.0] is clamped.
+	// The function should be nondecreasing in raw,
+	// so a higher raw score never yields a lower result;
+	// that isn't checked,
+	// but MatchAtLeast assumes it.
+	// If nil, LinearScale is used.
+	Calibration func(raw, min, max int) float32
+
+	// When the domain is an IP address,
+	// like "192.0.2.1" or "2001:db8::1",
+	// the string tests are skipped,
+	// since it can't contain the organization's name.
+	// The network tests (such as WebPageRef) still run,
+	// since a site served from one might still name the organization,
+	// unless SkipIPNetworkTests is true.
+	SkipIPNetworkTests bool
+
+	// Aliases, if non-nil,
+	// supplies other names for the organization in a reference.
+	// The domain is matched against each of them as well as the reference,
+	// and the best score is used.
+	Aliases AliasProvider
+
+	// NewDomainAge is the age below which the NewDomain test considers a domain newly registered.
+	// If zero, DefaultNewDomainAge is used.
+	// It is ignored if NewDomainWeights is set.
+	NewDomainAge time.Duration
+
+	// NewDomainWeights, if non-empty,
+	// grades the NewDomain test by the age of the domain,
+	// in place of NewDomainAge:
+	// a domain earns the Weight of the first entry whose MaxAge it is younger than,
+	// as a fraction of the test's (negative) score,
+	// so the entries should be in increasing order of MaxAge.
+	// A domain older than all of them does not pass.
+	// E.g. {{7 * 24 * time.Hour, 1}, {30 * 24 * time.Hour, 0.5}, {90 * 24 * time.Hour, 0.25}}
+	// applies the whole penalty to a domain registered within the past week,
+	// half of it within the past month,
+	// and a quarter within the past three months.
+	NewDomainWeights []DomainAgeWeight
+
+	// Now, if non-nil,
+	// supplies the current time
+	// for the tests that depend on it,
+	// like NewDomain.
+	// Tests of the Matcher can freeze it this way.
+	// If nil, time.Now is used.
+	Now func() time.Time
+
+	// MinPassingTests, if positive,
+	// is the number of tests with positive scores that must pass
+	// for a match to count at all,
+	// so that a single heavily weighted test can't carry it.
+	// With fewer,
+	// the raw score is the lowest possible
+	// (see Score),
+	// making the result 0,
+	// whatever the scores of the tests that did pass
+	// (see Detail.TooFewPassed).
+	// The network tests then always run,
+	// even when the string tests would otherwise decide the match
+	// (see AmbiguousBand and MatchAtLeast).
+	MinPassingTests int
+
+	// StreamPageText tells whether to read an HTML home page without parsing it,
+	// for lower memory use on large pages.
+	// The page is still read into memory in full,
+	// since the tests examining it share a single fetch,
+	// but it is kept as bytes
+	// rather than as a parse tree,
+	// which is usually several times larger.
+	// WebPageRef then matches the page's text
+	// as it is extracted from those bytes token by token,
+	// stopping at the first match,
+	// but missing any match spanning more than a few kilobytes of text.
+	// The page is still parsed if a test needs its structure
+	// (such as JSONLDOrganization),
+	// or if PageRegions is set.
+	StreamPageText bool
+
+	// HeadOnly tells whether to read only the <head> of an HTML home page,
+	// stopping at the start of the <body>
+	// (or after 256KB),
+	// to save bandwidth when only head-level signals matter,
+	// such as the title and meta tags
+	// and JSON-LD in the head.
+	// The tests that examine the page see only its head;
+	// WebPageRef, for instance, matches the title but not the body text.
+	// Pages stored in PageCache are read in full regardless.
+	HeadOnly bool
+
+	// CrawlDepth is how many links away from the home page
+	// the WebPageRef test looks for the reference string
+	// when the home page itself doesn't have it,
+	// following links within the same site
+	// (by registrable domain; see PublicSuffixList)
+	// such as a navigation link to an "About" page.
+	// Each page fetched counts against MaxRequestsPerMatch
+	// and may come from PageCache.
+	// When WebPageRef passes on such a page,
+	// its outcome's URL is that page's
+	// (see TestOutcome.URL).
+	// The default, 0,
+	// means the home page only.
+	CrawlDepth int
+
+	// CrawlLinks is the number of links followed from each page
+	// when CrawlDepth is positive.
+	// Links whose text or path suggests a page about the organization,
+	// with words like "about" and "company",
+	// come first.
+	// If zero, DefaultCrawlLinks is used.
+	CrawlLinks int
+
+	// The rate limiters enforcing RequestsPerSecond and PerHostRequestsPerSecond.
+	// Copies of a Matcher share this.
+	limits *limiterSet
+
+	// The threshold for MatchAtLeast, if that's what is running.
+	// See isDecisive.
+	atLeast *float32
 }
 
+// DefaultMinAffixLength is the length below which
+// the SignificantAffixes test disregards an affix
+// when Matcher.MinAffixLength is zero.
+// A single character is too short to be a significant word.
+const DefaultMinAffixLength = 2
+
 var defaultMatcher = Matcher{
 	Scores: map[testType]int{
 		testRootPhrase:           50,
@@ -63,36 +891,219 @@ var defaultMatcher = Matcher{
 		testSignificantAffixes:   -10,
 		testWebPageRef:           50,
 	},
-	Stop: defaultStopper,
+	Stop:          defaultStopper,
+	Collapse:      defaultCollapse,
+	RegionAffixes: DefaultRegionAffixes,
 }
 
 // NewMatcher returns a new Matcher with default score values.
 // It does this by making a copy of defaultMatcher.
 // The copy is deep so callers are free to modify the result without affecting defaultMatcher.
+// The new Matcher also gets its own rate limiters
+// (see RequestsPerSecond and PerHostRequestsPerSecond).
 func NewMatcher() Matcher {
-	result := defaultMatcher // makes a copy, but with a reference to the same Scores map
-	result.Scores = make(map[testType]int)
-	for k, v := range defaultMatcher.Scores {
-		result.Scores[k] = v
+	result := defaultMatcher.Clone()
+	result.limits = new(limiterSet)
+	return result
+}
+
+// Clone returns a copy of m that can be modified without affecting m.
+// The maps in m (Scores, Collapse, TLDWeights, CommonWords, NegativeKeywords, TestTimeouts, ContentTypes, and ConnectAddresses) are copied deeply,
+// as are the slices (PageRegions, ResponseHeaders, RefNoise, and NewDomainWeights)
+// and AmbiguousBand.
+// Everything else is shared with m:
+// the regular expressions in RefNoise,
+// the Stoppers, providers, and other interface values
+// (such as Whois, PageCache, and Aliases),
+// the functions (such as Now),
+// and the *http.Client,
+// which are expected not to change,
+// and the rate limiters,
+// so requests made via the clone count against the same limits as requests made via m.
+func (m Matcher) Clone() Matcher {
+	result := m // makes a copy, but with references to the same maps
+	if m.Scores != nil {
+		result.Scores = make(map[testType]int)
+		for k, v := range m.Scores {
+			result.Scores[k] = v
+		}
+	}
+	if m.Collapse != nil {
+		result.Collapse = make(map[string]string)
+		for k, v := range m.Collapse {
+			result.Collapse[k] = v
+		}
+	}
+	if m.CommonWords != nil {
+		result.CommonWords = make(map[string]bool)
+		for k, v := range m.CommonWords {
+			result.CommonWords[k] = v
+		}
+	}
+	if m.NegativeKeywords != nil {
+		result.NegativeKeywords = make(map[string]bool)
+		for k, v := range m.NegativeKeywords {
+			result.NegativeKeywords[k] = v
+		}
+	}
+	if m.TLDWeights != nil {
+		result.TLDWeights = make(map[string]float32)
+		for k, v := range m.TLDWeights {
+			result.TLDWeights[k] = v
+		}
+	}
+	if m.TestTimeouts != nil {
+		result.TestTimeouts = make(map[testType]time.Duration)
+		for k, v := range m.TestTimeouts {
+			result.TestTimeouts[k] = v
+		}
+	}
+	if m.ContentTypes != nil {
+		result.ContentTypes = make(map[string]ContentKind)
+		for k, v := range m.ContentTypes {
+			result.ContentTypes[k] = v
+		}
+	}
+	if m.ConnectAddresses != nil {
+		result.ConnectAddresses = make(map[string]string)
+		for k, v := range m.ConnectAddresses {
+			result.ConnectAddresses[k] = v
+		}
+	}
+	if m.PageRegions != nil {
+		result.PageRegions = append([]PageRegion{}, m.PageRegions...)
+	}
+	if m.ResponseHeaders != nil {
+		result.ResponseHeaders = append([]string{}, m.ResponseHeaders...)
+	}
+	if m.RefNoise != nil {
+		result.RefNoise = append([]*regexp.Regexp{}, m.RefNoise...)
+	}
+	if m.NewDomainWeights != nil {
+		result.NewDomainWeights = append([]DomainAgeWeight{}, m.NewDomainWeights...)
+	}
+	if m.AmbiguousBand != nil {
+		band := *m.AmbiguousBand
+		result.AmbiguousBand = &band
+	}
+	return result
+}
+
+// WithScore returns a copy of m
+// (see Clone)
+// in which the given test has the given score.
+// A score of zero turns the test off.
+// It panics if test is not the name of a test.
+func (m Matcher) WithScore(test TestName, score int) Matcher {
+	t, ok := testByName(string(test))
+	if !ok {
+		panic(fmt.Sprintf("unknown test %q", test))
+	}
+	result := m.Clone()
+	if result.Scores == nil {
+		result.Scores = make(map[testType]int)
+	}
+	if score == 0 {
+		delete(result.Scores, t)
+	} else {
+		result.Scores[t] = score
 	}
 	return result
 }
 
+// WithoutTest returns a copy of m
+// (see Clone)
+// in which the given test is turned off.
+// It panics if test is not the name of a test.
+func (m Matcher) WithoutTest(test TestName) Matcher {
+	return m.WithScore(test, 0)
+}
+
 // Match matches ref,
 // a reference string containing an organization name,
 // against domain.
+// The domain may include a port, as in "example.com:8443",
+// and may be an IPv6 literal, with or without brackets.
+// A port is ignored by the string tests
+// but is used when fetching the domain's home page.
+// The string tests are skipped for an IP address
+// (see SkipIPNetworkTests).
 // It reports the likelihood
 // (as a float in [0.0..1.0])
 // that the domain belongs to the organization.
 func (m Matcher) Match(ref, domain string) (float32, error) {
-	score, err := m.doMatch(ref, domain)
+	return m.MatchContext(context.Background(), ref, domain)
+}
+
+// MatchContext is like Match but takes a context,
+// which bounds the time spent on the network tests.
+// Each network test is further bounded by its own time budget
+// (see TestTimeouts).
+func (m Matcher) MatchContext(ctx context.Context, ref, domain string) (float32, error) {
+	normalized, _, err := m.ScoreContext(ctx, ref, domain)
+	return normalized, err
+}
+
+// Score is like Match
+// but also returns the raw score:
+// the sum of the scores (in Scores) of the tests that pass,
+// after any TLD weight.
+// The normalized score is the raw score
+// mapped from the range of possible scores to [0.0..1.0]
+// (see Calibration).
+func (m Matcher) Score(ref, domain string) (normalized float32, raw int, err error) {
+	return m.ScoreContext(context.Background(), ref, domain)
+}
+
+// ScoreContext is like Score but takes a context
+// (see MatchContext).
+func (m Matcher) ScoreContext(ctx context.Context, ref, domain string) (normalized float32, raw int, err error) {
+	raw, err = m.doMatch(ctx, ref, domain)
 	if err != nil {
-		return 0, err
+		m.metrics().Match(0, err)
+		return 0, 0, err
 	}
+	normalized = m.scale(raw)
+	m.metrics().Match(normalized, nil)
+	return normalized, raw, nil
+}
+
+// This maps score from the range of possible scores under m.Scores to [0..1],
+// using m.Calibration if it's set.
+func (m Matcher) scale(score int) float32 {
+	min, max := m.scoreRange()
+	return m.scaleRange(score, min, max)
+}
 
-	// Compute the min and max possible scores.
-	var min, max int
+// This is like scale
+// but maps score from the given range.
+func (m Matcher) scaleRange(score, min, max int) float32 {
+	calibrate := m.Calibration
+	if calibrate == nil {
+		calibrate = LinearScale
+	}
 
+	// A TLD weight (see TLDWeights) can push the score outside the range,
+	// and a calibration function can misbehave,
+	// so clamp it.
+	result := calibrate(score, min, max)
+	if result < 0 {
+		return 0
+	}
+	if result > 1 {
+		return 1
+	}
+	return result
+}
+
+// LinearScale maps raw from the range [min..max] to [0.0..1.0] linearly.
+// It is the default for Matcher.Calibration.
+func LinearScale(raw, min, max int) float32 {
+	return float32(raw-min) / float32(max-min)
+}
+
+// This returns the min and max possible scores under m.Scores.
+func (m Matcher) scoreRange() (min, max int) {
 	for _, v := range m.Scores {
 		if v < 0 {
 			min += v
@@ -100,209 +1111,1185 @@ func (m Matcher) Match(ref, domain string) (float32, error) {
 			max += v
 		}
 	}
+	return min, max
+}
+
+// matchInput holds the values,
+// derived from a reference string and a domain,
+// that the tests work on.
+type matchInput struct {
+	// Ref is the reference string as given.
+	ref string
+
+	// Norm is the normalized root phrase of the reference.
+	// Repeated words are kept,
+	// as in {"duck", "duck", "go"},
+	// and every test treats them as separate words:
+	// the root phrase and its pattern (see rootPhrasePattern)
+	// require each occurrence,
+	// while a test that looks for any one word
+	// (like AnyRootWord)
+	// is satisfied by a single occurrence.
+	norm []string
 
-	// Map score from that range to [0..1].
-	return float32(score-min) / float32(max-min), nil
+	// Joined is the normalized root phrase as a single string.
+	joined string
+
+	// Marks tells whether the root phrase keeps its diacritics
+	// (as the tokens given to MatchTokens may),
+	// in which case the domain and the text of web pages keep theirs
+	// (see Matcher.foldDomain and Matcher.textFolder).
+	marks bool
+
+	// Significant contains only the significant words of norm
+	// (so {"sanford", "and", "son"} becomes {"sanford", "son"}).
+	significant []string
+
+	// Re matches the words of norm,
+	// in sequence,
+	// plus anything between them.
+	re *regexp.Regexp
+
+	// Domain is the lowercased host name, without any port, for the string tests.
+	domain string
+
+	// IP tells whether the host name is an IP address
+	// (see Matcher.SkipIPNetworkTests).
+	ip bool
+
+	// WebURL is the URL of the page that the web tests fetch:
+	// the domain's home page,
+	// or the URL given in place of a domain
+	// (see pageURL).
+	webURL *url.URL
+
+	// CrawledURL is the URL of the page linked from the home page
+	// that WebPageRef passed on,
+	// if it passed on one rather than the home page itself
+	// (see Matcher.CrawlDepth).
+	// Only that test sets it,
+	// and it is read only after the tests finish.
+	crawledURL string
+
+	// The fetch of the domain's home page.
+	// Inputs for the same domain may share this.
+	// See homePage.
+	fetch *pageFetch
+
+	// Budget is the number of outbound requests remaining for the match.
+	// Inputs for the same match share this.
+	budget *requestBudget
+
+	// Memo holds the results that don't depend on the reference.
+	// Inputs for a reference and its aliases share this.
+	memo *domainMemo
 }
 
-func (m Matcher) doMatch(ref, domain string) (int, error) {
-	norm := m.normalizedRootPhrase(ref)
+func (m Matcher) newMatchInput(ref, domain string) (*matchInput, error) {
+	return m.newMatchInputNorm(ref, m.normalizedRootPhrase(ref), domain)
+}
+
+// This is like newMatchInput
+// but uses norm as the normalized root phrase of ref.
+func (m Matcher) newMatchInputNorm(ref string, norm []string, domain string) (*matchInput, error) {
+	webURL := pageURL(domain)
+
+	joined := strings.Join(norm, "")
+	marks := foldDiacritics(joined) != joined
+
+	in := &matchInput{
+		ref:    ref,
+		norm:   norm,
+		joined: joined,
+		marks:  marks,
 
-	domain = strings.ToLower(domain)
+		// The string tests look only at the host name,
+		// without any port or leading "www." label
+		// (which is never significant).
+		// The web tests get the whole URL.
+		domain: m.foldDomain(webURL.Hostname(), marks),
+		ip:     net.ParseIP(webURL.Hostname()) != nil,
+		webURL: webURL,
+
+		fetch:  newPageFetch(),
+		budget: newRequestBudget(m.MaxRequestsPerMatch),
+		memo:   newDomainMemo(),
+	}
 	// TODO: lop off TLD(s) from domain,
 	// and uninteresting subdomains.
 	// (E.g. in foo.coalitioninc.com we only care about coalitioninc.)
 	// Need to recognize that in something like coalition.github.io
 	// we might care about coalition or we might care about github.
 
-	// The normalized root phrase as a single string.
-	joined := strings.Join(norm, "")
-
-	// Make a copy of norm that contains only significant words
-	// (so {"sanford", "and", "son"} becomes {"sanford", "son"}).
-	var significantNorm []string
 	for _, word := range norm {
-		if !m.Stop.IsStopWord(word) {
-			significantNorm = append(significantNorm, word)
+		if !isStopWord(m.Stop, word) {
+			in.significant = append(in.significant, word)
 		}
 	}
 
-	// Now make a regex that matches the significant words of norm,
-	// in sequence,
-	// plus anything between them
-	// (so "sanford and son" or "sanford & son" or "sanford, son" etc).
-	// Note: the strings in norm don't need quoting with regexp.QuoteMeta
-	// because they contain only letters and no metacharacters.
-	re, err := regexp.Compile(strings.Join(norm, "(.*)"))
+	re, err := regexp.Compile(rootPhrasePattern(norm))
 	if err != nil { // should be impossible
-		return 0, err
+		return nil, err
 	}
+	in.re = re
 
-	// min and max hold the lowest and highest possible scores,
-	// for mapping to [0..1] at the end.
+	return in, nil
+}
 
-	var score int
+// This case-folds host for the string tests
+// and removes any leading "www." label,
+// then shortens runs of letters if m.MaxLetterRun is set.
+// If marks is false,
+// it also strips the diacritics from host,
+// as Normalize does for the root phrase,
+// and if m.CompatibilityFolding is set
+// it first applies compatibility folding.
+func (m Matcher) foldDomain(host string, marks bool) string {
+	if m.CompatibilityFolding {
+		host = foldCompatibility(host)
+	}
+	host = foldCase(host, m.Language)
+	if !marks {
+		host = foldDiacritics(host)
+	}
+	host = strings.TrimPrefix(host, "www.")
+	if m.MaxLetterRun > 0 {
+		host = shortenLetterRuns(host, m.MaxLetterRun)
+	}
+	return host
+}
+
+// This returns a function that folds text from the web,
+// such as the text of a page,
+// the way Normalize folds a reference for m
+// (see Matcher.normalizedRootPhrase),
+// so that the pattern of the root phrase
+// (see rootPhrasePattern)
+// can match it.
+// Diacritics are stripped unless marks is true
+// (see matchInput.marks).
+func (m Matcher) textFolder(marks bool) func(string) string {
+	collapse := collapser(m.collapse())
+	return func(s string) string {
+		if m.CompatibilityFolding {
+			s = foldCompatibility(s)
+		}
+		s = foldCase(s, m.Language)
+		if !marks {
+			s = foldDiacritics(s)
+		}
+		s = collapse.Replace(s)
+		s = replaceConnectors(s, m.Connectors)
+		if m.MaxLetterRun > 0 {
+			s = shortenLetterRuns(s, m.MaxLetterRun)
+		}
+		return s
+	}
+}
+
+// This returns a textMatcher for re,
+// which is in.re or another pattern built from in's root phrase,
+// that folds text as m.textFolder does.
+func (m Matcher) textMatcher(in *matchInput, re *regexp.Regexp) textMatcher {
+	return textMatcher{
+		re:   re,
+		fold: m.textFolder(in.marks),
+	}
+}
+
+// textMatcher matches text from the web against a pattern built from a normalized root phrase,
+// after folding the text the same way.
+// Every test that looks for the reference in web content uses one
+// (see Matcher.textMatcher).
+type textMatcher struct {
+	re   *regexp.Regexp
+	fold func(string) string
+}
+
+func (t textMatcher) match(text string) bool {
+	return t.re.MatchString(t.fold(text))
+}
+
+// This makes the source of a regex that matches the words of norm,
+// in sequence,
+// plus anything between them
+// (so "sanford and son" or "sanford & son" or "sanford, son" etc).
+// A repeated word must appear as many times as it does in norm
+// (so "duck(.*)duck(.*)go" does not match "duck go").
+// The words are quoted with regexp.QuoteMeta,
+// which changes nothing for normalized words
+// (they contain only letters)
+// but matters for the tokens given to MatchTokens.
+func rootPhrasePattern(norm []string) string {
+	quoted := make([]string, 0, len(norm))
+	for _, word := range norm {
+		quoted = append(quoted, regexp.QuoteMeta(word))
+	}
+	return strings.Join(quoted, "(.*)")
+}
+
+// Pattern returns the source of the regular expression
+// that m uses to look for ref in the text of web pages
+// and in the SignificantAffixes test,
+// e.g. "coalition(.*)security" for "Coalition Security, Inc."
+// It reflects the normalization performed by m
+// (including its choice of stop words),
+// and is useful for understanding why a page did or didn't match.
+func (m Matcher) Pattern(ref string) (string, error) {
+	in, err := m.newMatchInput(ref, "")
+	if err != nil {
+		return "", err
+	}
+	return in.re.String(), nil
+}
+
+// testDef describes one of the tests that doMatch can run.
+type testDef struct {
+	typ testType
+
+	// HomePage tells whether the test examines the domain's home page.
+	// All such tests share a single fetch
+	// (see matchInput.homePage).
+	homePage bool
+
+	// Network tells whether the test makes network requests.
+	// Each network test runs with its own time budget
+	// (see Matcher.TestTimeouts).
+	network bool
+
+	// SkipIfPassed lists tests that, if they passed,
+	// make this test unnecessary.
+	// This test does not start until those tests are finished.
+	// Other tests may run concurrently with this one.
+	// A test not among those being run counts as not passing.
+	skipIfPassed []testType
+
+	// Requires lists tests that must all pass for this test to run,
+	// as when a negative test is meaningful only if a positive one passed.
+	// This test does not start until those tests are finished.
+	// A test not among those being run,
+	// or with no score in Matcher.Scores,
+	// counts as not passing.
+	requires []testType
+
+	// Run reports whether the test passes.
+	run func(ctx context.Context, m Matcher, in *matchInput) (bool, error)
+
+	// PerDomain tells whether the test's result depends only on the domain
+	// (and the organization),
+	// not on the reference string naming it,
+	// so that it is the same for every alias of a reference
+	// and runs only once per match
+	// (see matchInput.memo).
+	perDomain bool
+
+	// MemoKey, if non-nil,
+	// is the key in matchInput.memo
+	// of the data the test fetches,
+	// when that depends only on the domain.
+	// Once it's there the test needs no requests.
+	memoKey interface{}
+
+	// Grade, if non-nil, is used instead of run.
+	// It reports the fraction of the test's score that it earns
+	// (its confidence; see TestOutcome.Confidence),
+	// from 0 (the test does not pass) to 1.
+	// A result outside that range is clamped to it.
+	grade func(ctx context.Context, m Matcher, in *matchInput) (float32, error)
+}
+
+// stringTests are the tests that examine only the reference and the domain name.
+var stringTests = []testDef{
+	{typ: testRootPhrase, grade: runRootPhraseTest},
+	{typ: testAnyRootWord, skipIfPassed: []testType{testRootPhrase}, grade: runAnyRootWordTest},
+	{typ: testMisspelledRootPhrase, skipIfPassed: []testType{testRootPhrase}, grade: runMisspelledRootPhraseTest},
+	{typ: testSignificantAffixes, run: runSignificantAffixesTest},
+	{typ: testHyphenated, run: runHyphenatedTest},
+	{typ: testNegativeKeyword, run: runNegativeKeywordTest},
+	{typ: testLeadWord, skipIfPassed: []testType{testRootPhrase}, run: runLeadWordTest},
+}
+
+// networkTests are the tests that make network requests.
+var networkTests = []testDef{
+	{typ: testWebPageRef, network: true, homePage: true, run: runWebPageRefTest},
+	{typ: testJSONLDOrganization, network: true, homePage: true, run: runJSONLDOrganizationTest},
+	{typ: testNewDomain, network: true, perDomain: true, grade: runNewDomainTest},
+	{typ: testCanonicalHost, network: true, homePage: true, run: runCanonicalHostTest},
+	{typ: testWebPageShortName, network: true, homePage: true, skipIfPassed: []testType{testWebPageRef}, run: runWebPageShortNameTest},
+	{typ: testBrandAsset, network: true, homePage: true, run: runBrandAssetTest},
+	{typ: testResponseHeader, network: true, homePage: true, run: runResponseHeaderTest},
+	{typ: testExternal, network: true, perDomain: true, grade: runExternalTest},
+	{typ: testCopyright, network: true, homePage: true, run: runCopyrightTest},
+	{typ: testSecurityTxt, network: true, memoKey: securityTxtKey{}, run: runSecurityTxtTest},
+	{typ: testPageLanguage, network: true, homePage: true, run: runPageLanguageTest},
+	{typ: testPageLanguageMismatch, network: true, homePage: true, run: runPageLanguageMismatchTest},
+	{typ: testSelfLinkedRef, network: true, homePage: true, run: runSelfLinkedRefTest},
+	{typ: testArchivedPageRef, network: true, memoKey: archiveKey{}, run: runArchivedPageRefTest},
+}
+
+// builtinTests are the tests doMatch runs.
+var builtinTests = append(append([]testDef{}, stringTests...), networkTests...)
+
+// defaultTestTimeout is the time budget for a network test
+// that has no entry in Matcher.TestTimeouts.
+const defaultTestTimeout = 5 * time.Second // arbitrary
+
+func (m Matcher) doMatch(ctx context.Context, ref, domain string) (int, error) {
+	score, _, err := m.doMatchDetail(ctx, ref, domain)
+	return score, err
+}
+
+// doMatchDetail is like doMatch
+// but also returns the outcomes of the tests
+// for the reference or alias that produced the score.
+func (m Matcher) doMatchDetail(ctx context.Context, ref, domain string) (int, *Detail, error) {
+	in, err := m.newMatchInput(ref, domain)
+	if err != nil {
+		return 0, nil, err
+	}
+	return m.doMatchInput(ctx, in, domain)
+}
+
+// doMatchInput is like doMatchDetail
+// but takes the already-built input for the reference.
+func (m Matcher) doMatchInput(ctx context.Context, in *matchInput, domain string) (int, *Detail, error) {
+	ref := in.ref
+
+	official := m.isOfficial(in)
+	if official {
+		// The boost comes after the tests,
+		// so they can't decide a MatchAtLeast.
+		m.atLeast = nil
+	}
+	if official && m.OfficialBoost == 0 {
+		// Human-verified data overrides the heuristics.
+		_, max := m.scoreRange()
+		return max, &Detail{Ref: ref, Official: true}, nil
+	}
+
+	score, detail, err := m.runAllTests(ctx, in, domain)
+	if err != nil {
+		return 0, nil, err
+	}
+	if official {
+		score += m.OfficialBoost
+		detail.Official = true
+	}
+	return score, detail, nil
+}
 
-	passed := make(map[testType]bool)
+// runAllTests runs the tests for in,
+// and for each alias of in.ref,
+// and returns the best score and its details.
+func (m Matcher) runAllTests(ctx context.Context, in *matchInput, domain string) (int, *Detail, error) {
+	// The fetch of the home page
+	// (see matchInput.startHomePage)
+	// is stopped, if it's still going,
+	// when the match is done.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ref := in.ref
+	outcomes, err := m.runTestsDetail(ctx, in, builtinTests)
+	if err != nil {
+		return 0, nil, err
+	}
+	score, tooFew := m.passingSum(outcomes)
+	detail := &Detail{Ref: ref, Outcomes: outcomes, TooFewPassed: tooFew}
+
+	// Score the domain against each alias too, and take the best.
+	// All share a single fetch of the home page,
+	// and the results of the tests that don't depend on the reference.
+	var aliases []string
+	if m.Aliases != nil {
+		aliases = m.aliases(in)
+	}
+	for _, alias := range aliases {
+		aliasIn, err := m.newMatchInput(alias, domain)
+		if err != nil {
+			return 0, nil, err
+		}
+		aliasIn.fetch = in.fetch
+		aliasIn.budget = in.budget
+		aliasIn.memo = in.memo
 
-	// RootPhrase test.
-	if v := m.Scores[testRootPhrase]; v != 0 {
-		if strings.Contains(domain, joined) {
-			score += v
-			passed[testRootPhrase] = true
+		aliasOutcomes, err := m.runTestsDetail(ctx, aliasIn, builtinTests)
+		if err != nil {
+			return 0, nil, err
+		}
+		if aliasScore, tooFew := m.passingSum(aliasOutcomes); aliasScore > score {
+			score = aliasScore
+			detail = &Detail{Ref: alias, Outcomes: aliasOutcomes, TooFewPassed: tooFew}
 		}
 	}
 
-	// AnyRootWord test.
-	if v := m.Scores[testAnyRootWord]; !passed[testRootPhrase] && v != 0 {
-		for _, word := range norm {
-			if strings.Contains(domain, word) {
-				score += v
-				passed[testAnyRootWord] = true
-				break
+	cancel()
+	detail.Fetch = in.fetch.wait()
+	return m.weighScore(score, in, detail), detail, nil
+}
+
+// passingSum returns the total score of outcomes,
+// or, if fewer than m.MinPassingTests tests with positive scores passed,
+// the lowest possible score
+// and true.
+func (m Matcher) passingSum(outcomes []TestOutcome) (int, bool) {
+	if m.MinPassingTests > 0 {
+		var passed int
+		for _, o := range outcomes {
+			if o.Passed && o.Score > 0 {
+				passed++
 			}
 		}
+		if passed < m.MinPassingTests {
+			min, _ := m.scoreRange()
+			return min, true
+		}
 	}
+	return sumOutcomes(outcomes), false
+}
 
-	// MisspelledRootPhrase test.
-	if v := m.Scores[testMisspelledRootPhrase]; !passed[testRootPhrase] && v != 0 {
-		// Check each substring of domain whose length is in [len(joined)-2..len(joined)+2]
-		// looking for ones with a Levenshtein edit distance of 1 or 2 away from joined.
-		// (An edit distance of 0 is an exact match which is covered by the testRootPhrase case.)
-		found := false
-		for start := 0; !found && start < len(domain)-len(joined)+2; start++ {
-			for l := -2; l <= 2; l++ {
-				end := start + len(joined) + l
-				if end > len(domain) {
-					break
+// This applies the TLD weight for in.domain to score
+// (see applyTLDWeight),
+// unless detail says too few tests passed
+// (see passingSum),
+// in which case score stays the lowest possible.
+func (m Matcher) weighScore(score int, in *matchInput, detail *Detail) int {
+	if detail.TooFewPassed {
+		return score
+	}
+	return m.applyTLDWeight(score, in)
+}
+
+// This applies the weight from m.TLDWeights for in.domain, if any, to score.
+func (m Matcher) applyTLDWeight(score int, in *matchInput) int {
+	w, ok := m.tldWeight(in.domain)
+	if !ok {
+		return score
+	}
+	return int(math.Round(float64(score) * float64(w)))
+}
+
+// This finds the entry in m.TLDWeights for the longest suffix of domain that has one.
+func (m Matcher) tldWeight(domain string) (float32, bool) {
+	labels := strings.Split(domain, ".")
+	for i := 1; i < len(labels); i++ {
+		if w, ok := m.TLDWeights[strings.Join(labels[i:], ".")]; ok {
+			return w, true
+		}
+	}
+	return 0, false
+}
+
+// runTests runs the given tests
+// and returns the total score of the ones that pass.
+// See runTestsDetail.
+func (m Matcher) runTests(ctx context.Context, in *matchInput, tests []testDef) (int, error) {
+	outcomes, err := m.runTestsDetail(ctx, in, tests)
+	if err != nil {
+		return 0, err
+	}
+	return sumOutcomes(outcomes), nil
+}
+
+// This returns the total score of outcomes.
+func sumOutcomes(outcomes []TestOutcome) int {
+	var score int
+	for _, o := range outcomes {
+		score += o.Score
+	}
+	return score
+}
+
+// runTestsDetail runs the given tests concurrently,
+// skipping those with no score in m.Scores,
+// and returns the outcome of each test that has a score,
+// in the order of tests.
+// A test waits for the tests named in its skipIfPassed list to finish
+// before deciding whether to run.
+// A network test with a positive score also waits for the string tests,
+// and is skipped if they alone guarantee the maximum result
+// (see earlyTests and isSettled)
+// or decide the match
+// (see isDecisive).
+// Under MatchAtLeast,
+// so does a network test with a negative score.
+// The fetch of the home page that the page tests share
+// runs under ctx,
+// not under any one test's time budget
+// (see matchInput.startHomePage).
+// The first error from any test cancels the others and is returned.
+func (m Matcher) runTestsDetail(ctx context.Context, in *matchInput, tests []testDef) ([]TestOutcome, error) {
+	var (
+		passed = make([]bool, len(tests))
+
+		// Earned[i] is the fraction of its score that tests[i] earned.
+		earned = make([]float32, len(tests))
+
+		// Index maps each test type to its position in tests.
+		index = make(map[testType]int)
+
+		// SkippedBy[i] is the test whose passing caused tests[i] to be skipped.
+		skippedBy = make([]testType, len(tests))
+
+		// Unmet[i] is the test whose not passing caused tests[i] to be skipped.
+		unmet = make([]testType, len(tests))
+
+		// Settled[i] tells whether tests[i] was skipped
+		// because it could not change the result.
+		settled = make([]bool, len(tests))
+
+		// Decided[i] tells whether tests[i] was skipped
+		// because the string tests were decisive
+		// (see isDecisive).
+		decided = make([]bool, len(tests))
+
+		// IPSkipped[i] tells whether tests[i] was skipped
+		// because the domain is an IP address
+		// (see Matcher.SkipIPNetworkTests).
+		ipSkipped = make([]bool, len(tests))
+
+		// Done[i] is closed when tests[i] has finished (or been skipped).
+		// After that, passed[i] and earned[i] are safe to read.
+		done = make([]chan struct{}, len(tests))
+	)
+	for i, t := range tests {
+		index[t.typ] = i
+		done[i] = make(chan struct{})
+	}
+	if err := checkTestOrder(tests, index); err != nil {
+		return nil, err
+	}
+
+	if in.ip {
+		for i, t := range tests {
+			ipSkipped[i] = !t.network || m.SkipIPNetworkTests
+		}
+	}
+
+	// Reserve the requests the network tests need,
+	// in test order,
+	// so which tests are over budget doesn't depend on which finish first.
+	overBudget := make([]bool, len(tests))
+	for i, t := range tests {
+		if t.network && m.Scores[t.typ] != 0 && !ipSkipped[i] && !in.memoized(t) && !in.budget.reserve(t) {
+			overBudget[i] = true
+		}
+	}
+
+	// Network tests with positive scores wait for these string tests
+	// (see isSettled).
+	// A string test that waits on a network test,
+	// directly or indirectly,
+	// is excluded to avoid a deadlock.
+	early := earlyTests(tests, index)
+	for i := range tests {
+		// A skipped string test has nothing to say about the others.
+		early[i] = early[i] && !ipSkipped[i]
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	for i, t := range tests {
+		i, t := i, t
+		g.Go(func() error {
+			defer close(done[i])
+
+			if m.Scores[t.typ] == 0 || overBudget[i] || ipSkipped[i] {
+				return nil
+			}
+			for _, gate := range t.skipIfPassed {
+				j, ok := index[gate]
+				if !ok {
+					continue
 				}
-				substr := domain[start:end]
-				if d := levenshtein.ComputeDistance(joined, substr); d == 1 || d == 2 {
-					found = true
-					break
+				select {
+				case <-done[j]:
+				case <-gctx.Done():
+					return gctx.Err()
+				}
+				if passed[j] {
+					skippedBy[i] = gate
+					return nil
+				}
+			}
+			for _, req := range t.requires {
+				j, ok := index[req]
+				if !ok {
+					unmet[i] = req
+					return nil
+				}
+				select {
+				case <-done[j]:
+				case <-gctx.Done():
+					return gctx.Err()
 				}
+				if !passed[j] {
+					unmet[i] = req
+					return nil
+				}
+			}
+
+			if t.network && (m.Scores[t.typ] > 0 || m.atLeast != nil) {
+				// The string tests are quick,
+				// so wait for them in case they make this test unnecessary.
+				for j := range tests {
+					if !early[j] {
+						continue
+					}
+					select {
+					case <-done[j]:
+					case <-gctx.Done():
+						return gctx.Err()
+					}
+				}
+				if m.isSettled(in, tests, early, earned) {
+					settled[i] = true
+					return nil
+				}
+				if m.isDecisive(in, tests, early, earned) {
+					decided[i] = true
+					return nil
+				}
+			}
+
+			if t.homePage {
+				in.startHomePage(ctx, m)
+			}
+			frac, err := m.runTest(gctx, in, t)
+			if err != nil {
+				return err
 			}
+			passed[i] = frac > 0
+			earned[i] = frac
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	// Report the outcomes in test order,
+	// so they don't depend on the order in which tests finished.
+	var outcomes []TestOutcome
+	for i, t := range tests {
+		score := m.Scores[t.typ]
+		if score == 0 {
+			continue
 		}
-		if found {
-			score += v
-			passed[testMisspelledRootPhrase] = true
+		o := TestOutcome{
+			Test:       testNames[t.typ],
+			Skipped:    skippedBy[i] != testNone || unmet[i] != testNone || settled[i] || decided[i] || overBudget[i] || ipSkipped[i],
+			SkippedBy:  testNames[skippedBy[i]],
+			Unmet:      testNames[unmet[i]],
+			OverBudget: overBudget[i],
+			Decided:    decided[i],
+			IPAddress:  ipSkipped[i],
+			Passed:     passed[i],
+		}
+		if passed[i] {
+			o.Confidence = earned[i]
+			o.Score = int(math.Round(float64(score) * float64(earned[i])))
+			if t.typ == testWebPageRef && in.crawledURL != "" {
+				o.URL = in.crawledURL
+			} else if t.homePage {
+				o.URL = in.fetch.pageURL()
+			}
 		}
+		outcomes = append(outcomes, o)
 	}
+	return outcomes, nil
+}
 
-	// SignificantAffixes test.
-	if v := m.Scores[testSignificantAffixes]; v != 0 {
-		if m.doSignificantAffixesTest(domain, re) {
-			score += v
-			passed[testSignificantAffixes] = true
+// checkTestOrder makes sure that each of tests
+// depends (through skipIfPassed or requires)
+// only on tests that precede it.
+// That rules out cycles,
+// which would deadlock runTestsDetail.
+// Index maps each test type to its position in tests.
+func checkTestOrder(tests []testDef, index map[testType]int) error {
+	for i, t := range tests {
+		for _, dep := range t.deps() {
+			if j, ok := index[dep]; ok && j >= i {
+				return fmt.Errorf("test %s depends on %s, which does not precede it", testNames[t.typ], testNames[dep])
+			}
 		}
 	}
+	return nil
+}
 
-	if v := m.Scores[testWebPageRef]; v != 0 {
-		// Note: if domain is normalized in some way (see notes above),
-		// we want the unmodified domain here.
-		found, err := doWebPageRefTest(domain, re)
-		if err != nil {
-			return 0, err
+// deps returns the tests that t waits for:
+// those in its skipIfPassed and requires lists.
+func (t testDef) deps() []testType {
+	return append(append([]testType{}, t.skipIfPassed...), t.requires...)
+}
+
+// earlyTests tells, for each of tests,
+// whether it is a string test that does not depend,
+// even indirectly through skipIfPassed or requires,
+// on a network test.
+func earlyTests(tests []testDef, index map[testType]int) []bool {
+	early := make([]bool, len(tests))
+	for i, t := range tests {
+		early[i] = !t.network
+	}
+
+	// Propagate lateness through the gates until nothing changes.
+	for changed := true; changed; {
+		changed = false
+		for i, t := range tests {
+			if !early[i] {
+				continue
+			}
+			for _, dep := range t.deps() {
+				if j, ok := index[dep]; ok && !early[j] {
+					early[i] = false
+					changed = true
+					break
+				}
+			}
 		}
-		if found {
-			score += v
-			passed[testWebPageRef] = true
+	}
+
+	return early
+}
+
+// isSettled tells whether the early string tests among tests
+// (see earlyTests)
+// already guarantee a result of 1
+// (after scaling to [0..1] and applying any TLD weight),
+// even if every other test with a negative score passes.
+// In that case the network tests with positive scores need not run.
+// It is false under m.MinPassingTests,
+// since then every passing test may be needed.
+// Earned must be safe to read for all the early tests.
+func (m Matcher) isSettled(in *matchInput, tests []testDef, early []bool, earned []float32) bool {
+	if m.MinPassingTests > 0 {
+		return false
+	}
+	worst := m.earlySubtotal(tests, early, earned)
+	for i, t := range tests {
+		if score := m.Scores[t.typ]; !early[i] && score < 0 {
+			worst += score
 		}
 	}
+	_, max := m.scoreRange()
+	return m.applyTLDWeight(worst, in) >= max
+}
 
-	return score, nil
+// earlySubtotal returns the total score of the early tests among tests
+// (see earlyTests).
+// Earned must be safe to read for all of them.
+func (m Matcher) earlySubtotal(tests []testDef, early []bool, earned []float32) int {
+	var result int
+	for i, t := range tests {
+		if early[i] {
+			result += int(math.Round(float64(m.Scores[t.typ]) * float64(earned[i])))
+		}
+	}
+	return result
 }
 
-// This normalizes an input string like "The Genco Olive Oil Company, LLP"
-// to a "root phrase" like {"genco", "olive", "oil"}.
-// It does this by downcasing everything,
-// collapsing some punctuation (e.g. apostrophes),
-// splitting into words (on whitespace and other punctuation),
-// and removing stop words from the left and right ends.
-// TODO: Map Unicode letters with diacritics to plain letters where possible. (See https://blog.golang.org/normalization.)
-func (m Matcher) normalizedRootPhrase(inp string) []string {
-	inp = strings.ToLower(inp)
-
-	// Collapse apostrophes, so "Tom's of Maine" does not become {"tom", "s", "of", "maine"}. (TODO: Anything else?)
-	inp = strings.ReplaceAll(inp, "'", "")
-	inp = strings.ReplaceAll(inp, "’", "")
-
-	norm := strings.FieldsFunc(inp, func(r rune) bool {
-		return !unicode.IsLetter(r)
-	})
-	for len(norm) > 1 {
-		if m.Stop.IsStopWord(norm[0]) {
-			norm = norm[1:]
-			continue
+// runTest runs a single test
+// and returns the fraction of its score that it earns:
+// 0 or 1 for a test that simply passes or not
+// (see testDef.grade).
+// A network test gets a context derived from ctx
+// that expires when the test's time budget runs out.
+// If that happens,
+// the test simply does not pass,
+// unless ctx itself has also expired,
+// in which case the error is returned.
+func (m Matcher) runTest(ctx context.Context, in *matchInput, t testDef) (float32, error) {
+	if !t.network {
+		return t.call(ctx, m, in)
+	}
+
+	if t.perDomain {
+		if frac, ok := in.memo.get(t.typ); ok {
+			return frac.(float32), nil
 		}
-		if m.Stop.IsStopWord(norm[len(norm)-1]) {
-			norm = norm[:len(norm)-1]
-			continue
+	}
+
+	timeout, ok := m.TestTimeouts[t.typ]
+	if !ok {
+		timeout = defaultTestTimeout
+	}
+	testCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	frac, err := t.call(testCtx, m, in)
+	if err != nil && ctx.Err() == nil && (testCtx.Err() != nil || errors.Is(err, errBudgetExceeded)) {
+		return 0, nil
+	}
+	if err == nil && t.perDomain {
+		in.memo.set(t.typ, frac)
+	}
+	return frac, err
+}
+
+// This tells whether t's result for in,
+// or the data it fetches,
+// is already known from the tests for another reference
+// (see testDef.perDomain and testDef.memoKey),
+// so it needs no request of its own.
+func (in *matchInput) memoized(t testDef) bool {
+	if t.perDomain {
+		if _, ok := in.memo.get(t.typ); ok {
+			return true
+		}
+	}
+	if t.memoKey != nil {
+		if _, ok := in.memo.get(t.memoKey); ok {
+			return true
 		}
-		break
 	}
-	return norm
+	return false
 }
 
-func (m Matcher) doSignificantAffixesTest(domain string, re *regexp.Regexp) bool {
-	domainParts := strings.Split(domain, ".")
-	for _, part := range domainParts {
-		indexes := re.FindStringSubmatchIndex(part)
-		if len(indexes) == 0 {
-			continue
+// call runs t using its grade function if it has one,
+// and otherwise its run function.
+func (t testDef) call(ctx context.Context, m Matcher, in *matchInput) (float32, error) {
+	if t.grade != nil {
+		frac, err := t.grade(ctx, m, in)
+		if frac < 0 {
+			frac = 0
+		} else if frac > 1 {
+			frac = 1
 		}
-		if prefix := part[:indexes[0]]; prefix != "" && !m.Stop.IsStopWord(prefix) {
+		return frac, err
+	}
+	ok, err := t.run(ctx, m, in)
+	if err != nil || !ok {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func runRootPhraseTest(_ context.Context, m Matcher, in *matchInput) (float32, error) {
+	return m.subdomainScale(in, func(domain string) float32 {
+		return m.gradeRootPhrase(domain, in)
+	}), nil
+}
+
+// This reports whether domain contains the words of a multi-word root phrase,
+// in order,
+// separated only by dots, label separators, and stop words,
+// as in "coalition-security.com" and "coalition.security.example"
+// for {"coalition", "security"}.
+func (m Matcher) containsSeparatedPhrase(domain string, words []string) bool {
+	if len(words) < 2 {
+		return false
+	}
+	for i := range domain {
+		if m.hasSeparatedPhrasePrefix(domain[i:], words) {
 			return true
 		}
-		if suffix := part[indexes[1]:]; suffix != "" && !m.Stop.IsStopWord(suffix) {
+	}
+	return false
+}
+
+// This reports whether s begins with words,
+// separated as in containsSeparatedPhrase.
+func (m Matcher) hasSeparatedPhrasePrefix(s string, words []string) bool {
+	if !strings.HasPrefix(s, words[0]) {
+		return false
+	}
+	s = s[len(words[0]):]
+	if len(words) == 1 {
+		return true
+	}
+	for i := 0; i <= len(s); i++ {
+		if !strings.HasPrefix(s[i:], words[1]) {
+			continue
+		}
+		if m.isSeparatorGap(s[:i]) && m.hasSeparatedPhrasePrefix(s[i:], words[1:]) {
 			return true
 		}
-		for i := 2; i < len(indexes); i += 2 {
-			interiorWord := part[indexes[i]:indexes[i+1]]
-			if !m.Stop.IsStopWord(interiorWord) {
-				return true
+	}
+	return false
+}
+
+// This reports whether gap,
+// between two words of the root phrase in a domain,
+// contains nothing but dots, label separators, and stop words.
+func (m Matcher) isSeparatorGap(gap string) bool {
+	for _, label := range strings.Split(gap, ".") {
+		for _, token := range m.domainTokens(label) {
+			if !m.isStopWordRun(token) {
+				return false
 			}
 		}
 	}
-	return false
+	return true
 }
 
-func doWebPageRefTest(domain string, re *regexp.Regexp) (bool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second) // arbitrary timeout
-	defer cancel()
+func runAnyRootWordTest(_ context.Context, m Matcher, in *matchInput) (float32, error) {
+	return m.subdomainGrade(in, func(domain string) bool {
+		for _, label := range strings.Split(domain, ".") {
+			for _, token := range m.domainTokens(label) {
+				for _, word := range in.norm {
+					if m.CommonWords[word] {
+						continue
+					}
+					if m.containsWord(token, word) {
+						return true
+					}
+				}
+			}
+		}
+		return false
+	}), nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", "http://"+domain, nil) // TODO: try other URLs in the same domain, like /about
-	if err != nil {
-		return false, err
+func runLeadWordTest(_ context.Context, m Matcher, in *matchInput) (bool, error) {
+	if len(in.significant) == 0 {
+		return false, nil
 	}
-	client := new(http.Client)
-	resp, err := client.Do(req)
-	if err != nil {
-		return false, err
+	lead := in.significant[0]
+	for _, label := range strings.Split(in.domain, ".") {
+		for _, token := range m.domainTokens(label) {
+			if strings.HasPrefix(token, lead) {
+				return true, nil
+			}
+		}
 	}
-	defer resp.Body.Close()
+	return false, nil
+}
 
-	ctField := resp.Header.Get("Content-Type")
-	contentType, _, err := mime.ParseMediaType(ctField)
-	if err != nil {
-		return false, err
+// DefaultMaxMisspellingComparisons is the number of substring comparisons
+// the MisspelledRootPhrase test makes, at most,
+// when Matcher.MaxMisspellingComparisons is zero.
+// It's enough for a domain of maximum length (253 characters).
+const DefaultMaxMisspellingComparisons = 1500
+
+// minMisspellingLength is the length of the shortest joined root phrase
+// whose misspellings MisspelledRootPhrase looks for.
+// Two edits can turn a shorter one into any string of its length.
+const minMisspellingLength = 3
+
+func runMisspelledRootPhraseTest(_ context.Context, m Matcher, in *matchInput) (float32, error) {
+	return m.subdomainScale(in, func(domain string) float32 {
+		return m.misspellingGrade(domain, in.joined)
+	}), nil
+}
+
+// This grades the closest misspelling of joined in domain:
+// 0 if there is none,
+// otherwise 1,
+// or, if m.ScaleMisspellings is true,
+// its similarity to joined
+// (see ScaleMisspellings).
+func (m Matcher) misspellingGrade(domain, joined string) float32 {
+	d, ok := m.misspellingDistance(domain, joined)
+	if !ok {
+		return 0
+	}
+	if !m.ScaleMisspellings {
+		return 1
+	}
+	sim := 1 - float32(d)/float32(utf8.RuneCountInString(joined))
+	if sim < 0 {
+		return 0
+	}
+	return sim
+}
+
+// This finds a misspelling of joined in domain
+// and returns its edit distance from joined (1 or 2).
+// If m.ScaleMisspellings is true,
+// it is the closest one.
+// The boolean result is false if there is none,
+// or if joined is too short for a misspelling to mean anything
+// (see minMisspellingLength).
+func (m Matcher) misspellingDistance(domain, joined string) (int, bool) {
+	if len(joined) < minMisspellingLength {
+		return 0, false
+	}
+
+	limit := m.MaxMisspellingComparisons
+	if limit == 0 {
+		limit = DefaultMaxMisspellingComparisons
+	}
+	var (
+		comparisons int
+		best        int
+	)
+
+	// Check each substring of domain whose length is in [len(joined)-2..len(joined)+2]
+	// looking for ones with a Levenshtein edit distance of 1 or 2 away from joined.
+	// (An edit distance of 0 is an exact match which is covered by the testRootPhrase case.)
+	for start := 0; start < len(domain)-len(joined)+2; start++ {
+		for l := -2; l <= 2; l++ {
+			end := start + len(joined) + l
+			if end > len(domain) {
+				break
+			}
+			if limit > 0 && comparisons >= limit {
+				return best, best > 0
+			}
+			comparisons++
+			substr := domain[start:end]
+			if d := levenshtein.ComputeDistance(joined, substr); (d == 1 || d == 2) && m.keepsFixedPrefix(joined, substr) {
+				if !m.ScaleMisspellings || d == 1 {
+					return d, true
+				}
+				best = d
+			}
+		}
+	}
+	return best, best > 0
+}
+
+// This tells whether the edits transforming joined into substr
+// leave its first m.MisspellingFixedPrefix letters alone.
+func (m Matcher) keepsFixedPrefix(joined, substr string) bool {
+	if m.MisspellingFixedPrefix <= 0 {
+		return true
+	}
+	for _, op := range editScript(joined, substr) {
+		if op.pos < m.MisspellingFixedPrefix {
+			return false
+		}
 	}
-	if contentType != "text/html" {
+	return true
+}
+
+func runSignificantAffixesTest(_ context.Context, m Matcher, in *matchInput) (bool, error) {
+	domain := in.domain
+	if m.SubdomainPolicy == SubdomainIgnore {
+		domain = m.registrable(domain)
+	}
+	return m.doSignificantAffixesTest(domain, in.re), nil
+}
+
+func runHyphenatedTest(_ context.Context, _ Matcher, in *matchInput) (bool, error) {
+	if strings.Contains(in.ref, "-") {
 		return false, nil
 	}
+	for _, label := range strings.Split(in.domain, ".") {
+		if !strings.Contains(label, "-") {
+			continue
+		}
+		if strings.Contains(strings.ReplaceAll(label, "-", ""), in.joined) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
 
-	// The body is HTML. Parse it and walk it looking for a match against re.
-	tree, err := html.Parse(resp.Body)
-	if err != nil {
-		return false, err
+func (m Matcher) refNoise() []*regexp.Regexp {
+	if m.RefNoise != nil {
+		return m.RefNoise
 	}
+	return DefaultRefNoise
+}
 
-	// This comes from my htree package. It extracts plain text from HTML.
-	// See https://godoc.org/github.com/bobg/htree#Text.
-	text, err := htree.Text(tree)
-	if err != nil {
-		return false, err
+// This normalizes an input string like "The Genco Olive Oil Company, LLP"
+// to a "root phrase" like {"genco", "olive", "oil"}.
+// See Normalize.
+func (m Matcher) normalizedRootPhrase(inp string) []string {
+	return Normalize(inp, WithNoise(m.refNoise()), WithStopper(m.Stop), WithCollapse(m.collapse()), WithLegalForms(m.LegalForms), WithGeoTerms(m.GeoTerms), WithLanguage(m.Language), WithCompatibilityFolding(m.CompatibilityFolding), WithMaxLetterRun(m.MaxLetterRun), WithTrailingNumbers(m.TrailingNumbers == NumbersKeep), WithConnectors(m.Connectors))
+}
+
+func (m Matcher) collapse() map[string]string {
+	if m.Collapse == nil {
+		return defaultCollapse
+	}
+	return m.Collapse
+}
+
+func (m Matcher) doSignificantAffixesTest(domain string, re *regexp.Regexp) bool {
+	domainParts := strings.Split(domain, ".")
+	for _, part := range domainParts {
+		for i, affix := range labelAffixes(part, re) {
+			if i == 1 { // the part after the root phrase
+				var penalize bool
+				if affix, penalize = m.trailingNumberAffix(affix); penalize {
+					return true
+				}
+			}
+			if !m.isIgnorableAffix(affix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// This returns the affixes of label
+// (the part before, the part after, and the parts between the words of the root phrase),
+// where re matches the root phrase.
+// If re does not match label, the result is nil.
+func labelAffixes(label string, re *regexp.Regexp) []string {
+	indexes := re.FindStringSubmatchIndex(label)
+	if len(indexes) == 0 {
+		return nil
+	}
+	result := []string{label[:indexes[0]], label[indexes[1]:]}
+	for i := 2; i < len(indexes); i += 2 {
+		result = append(result, label[indexes[i]:indexes[i+1]])
+	}
+	return result
+}
+
+// This reports whether an affix extracted from a domain label
+// (the part before, after, or between the words of the root phrase)
+// consists entirely of stop words.
+// The affix is first split into tokens with the Matcher's DomainTokenizer.
+// Domain labels often run words together with no separators at all
+// (as in "thecoalitiongroup"),
+// so each token must further be splittable into a sequence of stop words,
+// unless the whole token is a region code like "us" or "emea"
+// (see Matcher.RegionAffixes)
+// or too short to matter
+// (see Matcher.MinAffixLength).
+// The empty string is ignorable.
+func (m Matcher) isIgnorableAffix(affix string) bool {
+	minLen := m.MinAffixLength
+	if minLen == 0 {
+		minLen = DefaultMinAffixLength
 	}
+	for _, piece := range m.domainTokens(affix) {
+		if utf8.RuneCountInString(piece) < minLen {
+			continue
+		}
+		if m.RegionAffixes != nil && m.RegionAffixes.IsStopWord(piece) {
+			continue
+		}
+		if !m.isStopWordRun(piece) {
+			return false
+		}
+	}
+	return true
+}
 
-	return re.MatchString(text), nil // TODO: inspect submatches for significant words.
+// This reports whether s is a stop word
+// (or has an empty canonical form, if m.Stop is a CanonicalStopper),
+// or a legal form when m.LegalForms is true,
+// or a geographic term when m.GeoTerms is set.
+func (m Matcher) isStopWord(s string) bool {
+	return isStopWord(m.Stop, s) || (m.LegalForms && legalForms[s]) || (m.GeoTerms != nil && m.GeoTerms.IsStopWord(s))
+}
+
+// This reports whether s can be split into one or more consecutive stop words,
+// e.g. "getthe" is "get" plus "the".
+// It needs no dictionary beyond the stopper itself.
+func (m Matcher) isStopWordRun(s string) bool {
+	return isWordRun(s, m.isStopWord)
+}
+
+// This reports whether s can be split into one or more consecutive words
+// for which isWord is true.
+func isWordRun(s string, isWord func(string) bool) bool {
+	// ok[i] tells whether s[:i] can be split into words.
+	ok := make([]bool, len(s)+1)
+	ok[0] = true
+	for i := 1; i <= len(s); i++ {
+		for j := 0; j < i; j++ {
+			if ok[j] && isWord(s[j:i]) {
+				ok[i] = true
+				break
+			}
+		}
+	}
+	return ok[len(s)]
 }
@@ -1,8 +1,15 @@
 package coalition
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestMatch(t *testing.T) {
@@ -35,6 +42,26 @@ func TestMatch(t *testing.T) {
 			domain: "coalition.com",
 			want:   5,
 		},
+		{
+			ref:    "Coalition, Inc",
+			domain: "thecoalitiongroup.com",
+			want:   40, // "the" is ignorable but "group" is a significant affix
+		},
+		{
+			ref:    "Coalition, Inc",
+			domain: "thecoalition.com",
+			want:   50, // "the" is ignorable
+		},
+		{
+			ref:    "Coalition, Inc",
+			domain: "getthecoalition-inc.com",
+			want:   50, // "getthe" is two stop words, "-inc" is a stop word after a separator
+		},
+		{
+			ref:    "Coalition, Inc",
+			domain: "thegroupcoalition.com",
+			want:   40, // "thegroup" is not made entirely of stop words
+		},
 	}
 
 	matcher := NewMatcher()
@@ -42,7 +69,637 @@ func TestMatch(t *testing.T) {
 
 	for i, c := range cases {
 		t.Run(fmt.Sprintf("case_%02d", i+1), func(t *testing.T) {
-			got, err := matcher.doMatch(c.ref, c.domain)
+			got, err := matcher.doMatch(context.Background(), c.ref, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+	cases := []struct {
+		domain  string
+		wantRaw int
+	}{
+		{domain: "coalitioninc.com", wantRaw: 50},
+		{domain: "coalition-rutabaga.com", wantRaw: 40},
+		{domain: "xyzzy.com", wantRaw: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.domain, func(t *testing.T) {
+			normalized, raw, err := matcher.Score("Coalition, Inc.", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if raw != c.wantRaw {
+				t.Errorf("got raw score %d, want %d", raw, c.wantRaw)
+			}
+			match, err := matcher.Match("Coalition, Inc.", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if normalized != match {
+				t.Errorf("got normalized score %v, want %v (from Match)", normalized, match)
+			}
+		})
+	}
+}
+
+func TestCalibration(t *testing.T) {
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+	// The scores range from -10 to 60.
+	// A raw score of 40 is 50/70 of the way.
+	const ref, domain = "Coalition, Inc.", "coalition-rutabaga.com"
+
+	linear, raw, err := matcher.Score(ref, domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw != 40 || linear != float32(50)/70 {
+		t.Fatalf("got %v (raw %d), want %v (raw 40)", linear, raw, float32(50)/70)
+	}
+
+	matcher.Calibration = func(raw, min, max int) float32 {
+		x := LinearScale(raw, min, max)
+		return x * x
+	}
+	got, err := matcher.Match(ref, domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := linear * linear; got != want {
+		t.Errorf("got %v with calibration, want %v", got, want)
+	}
+
+	// Results outside [0..1] are clamped.
+	matcher.Calibration = func(raw, min, max int) float32 { return 2 }
+	got, err = matcher.Match(ref, domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 1 {
+		t.Errorf("got %v with out-of-range calibration, want 1", got)
+	}
+}
+
+func TestTestDependencies(t *testing.T) {
+	// Each test passes according to its entry here,
+	// and records that it ran.
+	var (
+		mu   sync.Mutex
+		ran  = make(map[testType]bool)
+		pass = make(map[testType]bool)
+	)
+	def := func(typ testType, network bool, skipIfPassed, requires []testType) testDef {
+		return testDef{
+			typ:          typ,
+			network:      network,
+			skipIfPassed: skipIfPassed,
+			requires:     requires,
+			run: func(context.Context, Matcher, *matchInput) (bool, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				ran[typ] = true
+				return pass[typ], nil
+			},
+		}
+	}
+
+	tests := []testDef{
+		def(testRootPhrase, false, nil, nil),
+		def(testAnyRootWord, false, []testType{testRootPhrase}, nil),
+		def(testSignificantAffixes, false, nil, []testType{testRootPhrase}),
+		def(testWebPageRef, true, nil, nil),
+		def(testNewDomain, true, nil, []testType{testWebPageRef, testRootPhrase}),
+	}
+
+	matcher := NewMatcher()
+	matcher.Scores = map[testType]int{
+		testRootPhrase:         50,
+		testAnyRootWord:        5,
+		testSignificantAffixes: -10,
+		testWebPageRef:         20,
+		testNewDomain:          -20,
+	}
+
+	in, err := matcher.newMatchInput("Coalition", "coalition.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name        string
+		pass        []testType
+		wantRan     []testType
+		wantReasons []string
+	}{{
+		name:    "primary_passes",
+		pass:    []testType{testRootPhrase, testSignificantAffixes, testWebPageRef},
+		wantRan: []testType{testRootPhrase, testSignificantAffixes, testWebPageRef, testNewDomain},
+		wantReasons: []string{
+			"RootPhrase passed (+50)",
+			"AnyRootWord skipped because RootPhrase passed",
+			"SignificantAffixes passed (-10)",
+			"WebPageRef passed (+20)",
+			"NewDomain did not pass",
+		},
+	}, {
+		name:    "primary_fails",
+		pass:    []testType{testAnyRootWord, testSignificantAffixes, testWebPageRef, testNewDomain},
+		wantRan: []testType{testRootPhrase, testAnyRootWord, testWebPageRef},
+		wantReasons: []string{
+			"RootPhrase did not pass",
+			"AnyRootWord passed (+5)",
+			"SignificantAffixes skipped because RootPhrase did not pass",
+			"WebPageRef passed (+20)",
+			"NewDomain skipped because RootPhrase did not pass",
+		},
+	}, {
+		name:    "one_of_two_prerequisites",
+		pass:    []testType{testRootPhrase, testNewDomain},
+		wantRan: []testType{testRootPhrase, testSignificantAffixes, testWebPageRef},
+		wantReasons: []string{
+			"RootPhrase passed (+50)",
+			"AnyRootWord skipped because RootPhrase passed",
+			"SignificantAffixes did not pass",
+			"WebPageRef did not pass",
+			"NewDomain skipped because WebPageRef did not pass",
+		},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ran = make(map[testType]bool)
+			pass = make(map[testType]bool)
+			for _, typ := range c.pass {
+				pass[typ] = true
+			}
+
+			outcomes, err := matcher.runTestsDetail(context.Background(), in, tests)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var reasons []string
+			for _, o := range outcomes {
+				reasons = append(reasons, o.Reason())
+			}
+			if !reflect.DeepEqual(reasons, c.wantReasons) {
+				t.Errorf("got %q, want %q", reasons, c.wantReasons)
+			}
+
+			wantRan := make(map[testType]bool)
+			for _, typ := range c.wantRan {
+				wantRan[typ] = true
+			}
+			if !reflect.DeepEqual(ran, wantRan) {
+				t.Errorf("got ran %v, want %v", ran, wantRan)
+			}
+		})
+	}
+
+	t.Run("disabled_prerequisite", func(t *testing.T) {
+		matcher := matcher.Clone()
+		delete(matcher.Scores, testRootPhrase)
+		ran = make(map[testType]bool)
+		pass = map[testType]bool{testRootPhrase: true, testSignificantAffixes: true}
+
+		got, err := matcher.runTests(context.Background(), in, tests)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 0 {
+			t.Errorf("got %d, want 0", got)
+		}
+		if ran[testSignificantAffixes] {
+			t.Error("SignificantAffixes ran without its prerequisite")
+		}
+	})
+
+	t.Run("out_of_order", func(t *testing.T) {
+		bad := []testDef{
+			def(testSignificantAffixes, false, nil, []testType{testRootPhrase}),
+			def(testRootPhrase, false, nil, nil),
+		}
+		if _, err := matcher.runTests(context.Background(), in, bad); err == nil {
+			t.Error("got no error, want one")
+		}
+
+		cycle := []testDef{
+			def(testRootPhrase, false, []testType{testAnyRootWord}, nil),
+			def(testAnyRootWord, false, []testType{testRootPhrase}, nil),
+		}
+		if _, err := matcher.runTests(context.Background(), in, cycle); err == nil {
+			t.Error("got no error, want one")
+		}
+	})
+
+	t.Run("builtin_order", func(t *testing.T) {
+		index := make(map[testType]int)
+		for i, t := range builtinTests {
+			index[t.typ] = i
+		}
+		if err := checkTestOrder(builtinTests, index); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestTestTimeouts(t *testing.T) {
+	// The slow test runs until its context expires.
+	slow := testDef{
+		typ:     testWebPageRef,
+		network: true,
+		run: func(ctx context.Context, _ Matcher, _ *matchInput) (bool, error) {
+			<-ctx.Done()
+			return false, ctx.Err()
+		},
+	}
+	fast := testDef{
+		typ:     testJSONLDOrganization,
+		network: true,
+		run: func(ctx context.Context, _ Matcher, _ *matchInput) (bool, error) {
+			select {
+			case <-ctx.Done():
+				return false, ctx.Err()
+			case <-time.After(20 * time.Millisecond):
+				return true, nil
+			}
+		},
+	}
+
+	matcher := NewMatcher()
+	matcher.Scores = map[testType]int{testWebPageRef: 50, testJSONLDOrganization: 20}
+	matcher.TestTimeouts = map[testType]time.Duration{
+		testWebPageRef:         50 * time.Millisecond,
+		testJSONLDOrganization: time.Second,
+	}
+
+	in, err := matcher.newMatchInput("Coalition", "coalition.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("budgets", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+
+		// Without its budget, the slow test would use up all of ctx and starve the fast one.
+		got, err := matcher.runTests(ctx, in, []testDef{slow, fast})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 20 {
+			t.Errorf("got %d, want 20", got)
+		}
+	})
+
+	t.Run("overall", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// When the overall context expires, that's an error.
+		_, err := matcher.runTests(ctx, in, []testDef{slow, fast})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+}
+
+func TestRunTestsConcurrently(t *testing.T) {
+	delayed := func(typ testType, result bool) testDef {
+		return testDef{
+			typ:     typ,
+			network: true,
+			run: func(ctx context.Context, _ Matcher, _ *matchInput) (bool, error) {
+				select {
+				case <-ctx.Done():
+					return false, ctx.Err()
+				case <-time.After(100 * time.Millisecond):
+					return result, nil
+				}
+			},
+		}
+	}
+
+	// This one must not run, since the test it's gated on passes.
+	gated := testDef{
+		typ:          testAnyRootWord,
+		skipIfPassed: []testType{testWebPageRef},
+		run: func(context.Context, Matcher, *matchInput) (bool, error) {
+			return false, errors.New("gated test ran")
+		},
+	}
+
+	tests := []testDef{
+		delayed(testWebPageRef, true),
+		delayed(testJSONLDOrganization, true),
+		delayed(testSignificantAffixes, true),
+		gated,
+	}
+
+	matcher := NewMatcher()
+	matcher.Scores = map[testType]int{
+		testWebPageRef:         50,
+		testJSONLDOrganization: 20,
+		testSignificantAffixes: -10,
+		testAnyRootWord:        5,
+	}
+
+	in, err := matcher.newMatchInput("Coalition", "coalition.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		start := time.Now()
+		got, err := matcher.runTests(context.Background(), in, tests)
+		if err != nil {
+			t.Fatal(err)
+		}
+		elapsed := time.Since(start)
+
+		if got != 60 {
+			t.Errorf("run %d: got %d, want 60", i, got)
+		}
+
+		// Run sequentially, the three delayed tests would take at least 300ms.
+		if elapsed >= 250*time.Millisecond {
+			t.Errorf("run %d: took %s, want less than 250ms", i, elapsed)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	orig := NewMatcher()
+	orig.TestTimeouts = map[testType]time.Duration{testWebPageRef: time.Second}
+
+	clone := orig.Clone()
+	clone.Scores[testRootPhrase] = 70
+	delete(clone.Scores, testWebPageRef)
+	clone.TestTimeouts[testWebPageRef] = time.Minute
+
+	if got := orig.Scores[testRootPhrase]; got != 50 {
+		t.Errorf("got original RootPhrase score %d, want 50", got)
+	}
+	if got := orig.Scores[testWebPageRef]; got != 50 {
+		t.Errorf("got original WebPageRef score %d, want 50", got)
+	}
+	if got := orig.TestTimeouts[testWebPageRef]; got != time.Second {
+		t.Errorf("got original WebPageRef timeout %s, want %s", got, time.Second)
+	}
+	if clone.limits != orig.limits {
+		t.Error("clone does not share rate limiters with original")
+	}
+
+	// Slices are copied too.
+	orig.PageRegions = []PageRegion{{Tag: "footer"}}
+	orig.ResponseHeaders = []string{"Server"}
+	orig.NewDomainWeights = []DomainAgeWeight{{MaxAge: time.Hour, Weight: 1}}
+	clone = orig.Clone()
+	clone.PageRegions[0].Tag = "header"
+	clone.ResponseHeaders[0] = "X-Powered-By"
+	clone.NewDomainWeights[0].Weight = 0.5
+	if got := orig.PageRegions[0].Tag; got != "footer" {
+		t.Errorf("got original PageRegions tag %s, want footer", got)
+	}
+	if got := orig.ResponseHeaders[0]; got != "Server" {
+		t.Errorf("got original ResponseHeaders %s, want Server", got)
+	}
+	if got := orig.NewDomainWeights[0].Weight; got != 1 {
+		t.Errorf("got original NewDomainWeights weight %v, want 1", got)
+	}
+}
+
+func TestWithScore(t *testing.T) {
+	orig := NewMatcher()
+
+	m := orig.WithoutTest(TestWebPageRef).WithScore(TestRootPhrase, 70).WithScore(TestHyphenated, -5)
+
+	want := map[testType]int{
+		testRootPhrase:           70,
+		testAnyRootWord:          5,
+		testMisspelledRootPhrase: 5,
+		testSignificantAffixes:   -10,
+		testHyphenated:           -5,
+	}
+	if !reflect.DeepEqual(m.Scores, want) {
+		t.Errorf("got %v, want %v", m.Scores, want)
+	}
+
+	// The receiver is unchanged.
+	if !reflect.DeepEqual(orig.Scores, defaultMatcher.Scores) {
+		t.Errorf("original changed to %v", orig.Scores)
+	}
+	if got := NewMatcher().Scores[testRootPhrase]; got != 50 {
+		t.Errorf("default changed: got RootPhrase score %d, want 50", got)
+	}
+
+	// Every exported name is a test.
+	for _, name := range testNames {
+		NewMatcher().WithScore(TestName(name), 1)
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("got no panic for unknown test")
+			}
+		}()
+		orig.WithScore("Bogus", 1)
+	})
+}
+
+func TestLeadWordTest(t *testing.T) {
+	cases := []struct {
+		domain            string
+		wantLead, wantAny bool
+	}{
+		{domain: "coalition-foo.com", wantLead: true, wantAny: true},
+		{domain: "coalitionfoo.com", wantLead: true, wantAny: true},
+		{domain: "security-foo.com", wantLead: false, wantAny: true},
+		{domain: "foo.com", wantLead: false, wantAny: false},
+	}
+
+	matcher := NewMatcher()
+	matcher.Scores = map[testType]int{testLeadWord: 10}
+
+	anyMatcher := NewMatcher()
+	anyMatcher.Scores = map[testType]int{testAnyRootWord: 10}
+
+	for _, c := range cases {
+		t.Run(c.domain, func(t *testing.T) {
+			got, err := matcher.doMatch(context.Background(), "Coalition Security", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (got > 0) != c.wantLead {
+				t.Errorf("LeadWord: got %d, want passed %v", got, c.wantLead)
+			}
+
+			got, err = anyMatcher.doMatch(context.Background(), "Coalition Security", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (got > 0) != c.wantAny {
+				t.Errorf("AnyRootWord: got %d, want passed %v", got, c.wantAny)
+			}
+		})
+	}
+
+	t.Run("stop_word", func(t *testing.T) {
+		// The leading "the" is not significant.
+		got, err := matcher.doMatch(context.Background(), "The Coalition", "coalition-foo.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 10 {
+			t.Errorf("got %d, want 10", got)
+		}
+	})
+}
+
+func TestLeadingThe(t *testing.T) {
+	cases := []struct {
+		ref, domain string
+	}{
+		{ref: "The North Face", domain: "thenorthface.com"},
+		{ref: "The North Face", domain: "northface.com"},
+		{ref: "The Coalition", domain: "coalition.com"},
+		{ref: "The Coalition", domain: "thecoalition.com"},
+		{ref: "Coalition", domain: "thecoalition.com"},
+	}
+
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+	for _, c := range cases {
+		t.Run(c.ref+"_"+c.domain, func(t *testing.T) {
+			got, err := matcher.doMatch(context.Background(), c.ref, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != 50 {
+				t.Errorf("got %d, want 50", got)
+			}
+		})
+	}
+
+	t.Run("web", func(t *testing.T) {
+		srv, domain := newTestServer("text/plain", "shop the north face")
+		defer srv.Close()
+
+		got, err := NewMatcher().doMatch(context.Background(), "The North Face", domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 50 {
+			t.Errorf("got %d, want 50", got)
+		}
+	})
+}
+
+func TestRepeatedWords(t *testing.T) {
+	cases := []struct {
+		domain string
+		want   []string
+	}{
+		{
+			domain: "duckduckgo.com",
+			want: []string{
+				"RootPhrase passed (+50)",
+				"AnyRootWord skipped because RootPhrase passed",
+				"MisspelledRootPhrase skipped because RootPhrase passed",
+				"SignificantAffixes did not pass",
+			},
+		},
+		{
+			// One "duck" is not enough for the root phrase.
+			domain: "duckgo.com",
+			want: []string{
+				"RootPhrase did not pass",
+				"AnyRootWord passed (+5)",
+				"MisspelledRootPhrase did not pass",
+				"SignificantAffixes did not pass",
+			},
+		},
+		{
+			// An extra "duck" is a significant affix.
+			domain: "duckduckduckgo.com",
+			want: []string{
+				"RootPhrase passed (+50)",
+				"AnyRootWord skipped because RootPhrase passed",
+				"MisspelledRootPhrase skipped because RootPhrase passed",
+				"SignificantAffixes passed (-10)",
+			},
+		},
+	}
+
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+	for _, c := range cases {
+		t.Run(c.domain, func(t *testing.T) {
+			detail, err := matcher.MatchDetail(context.Background(), "Duck Duck Go, Inc.", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := detail.Reasons(); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+
+	t.Run("web", func(t *testing.T) {
+		for _, body := range []string{"Duck Duck Go", "duck go"} {
+			t.Run(body, func(t *testing.T) {
+				srv, domain := newTestServer("text/plain", strings.ToLower(body))
+				defer srv.Close()
+
+				got, err := NewMatcher().doMatch(context.Background(), "Duck Duck Go", domain)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := 0
+				if body == "Duck Duck Go" {
+					want = 50
+				}
+				if got != want {
+					t.Errorf("got %d, want %d", got, want)
+				}
+			})
+		}
+	})
+}
+
+func TestHyphenatedTest(t *testing.T) {
+	cases := []struct {
+		ref, domain string
+		want        int
+	}{
+		{ref: "Coalition", domain: "coalition.com", want: 50},
+		{ref: "Coalition", domain: "coali-tion.com", want: 0},         // misspelled root phrase match, less the hyphen penalty
+		{ref: "Coalition", domain: "coalition-inc.com", want: 45},     // "-inc" is ignorable, but the hyphen is not
+		{ref: "Coalition", domain: "my-site.coalition.com", want: 50}, // the hyphen is not near the root phrase
+		{ref: "Coca-Cola", domain: "coca-cola.com", want: 50},         // the input has a hyphen too
+	}
+
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+	matcher.Scores[testHyphenated] = -5
+
+	for _, c := range cases {
+		t.Run(c.domain, func(t *testing.T) {
+			got, err := matcher.doMatch(context.Background(), c.ref, c.domain)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -51,4 +708,557 @@ func TestMatch(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("default_off", func(t *testing.T) {
+		matcher := NewMatcher()
+		delete(matcher.Scores, testWebPageRef)
+		got, err := matcher.doMatch(context.Background(), "Coalition", "coali-tion.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 5 {
+			t.Errorf("got %d, want 5", got)
+		}
+	})
+}
+
+func TestPattern(t *testing.T) {
+	cases := []struct {
+		ref, want string
+	}{
+		{ref: "Coalition, Inc", want: "coalition"},
+		{ref: "Coalition Security, Inc.", want: "coalition(.*)security"},
+		{ref: "Sanford and Son", want: "sanford(.*)and(.*)son"},
+		{ref: "The Genco Olive Oil Company, LLC", want: "genco(.*)olive(.*)oil(.*)company"},
+	}
+
+	matcher := NewMatcher()
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			got, err := matcher.Pattern(c.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %s, want %s", got, c.want)
+			}
+		})
+	}
+}
+
+// longDomain is a domain of the maximum length, 253 characters,
+// with a misspelling of "coalition" at the very end.
+var longDomain = strings.Repeat(strings.Repeat("x", 62)+".", 3) + strings.Repeat("x", 51) + ".colition.com"
+
+func TestMaxMisspellingComparisons(t *testing.T) {
+	if len(longDomain) != 253 {
+		t.Fatalf("long domain has length %d, want 253", len(longDomain))
+	}
+
+	cases := []struct {
+		limit int
+		want  int
+	}{
+		{limit: 0, want: 5},   // the default suffices
+		{limit: -1, want: 5},  // no cap
+		{limit: 100, want: 0}, // bail out before reaching the misspelling
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("limit_%d", c.limit), func(t *testing.T) {
+			matcher := NewMatcher()
+			delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+			matcher.MaxMisspellingComparisons = c.limit
+
+			got, err := matcher.doMatch(context.Background(), "Coalition, Inc", longDomain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func BenchmarkMisspelledRootPhrase(b *testing.B) {
+	matcher := NewMatcher()
+	in, err := matcher.newMatchInput("Coalition, Inc", longDomain)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		if _, err := runMisspelledRootPhraseTest(context.Background(), matcher, in); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestIPAddress(t *testing.T) {
+	cases := []struct {
+		domain, url string
+	}{
+		{domain: "192.0.2.1", url: "http://192.0.2.1/"},
+		{domain: "2001:db8::1", url: "http://[2001:db8::1]/"},
+		{domain: "[2001:db8::1]:8443", url: "http://[2001:db8::1]:8443/"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.domain, func(t *testing.T) {
+			for _, skip := range []bool{false, true} {
+				rec := &RequestRecorder{
+					Responses: map[string]CannedResponse{
+						c.url: {ContentType: "text/html", Body: "<html><body>welcome to coalition</body></html>"},
+					},
+				}
+
+				matcher := NewMatcher()
+				matcher.Client = &http.Client{Transport: rec}
+				matcher.SkipIPNetworkTests = skip
+
+				detail, err := matcher.MatchDetail(context.Background(), "Coalition", c.domain)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				want := []string{
+					"RootPhrase skipped because the domain is an IP address",
+					"AnyRootWord skipped because the domain is an IP address",
+					"MisspelledRootPhrase skipped because the domain is an IP address",
+					"SignificantAffixes skipped because the domain is an IP address",
+					"WebPageRef passed (+50)",
+				}
+				if skip {
+					want[4] = "WebPageRef skipped because the domain is an IP address"
+				}
+				if got := detail.Reasons(); !reflect.DeepEqual(got, want) {
+					t.Errorf("skip %v: got %v, want %v", skip, got, want)
+				}
+				if gotFetch := len(rec.Requests()) > 0; gotFetch == skip {
+					t.Errorf("skip %v: got fetch %v", skip, gotFetch)
+				}
+			}
+		})
+	}
+}
+
+func TestMinAffixLength(t *testing.T) {
+	cases := []struct {
+		domain string
+		minLen int
+		want   int
+	}{
+		{domain: "coalitionx.com", want: 50},
+		{domain: "x-coalition.com", want: 50},
+		{domain: "coalitionxy.com", want: 40},
+		{domain: "coalitionpro.com", want: 40},
+		{domain: "coalitionx.com", minLen: -1, want: 40},
+		{domain: "coalitionxy.com", minLen: 3, want: 50},
+		{domain: "coalitionpro.com", minLen: 3, want: 40},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%s_%d", c.domain, c.minLen), func(t *testing.T) {
+			matcher := NewMatcher()
+			delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+			matcher.MinAffixLength = c.minLen
+
+			got, err := matcher.doMatch(context.Background(), "Coalition", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestWWW(t *testing.T) {
+	cases := []struct {
+		ref, domain string
+	}{
+		{ref: "Coalition, Inc", domain: "coalitioninc.com"},
+		{ref: "Coalition, Inc", domain: "coalition-rutabaga.com"},
+		{ref: "Coalition, Inc", domain: "colition.com"},
+		{ref: "WWW Coalition", domain: "coalition.com"},
+		{ref: "Coalition", domain: "coalition.com"},
+	}
+
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+	for _, c := range cases {
+		t.Run(c.domain, func(t *testing.T) {
+			want, err := matcher.doMatch(context.Background(), c.ref, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, prefix := range []string{"www.", "WWW."} {
+				got, err := matcher.doMatch(context.Background(), c.ref, prefix+c.domain)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != want {
+					t.Errorf("got %d for %s%s, want %d", got, prefix, c.domain, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCollapse(t *testing.T) {
+	cases := []struct {
+		name     string
+		collapse map[string]string
+		want     string
+	}{
+		{name: "default", want: "dangelos(.*)pizza"},
+		{name: "none", collapse: map[string]string{}, want: "d(.*)angelo(.*)s(.*)pizza"},
+		{name: "split", collapse: map[string]string{"'": " ", "’": " "}, want: "d(.*)angelo(.*)s(.*)pizza"},
+		{name: "mixed", collapse: map[string]string{"'s": "s", "'": " "}, want: "d(.*)angelos(.*)pizza"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			matcher := NewMatcher()
+			if c.collapse != nil {
+				matcher.Collapse = c.collapse
+			}
+			got, err := matcher.Pattern("D'Angelo's Pizza")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %s, want %s", got, c.want)
+			}
+		})
+	}
+
+	t.Run("zero", func(t *testing.T) {
+		// A nil Collapse means the default, even without NewMatcher.
+		matcher := Matcher{Stop: defaultStopper}
+		got, err := matcher.Pattern("D'Angelo's Pizza")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "dangelos(.*)pizza"; got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	})
+}
+
+func TestTLDWeights(t *testing.T) {
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+	matcher.TLDWeights = map[string]float32{
+		"bank":  1.2,
+		"xyz":   0.5,
+		"uk":    0.8,
+		"co.uk": 1.1,
+	}
+
+	cases := []struct {
+		domain string
+		want   int
+	}{
+		{domain: "coalition.com", want: 50},
+		{domain: "coalition.bank", want: 60},
+		{domain: "coalition.xyz", want: 25},
+		{domain: "coalition.org.uk", want: 40},
+		{domain: "coalition.co.uk", want: 55},
+		{domain: "coalition-rutabaga.xyz", want: 20},
+	}
+
+	for _, c := range cases {
+		t.Run(c.domain, func(t *testing.T) {
+			got, err := matcher.doMatch(context.Background(), "Coalition, Inc", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+
+	bank, err := matcher.Match("Coalition, Inc", "coalition.bank")
+	if err != nil {
+		t.Fatal(err)
+	}
+	xyz, err := matcher.Match("Coalition, Inc", "coalition.xyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bank <= xyz {
+		t.Errorf("got %f for .bank, want more than %f for .xyz", bank, xyz)
+	}
+}
+
+func TestSettled(t *testing.T) {
+	rec := &RequestRecorder{
+		Responses: map[string]CannedResponse{
+			"http://coalition.com/": {ContentType: "text/html", Body: "<html><body>coalition</body></html>"},
+		},
+	}
+
+	matcher := NewMatcher()
+	matcher.Client = &http.Client{Transport: rec}
+
+	cases := []struct {
+		name      string
+		weight    float32
+		want      float32
+		wantFetch bool
+	}{
+		// RootPhrase alone, tripled, exceeds the maximum score,
+		// so WebPageRef can't change the result.
+		{name: "settled", weight: 3, want: 1},
+
+		// RootPhrase alone, doubled, doesn't,
+		// so WebPageRef must run.
+		{name: "unsettled", weight: 2, want: 1, wantFetch: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec.Reset()
+			matcher.TLDWeights = map[string]float32{"com": c.weight}
+
+			got, err := matcher.MatchContext(context.Background(), "Coalition", "coalition.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+			if gotFetch := len(rec.Requests()) > 0; gotFetch != c.wantFetch {
+				t.Errorf("got fetch %v, want %v", gotFetch, c.wantFetch)
+			}
+		})
+	}
+
+	t.Run("negative_tests_run", func(t *testing.T) {
+		rec.Reset()
+		matcher := matcher.Clone()
+		matcher.TLDWeights = map[string]float32{"com": 3}
+
+		detail, err := matcher.MatchDetail(context.Background(), "Coalition", "coalition-sucks.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{
+			"RootPhrase passed (+50)",
+			"AnyRootWord skipped because RootPhrase passed",
+			"MisspelledRootPhrase skipped because RootPhrase passed",
+			"SignificantAffixes passed (-10)",
+			"WebPageRef skipped because it could not change the result",
+		}
+		if got := detail.Reasons(); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+}
+
+func TestMisspellingFixedPrefix(t *testing.T) {
+	cases := []struct {
+		domain string
+		fixed  int
+		want   int
+	}{
+		{domain: "xoalition.com", want: 5},
+		{domain: "xoalition.com", fixed: 1, want: 0},
+		{domain: "oalition.com", fixed: 1, want: 0},
+		{domain: "coallition.com", fixed: 1, want: 5},
+		{domain: "coalitoin.com", fixed: 3, want: 5},
+		{domain: "cpalition.com", fixed: 1, want: 5},
+		{domain: "cpalition.com", fixed: 2, want: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%s_%d", c.domain, c.fixed), func(t *testing.T) {
+			matcher := NewMatcher()
+			delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+			matcher.MisspellingFixedPrefix = c.fixed
+
+			got, err := matcher.doMatch(context.Background(), "Coalition", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestScaleMisspellings(t *testing.T) {
+	cases := []struct {
+		ref, domain string
+		scale       bool
+		want        int
+	}{
+		{ref: "Coalition", domain: "coallition.com", want: 20},
+		{ref: "Coalition", domain: "colitin.com", want: 20},
+		{ref: "Coalition", domain: "coallition.com", scale: true, want: 18},                // 20 * (1 - 1/9)
+		{ref: "Coalition", domain: "colitin.com", scale: true, want: 16},                   // 20 * (1 - 2/9)
+		{ref: "Coalition", domain: "colitin-coallition.com", scale: true, want: 18},        // the closer one counts
+		{ref: "Coalition Rutabaga", domain: "coalitionrutabga.com", scale: true, want: 19}, // 20 * (1 - 1/17)
+		{ref: "Coalition Rutabaga", domain: "colitionrutabga.com", scale: true, want: 18},  // 20 * (1 - 2/17)
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%s_%v", c.domain, c.scale), func(t *testing.T) {
+			matcher := NewMatcher()
+			matcher.Scores = map[testType]int{testMisspelledRootPhrase: 20}
+			matcher.ScaleMisspellings = c.scale
+
+			got, err := matcher.doMatch(context.Background(), c.ref, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestMinPassingTests(t *testing.T) {
+	cases := []struct {
+		ref, page  string
+		minPassing int
+		want       float32
+		wantTooFew bool
+	}{
+		// The scores range from -10 to 110.
+		{ref: "Coalition", page: "welcome", want: float32(60) / 120},                                               // RootPhrase alone
+		{ref: "Coalition", page: "welcome", minPassing: 2, want: 0, wantTooFew: true},                              // RootPhrase alone
+		{ref: "Coalition", page: "welcome to coalition", minPassing: 2, want: float32(110) / 120},                  // RootPhrase and WebPageRef
+		{ref: "Coalition", page: "welcome to coalition", minPassing: 3, want: 0, wantTooFew: true},                 // RootPhrase and WebPageRef
+		{ref: "Coalition Rutabaga", page: "welcome to coalition rutabaga", minPassing: 2, want: float32(65) / 120}, // AnyRootWord and WebPageRef
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case_%02d", i+1), func(t *testing.T) {
+			rec := &RequestRecorder{
+				Responses: map[string]CannedResponse{
+					"http://coalition.com/": {ContentType: "text/html", Body: "<html><body>" + c.page + "</body></html>"},
+				},
+			}
+
+			matcher := NewMatcher()
+			matcher.Client = &http.Client{Transport: rec}
+			matcher.MinPassingTests = c.minPassing
+
+			detail, err := matcher.MatchDetail(context.Background(), c.ref, "coalition.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if detail.Score != c.want {
+				t.Errorf("got %v, want %v (%v)", detail.Score, c.want, detail.Reasons())
+			}
+			if detail.TooFewPassed != c.wantTooFew {
+				t.Errorf("got TooFewPassed %v, want %v", detail.TooFewPassed, c.wantTooFew)
+			}
+		})
+	}
+}
+
+func TestSeparatedRootPhrase(t *testing.T) {
+	cases := []struct {
+		domain string
+		policy SubdomainPolicy
+		want   bool
+	}{
+		{domain: "coalitionsecurity.com", want: true},
+		{domain: "coalition-security.com", want: true},
+		{domain: "coalition_security.com", want: true},
+		{domain: "coalition--security.com", want: true},
+		{domain: "coalition.security.example", want: true},
+		{domain: "coalition-and-security.com", want: true},
+		{domain: "coalition-cyber-security.com", want: false},
+		{domain: "security-coalition.com", want: false},
+		{domain: "coalition.security.example", policy: SubdomainIgnore, want: false},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%s_%d", c.domain, c.policy), func(t *testing.T) {
+			matcher := NewMatcher()
+			delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+			matcher.SubdomainPolicy = c.policy
+
+			_, detail, err := matcher.doMatchDetail(context.Background(), "Coalition Security", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, o := range detail.Outcomes {
+				if o.Test != "RootPhrase" {
+					continue
+				}
+				if o.Passed != c.want {
+					t.Errorf("got passed %v, want %v", o.Passed, c.want)
+				}
+				if c.want && o.Score != 50 {
+					t.Errorf("got score %d, want the full 50", o.Score)
+				}
+			}
+		})
+	}
+}
+
+func TestConfidence(t *testing.T) {
+	graded := func(typ testType, frac float32) testDef {
+		return testDef{
+			typ: typ,
+			grade: func(context.Context, Matcher, *matchInput) (float32, error) {
+				return frac, nil
+			},
+		}
+	}
+
+	tests := []testDef{
+		graded(testRootPhrase, 0.25),
+		graded(testAnyRootWord, 1.5),
+		graded(testMisspelledRootPhrase, -1),
+		{typ: testLeadWord, run: func(context.Context, Matcher, *matchInput) (bool, error) { return true, nil }},
+	}
+
+	matcher := NewMatcher()
+	matcher.Scores = map[testType]int{
+		testRootPhrase:           50,
+		testAnyRootWord:          10,
+		testMisspelledRootPhrase: 10,
+		testLeadWord:             4,
+	}
+
+	in, err := matcher.newMatchInput("Coalition", "coalition.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	outcomes, err := matcher.runTestsDetail(context.Background(), in, tests)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		confidence float32
+		score      int
+	}{
+		{confidence: 0.25, score: 13}, // a partial grade earns part of the score, rounded
+		{confidence: 1, score: 10},    // clamped to 1
+		{confidence: 0, score: 0},     // clamped to 0
+		{confidence: 1, score: 4},     // a test that simply passes
+	}
+	if len(outcomes) != len(want) {
+		t.Fatalf("got %d outcomes, want %d", len(outcomes), len(want))
+	}
+	for i, o := range outcomes {
+		if o.Confidence != want[i].confidence || o.Score != want[i].score {
+			t.Errorf("%s: got confidence %v, score %d; want %v, %d", o.Test, o.Confidence, o.Score, want[i].confidence, want[i].score)
+		}
+	}
+	if got := sumOutcomes(outcomes); got != 27 {
+		t.Errorf("got total %d, want 27", got)
+	}
 }
@@ -0,0 +1,132 @@
+package coalition
+
+import "sync"
+
+// Metrics is told about the work a Matcher does,
+// for monitoring.
+// Its methods may be called concurrently.
+// See Matcher.Metrics.
+type Metrics interface {
+	// Match is called when a match finishes,
+	// with its result or the error that prevented one.
+	// A call to MatchAny counts as a single match.
+	// A call to MatchAtLeast,
+	// whose score may be inexact,
+	// is not counted,
+	// nor is one to QuickScore.
+	Match(score float32, err error)
+
+	// Fetch is called after each outbound web request,
+	// with the error that prevented a response, if any.
+	// Pages served from Matcher.PageCache are not counted.
+	Fetch(err error)
+
+	// CacheHit and CacheMiss are called when a page is,
+	// or is not,
+	// found in Matcher.PageCache.
+	CacheHit()
+	CacheMiss()
+}
+
+// Counters is a simple implementation of Metrics
+// that counts the calls to its methods.
+// Its zero value is ready to use.
+// It is safe for concurrent use.
+type Counters struct {
+	mu sync.Mutex
+
+	matches, matchErrors   int64
+	fetches, fetchErrors   int64
+	cacheHits, cacheMisses int64
+	scoreSum               float64
+}
+
+// Match implements Metrics.
+func (c *Counters) Match(score float32, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.matches++
+	if err != nil {
+		c.matchErrors++
+		return
+	}
+	c.scoreSum += float64(score)
+}
+
+// Fetch implements Metrics.
+func (c *Counters) Fetch(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fetches++
+	if err != nil {
+		c.fetchErrors++
+	}
+}
+
+// CacheHit implements Metrics.
+func (c *Counters) CacheHit() {
+	c.mu.Lock()
+	c.cacheHits++
+	c.mu.Unlock()
+}
+
+// CacheMiss implements Metrics.
+func (c *Counters) CacheMiss() {
+	c.mu.Lock()
+	c.cacheMisses++
+	c.mu.Unlock()
+}
+
+// CounterValues is a snapshot of a Counters.
+type CounterValues struct {
+	// Matches is the number of matches performed,
+	// and MatchErrors the number of those that failed.
+	Matches, MatchErrors int64
+
+	// Fetches is the number of outbound web requests made,
+	// and FetchErrors the number of those that failed.
+	Fetches, FetchErrors int64
+
+	// CacheHits and CacheMisses count lookups in Matcher.PageCache.
+	CacheHits, CacheMisses int64
+
+	// AverageScore is the mean result of the matches that did not fail,
+	// or zero if there are none.
+	AverageScore float32
+}
+
+// Values returns the current values of c.
+func (c *Counters) Values() CounterValues {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v := CounterValues{
+		Matches:     c.matches,
+		MatchErrors: c.matchErrors,
+		Fetches:     c.fetches,
+		FetchErrors: c.fetchErrors,
+		CacheHits:   c.cacheHits,
+		CacheMisses: c.cacheMisses,
+	}
+	if n := c.matches - c.matchErrors; n > 0 {
+		v.AverageScore = float32(c.scoreSum / float64(n))
+	}
+	return v
+}
+
+// noMetrics is the Metrics used when Matcher.Metrics is nil.
+type noMetrics struct{}
+
+func (noMetrics) Match(float32, error) {}
+func (noMetrics) Fetch(error)          {}
+func (noMetrics) CacheHit()            {}
+func (noMetrics) CacheMiss()           {}
+
+func (m Matcher) metrics() Metrics {
+	if m.Metrics != nil {
+		return m.Metrics
+	}
+	return noMetrics{}
+}
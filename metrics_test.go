@@ -0,0 +1,51 @@
+package coalition
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCounters(t *testing.T) {
+	srv, domain := newTestServer("text/html", "<html><body>coalition</body></html>")
+	defer srv.Close()
+
+	down, downDomain := newTestServer("text/html", "")
+	down.Close() // Nothing is listening now.
+
+	var counters Counters
+	matcher := NewMatcher()
+	matcher.Metrics = &counters
+	matcher.PageCache = NewMemoryPageCache(time.Minute, 0)
+
+	var sum float32
+	for i := 0; i < 3; i++ {
+		score, err := matcher.Match("Coalition", domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum += score
+	}
+	score, err := matcher.Match("Acme", domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum += score
+
+	if _, err := matcher.Match("Coalition", downDomain); err == nil {
+		t.Fatal("got no error, want one")
+	}
+
+	got := counters.Values()
+	want := CounterValues{
+		Matches:      5,
+		MatchErrors:  1,
+		Fetches:      2, // one for domain, then the cache; one for downDomain
+		FetchErrors:  1,
+		CacheHits:    3,
+		CacheMisses:  2,
+		AverageScore: sum / 4,
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
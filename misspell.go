@@ -0,0 +1,109 @@
+package coalition
+
+import "strings"
+
+// DefaultMaxMisspellingCandidates is the number of variants
+// MisspellingCandidates returns, at most,
+// when Matcher.MaxMisspellingCandidates is zero.
+// A root phrase of ten letters has roughly 750 variants at edit distance 1
+// but several hundred thousand at edit distance 2.
+const DefaultMaxMisspellingCandidates = 10000
+
+// misspellingAlphabet holds the characters that may be substituted or inserted
+// in a misspelling: those permitted in a domain label.
+const misspellingAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789-"
+
+// MisspellingCandidates returns the misspellings of the root phrase of ref
+// that the MisspelledRootPhrase test accepts:
+// the variants of the joined root phrase
+// (as in "coalitioninc" for "Coalition Inc")
+// at a Levenshtein edit distance of 1 or 2,
+// honoring MisspellingFixedPrefix.
+// Their characters are those permitted in a domain label.
+// This is useful for building a list of likely typosquatting domains.
+//
+// The variants at distance 1 come first.
+// There are at most MaxMisspellingCandidates of them
+// (see DefaultMaxMisspellingCandidates).
+func (m Matcher) MisspellingCandidates(ref string) []string {
+	joined := strings.Join(m.normalizedRootPhrase(ref), "")
+	if joined == "" {
+		return nil
+	}
+
+	limit := m.MaxMisspellingCandidates
+	if limit == 0 {
+		limit = DefaultMaxMisspellingCandidates
+	}
+
+	var (
+		result []string
+		seen   = map[string]bool{joined: true}
+		dist1  []string
+	)
+
+	// Add adds s to result if it's new and acceptable,
+	// and reports whether there is room for more.
+	add := func(s string, keep *[]string) bool {
+		if s == "" || seen[s] {
+			return true
+		}
+		seen[s] = true
+		if keep != nil {
+			*keep = append(*keep, s)
+		}
+		if m.keepsFixedPrefix(joined, s) {
+			result = append(result, s)
+		}
+		return limit < 0 || len(result) < limit
+	}
+
+	if !edits1(joined, func(s string) bool { return add(s, &dist1) }) {
+		return result
+	}
+	for _, s := range dist1 {
+		if !edits1(s, func(s string) bool { return add(s, nil) }) {
+			break
+		}
+	}
+	return result
+}
+
+// This calls f on each string at a Levenshtein edit distance of 1 from s
+// (possibly with repeats),
+// using characters from misspellingAlphabet,
+// until f returns false.
+// It reports whether f always returned true.
+func edits1(s string, f func(string) bool) bool {
+	runes := []rune(s)
+
+	// Deletions.
+	for i := range runes {
+		if !f(string(runes[:i]) + string(runes[i+1:])) {
+			return false
+		}
+	}
+
+	// Substitutions.
+	for i, r := range runes {
+		for _, c := range misspellingAlphabet {
+			if c == r {
+				continue
+			}
+			if !f(string(runes[:i]) + string(c) + string(runes[i+1:])) {
+				return false
+			}
+		}
+	}
+
+	// Insertions.
+	for i := 0; i <= len(runes); i++ {
+		for _, c := range misspellingAlphabet {
+			if !f(string(runes[:i]) + string(c) + string(runes[i:])) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,117 @@
+package coalition
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMisspellingCandidates(t *testing.T) {
+	matcher := NewMatcher()
+	matcher.MaxMisspellingCandidates = -1
+
+	got := matcher.MisspellingCandidates("Coalition, Inc.")
+	have := make(map[string]bool)
+	for _, s := range got {
+		if have[s] {
+			t.Errorf("duplicate candidate %s", s)
+		}
+		have[s] = true
+	}
+
+	for _, want := range []string{"colition", "coaltion", "coallition", "koalition", "coalitlon", "colitlon", "coalition-"} {
+		if !have[want] {
+			t.Errorf("missing candidate %s", want)
+		}
+	}
+	for _, notWant := range []string{"coalition", "clitn", "coalitionxyz"} {
+		if have[notWant] {
+			t.Errorf("unexpected candidate %s", notWant)
+		}
+	}
+
+	// Every candidate is one the MisspelledRootPhrase test accepts.
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+	for _, s := range got[:100] {
+		in, err := matcher.newMatchInput("Coalition, Inc.", s+".com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		frac, err := runMisspelledRootPhraseTest(context.Background(), matcher, in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if frac != 1 {
+			t.Errorf("MisspelledRootPhrase does not pass for candidate %s", s)
+		}
+	}
+
+	t.Run("distance_1_first", func(t *testing.T) {
+		matcher := NewMatcher()
+		matcher.MaxMisspellingCandidates = 5
+		got := matcher.MisspellingCandidates("Coalition")
+		want := []string{"oalition", "calition", "colition", "coaition", "coaltion"}
+		if len(got) != len(want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("got %v, want %v", got, want)
+				break
+			}
+		}
+	})
+
+	t.Run("default_cap", func(t *testing.T) {
+		if got := NewMatcher().MisspellingCandidates("Coalition"); len(got) != DefaultMaxMisspellingCandidates {
+			t.Errorf("got %d candidates, want %d", len(got), DefaultMaxMisspellingCandidates)
+		}
+	})
+
+	t.Run("fixed_prefix", func(t *testing.T) {
+		matcher := NewMatcher()
+		matcher.MisspellingFixedPrefix = 1
+		for _, s := range matcher.MisspellingCandidates("Coalition") {
+			if s[0] != 'c' {
+				t.Errorf("candidate %s alters the fixed prefix", s)
+			}
+		}
+	})
+}
+
+func TestShortRootPhrase(t *testing.T) {
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+	for _, ref := range []string{"X", "3M", "X Corp", "Q Inc", "", "!!!"} {
+		t.Run(ref, func(t *testing.T) {
+			if _, err := matcher.Match(ref, "coalition.com"); err != nil {
+				t.Errorf("Match: %s", err)
+			}
+			if _, err := matcher.MatchTokens(Normalize(ref), "coalition.com"); err != nil {
+				t.Errorf("MatchTokens: %s", err)
+			}
+			if _, err := matcher.QuickScore(ref, "coalition.com"); err != nil {
+				t.Errorf("QuickScore: %s", err)
+			}
+		})
+	}
+
+	t.Run("nil tokens", func(t *testing.T) {
+		if _, err := matcher.MatchTokens(nil, "coalition.com"); err != nil {
+			t.Error(err)
+		}
+	})
+
+	// A one-letter root phrase has no misspellings.
+	in, err := matcher.newMatchInput("X", "y.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	frac, err := runMisspelledRootPhraseTest(context.Background(), matcher, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if frac != 0 {
+		t.Errorf("got %v, want 0", frac)
+	}
+}
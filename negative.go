@@ -0,0 +1,58 @@
+package coalition
+
+import (
+	"context"
+	"strings"
+)
+
+// DefaultNegativeKeywords is the set of words used by the NegativeKeyword test
+// when Matcher.NegativeKeywords is nil.
+// The presence of any of these in a domain suggests that it is about the organization,
+// not owned by it.
+var DefaultNegativeKeywords = map[string]bool{
+	"fan":        true,
+	"fans":       true,
+	"unofficial": true,
+	"news":       true,
+	"review":     true,
+	"reviews":    true,
+	"sucks":      true,
+	"scam":       true,
+	"complaints": true,
+	"boycott":    true,
+}
+
+func runNegativeKeywordTest(_ context.Context, m Matcher, in *matchInput) (bool, error) {
+	keywords := m.NegativeKeywords
+	if keywords == nil {
+		keywords = DefaultNegativeKeywords
+	}
+
+	for _, label := range strings.Split(in.domain, ".") {
+		// Look only outside the root phrase,
+		// so that a brand like "Fanatics" doesn't disqualify itself.
+		affixes := labelAffixes(label, in.re)
+		if affixes == nil {
+			affixes = []string{label}
+		}
+		for _, affix := range affixes {
+			for _, token := range m.domainTokens(affix) {
+				if m.isNegativeToken(token, keywords) {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// This reports whether token is made of negative keywords,
+// possibly with stop words,
+// as in "reviews" or "thereviews",
+// but not "preview" or "fantasy".
+func (m Matcher) isNegativeToken(token string, keywords map[string]bool) bool {
+	isWord := func(s string) bool {
+		return keywords[s] || m.isStopWord(s)
+	}
+	return isWordRun(token, isWord) && !m.isStopWordRun(token)
+}
@@ -0,0 +1,43 @@
+package coalition
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNegativeKeywordTest(t *testing.T) {
+	cases := []struct {
+		ref, domain string
+		keywords    map[string]bool
+		want        int
+	}{
+		{ref: "Coalition, Inc", domain: "coalition.com", want: 50},
+		{ref: "Coalition, Inc", domain: "coalitionsucks.com", want: -10},    // root phrase, significant affix, negative keyword
+		{ref: "Coalition, Inc", domain: "coalition-reviews.com", want: -10}, // likewise
+		{ref: "Coalition, Inc", domain: "news.coalition.com", want: 0},      // root phrase, negative keyword
+		{ref: "Coalition, Inc", domain: "coalition-rutabaga.com", want: 40}, // not a negative keyword
+		{ref: "Fanatics", domain: "fanatics.com", want: 50},                 // "fan" is part of the root phrase
+		{ref: "Coalition, Inc", domain: "coalition-fantasy.com", want: 40},  // "fan" is part of another word
+		{ref: "Coalition, Inc", domain: "coalition-preview.com", want: 40},  // likewise "review"
+		{ref: "Coalition, Inc", domain: "coalition-thereviews.com", want: -10},
+		{ref: "Coalition, Inc", domain: "coalition-rutabaga.com", keywords: map[string]bool{"rutabaga": true}, want: -10},
+		{ref: "Coalition, Inc", domain: "coalitionsucks.com", keywords: map[string]bool{"rutabaga": true}, want: 40},
+	}
+
+	for _, c := range cases {
+		t.Run(c.domain, func(t *testing.T) {
+			matcher := NewMatcher()
+			delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+			matcher.Scores[testNegativeKeyword] = -50
+			matcher.NegativeKeywords = c.keywords
+
+			got, err := matcher.doMatch(context.Background(), c.ref, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
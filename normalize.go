@@ -0,0 +1,361 @@
+package coalition
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// defaultCollapse is the default value for Matcher.Collapse,
+// and for the WithCollapse option to Normalize.
+// It collapses apostrophes.
+var defaultCollapse = map[string]string{
+	"'": "",
+	"’": "",
+}
+
+type normalizeConfig struct {
+	noise      []*regexp.Regexp
+	stop       Stopper
+	fold       bool
+	compat     bool
+	maxRun     int
+	numbers    bool
+	connectors ConnectorPolicy
+	collapse   map[string]string
+	legalForms bool
+	geo        Stopper
+	lang       language.Tag
+}
+
+// NormalizeOption is the type of an option to Normalize.
+type NormalizeOption func(*normalizeConfig)
+
+// WithStopper tells Normalize to remove stop words,
+// as reported by s,
+// from the left and right ends of the result.
+// If s is a CanonicalStopper,
+// each word is first replaced with its canonical form.
+// By default no stop words are removed.
+func WithStopper(s Stopper) NormalizeOption {
+	return func(c *normalizeConfig) {
+		c.stop = s
+	}
+}
+
+// WithNoise tells Normalize to remove the parts of the reference string
+// that match any of the given regular expressions
+// before anything else,
+// as with registration metadata like "(Delaware, File #1234567)"
+// in "COALITION, INC. (Delaware, File #1234567)".
+// Each match is replaced with a space.
+// See Matcher.RefNoise and DefaultRefNoise.
+// By default nothing is removed.
+func WithNoise(noise []*regexp.Regexp) NormalizeOption {
+	return func(c *normalizeConfig) {
+		c.noise = noise
+	}
+}
+
+// WithLegalForms tells Normalize whether to remove legal forms of organization,
+// in several languages,
+// from the right end of the result,
+// e.g. "GmbH", "S.A.", and "Ltd".
+// See Matcher.LegalForms.
+// The default is false.
+func WithLegalForms(remove bool) NormalizeOption {
+	return func(c *normalizeConfig) {
+		c.legalForms = remove
+	}
+}
+
+// WithGeoTerms tells Normalize to remove geographic terms,
+// as reported by geo,
+// from the right end of the result,
+// e.g. "USA" in "Coalition USA".
+// See Matcher.GeoTerms and DefaultGeoTerms.
+// By default no geographic terms are removed.
+func WithGeoTerms(geo Stopper) NormalizeOption {
+	return func(c *normalizeConfig) {
+		c.geo = geo
+	}
+}
+
+// WithLanguage tells Normalize the language of the reference string,
+// for language-specific case mapping,
+// e.g. "I" to dotless "ı" in Turkish.
+// By default (or with language.Und),
+// language-neutral Unicode case folding is used.
+func WithLanguage(tag language.Tag) NormalizeOption {
+	return func(c *normalizeConfig) {
+		c.lang = tag
+	}
+}
+
+// WithDiacriticFolding tells Normalize whether to map letters with diacritics to plain letters,
+// e.g. "é" to "e".
+// The default is true.
+func WithDiacriticFolding(fold bool) NormalizeOption {
+	return func(c *normalizeConfig) {
+		c.fold = fold
+	}
+}
+
+// WithCompatibilityFolding tells Normalize whether to apply Unicode compatibility normalization (NFKC)
+// before anything else,
+// mapping full-width forms and ligatures to their plain equivalents,
+// e.g. "Ｃｏａｌｉｔｉｏｎ" to "Coalition" and "ﬁ" to "fi".
+// A symbol that would become letters,
+// like "™" ("TM"),
+// is left alone,
+// so it still separates words rather than joining them.
+// The default is false.
+func WithCompatibilityFolding(fold bool) NormalizeOption {
+	return func(c *normalizeConfig) {
+		c.compat = fold
+	}
+}
+
+// WithMaxLetterRun tells Normalize to shorten runs of the same letter
+// longer than n
+// to n,
+// as in "cooalition" for "Coooalition" when n is 2.
+// See Matcher.MaxLetterRun.
+// By default (or if n is not positive)
+// runs are left alone.
+func WithMaxLetterRun(n int) NormalizeOption {
+	return func(c *normalizeConfig) {
+		c.maxRun = n
+	}
+}
+
+// WithTrailingNumbers tells Normalize whether to keep a number at the end of the reference string,
+// as words at the end of the result,
+// as in {"coalition", "2", "0"} for "Coalition 2.0".
+// See Matcher.TrailingNumbers.
+// By default,
+// numbers are dropped,
+// like other characters that aren't letters.
+func WithTrailingNumbers(keep bool) NormalizeOption {
+	return func(c *normalizeConfig) {
+		c.numbers = keep
+	}
+}
+
+// WithConnectors tells Normalize how to treat the connectors "&", "+", and a standalone "n",
+// as in "AT&T", "R+Co", and "Chip n Dale".
+// See ConnectorPolicy and Matcher.Connectors.
+// The default is ConnectorsSeparate.
+func WithConnectors(policy ConnectorPolicy) NormalizeOption {
+	return func(c *normalizeConfig) {
+		c.connectors = policy
+	}
+}
+
+// WithCollapse tells Normalize how to collapse punctuation.
+// See Matcher.Collapse.
+// The default collapses apostrophes.
+func WithCollapse(collapse map[string]string) NormalizeOption {
+	return func(c *normalizeConfig) {
+		c.collapse = collapse
+	}
+}
+
+// Normalize normalizes a reference string like "The Société Générale Co."
+// to a list of words like {"the", "societe", "generale", "co"}.
+// It does this by case-folding everything
+// (so e.g. "ß" becomes "ss"; see WithLanguage),
+// folding letters with diacritics to plain letters,
+// collapsing some punctuation (e.g. apostrophes),
+// and splitting into words (on whitespace and other punctuation).
+// With the WithStopper option,
+// it also removes stop words from the left and right ends,
+// producing a Matcher's "root phrase"
+// (e.g. {"societe", "generale"}).
+// With the WithLegalForms option,
+// it removes legal forms like "GmbH" from the right end too,
+// and with the WithGeoTerms option,
+// geographic terms like "International".
+// With the WithNoise option,
+// it first removes non-name text like registration numbers.
+// Numbers are dropped,
+// except that with the WithTrailingNumbers option
+// one at the end of ref is kept.
+func Normalize(ref string, opts ...NormalizeOption) []string {
+	conf := normalizeConfig{
+		fold:     true,
+		collapse: defaultCollapse,
+	}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	for _, re := range conf.noise {
+		ref = re.ReplaceAllString(ref, " ")
+	}
+
+	if conf.compat {
+		ref = foldCompatibility(ref)
+	}
+
+	ref = foldCase(ref, conf.lang)
+
+	if conf.fold {
+		ref = foldDiacritics(ref)
+	}
+
+	ref = collapser(conf.collapse).Replace(ref)
+	ref = replaceConnectors(ref, conf.connectors)
+
+	if conf.maxRun > 0 {
+		ref = shortenLetterRuns(ref, conf.maxRun)
+	}
+
+	var numbers []string
+	if conf.numbers {
+		ref, numbers = splitTrailingNumber(ref)
+	}
+
+	var result []string
+	if cs, ok := conf.stop.(CanonicalStopper); ok {
+		result = canonicalWords(ref, cs)
+	} else {
+		result = strings.FieldsFunc(ref, func(r rune) bool {
+			return !unicode.IsLetter(r)
+		})
+	}
+	result = replaceConnectorWords(result, conf.connectors)
+	for len(result) > 1 {
+		if conf.stop != nil && conf.stop.IsStopWord(result[0]) {
+			result = result[1:]
+			continue
+		}
+		if conf.stop != nil && conf.stop.IsStopWord(result[len(result)-1]) {
+			result = result[:len(result)-1]
+			continue
+		}
+		if conf.legalForms {
+			if n := trailingLegalForm(result); n > 0 {
+				result = result[:len(result)-n]
+				continue
+			}
+		}
+		if conf.geo != nil {
+			if n := trailingGeoTerm(result, conf.geo); n > 0 {
+				result = result[:len(result)-n]
+				continue
+			}
+		}
+		break
+	}
+	return append(result, numbers...)
+}
+
+// DefaultRefNoise is the default value of Matcher.RefNoise.
+// It matches the parenthetical registration metadata
+// that references scraped from company registries often carry:
+// any parenthetical containing a digit,
+// like "(File #1234567)",
+// or a word like "file", "registration", or "jurisdiction",
+// and a parenthetical at the end of the reference containing a comma,
+// like "(Delaware, USA)".
+var DefaultRefNoise = []*regexp.Regexp{
+	regexp.MustCompile(`\([^()]*\d[^()]*\)`),
+	regexp.MustCompile(`(?i)\([^()]*\b(?:file|reg|regd|registered|registration|registry|company|entity|no|number|incorporated|jurisdiction)\b[^()]*\)`),
+	regexp.MustCompile(`\([^()]*,[^()]*\)\s*$`),
+}
+
+// This case-folds s using the rules of the given language,
+// or language-neutral Unicode folding if it's language.Und.
+func foldCase(s string, lang language.Tag) string {
+	if lang != language.Und {
+		s = cases.Lower(lang).String(s)
+	}
+	return cases.Fold().String(s)
+}
+
+// This applies NFKC normalization to s,
+// except to any non-letter whose normalized form includes a letter
+// (see WithCompatibilityFolding).
+func foldCompatibility(s string) string {
+	var (
+		buf   strings.Builder
+		start int
+	)
+	for i, r := range s {
+		if unicode.IsLetter(r) {
+			continue
+		}
+		if strings.IndexFunc(norm.NFKC.String(string(r)), unicode.IsLetter) < 0 {
+			continue
+		}
+		buf.WriteString(norm.NFKC.String(s[start:i]))
+		buf.WriteRune(r)
+		start = i + utf8.RuneLen(r)
+	}
+	buf.WriteString(norm.NFKC.String(s[start:]))
+	return buf.String()
+}
+
+// This shortens each run of the same letter in s
+// to at most n letters.
+// Other characters are left alone.
+func shortenLetterRuns(s string, n int) string {
+	var (
+		buf  strings.Builder
+		prev rune
+		run  int
+	)
+	for _, r := range s {
+		if r == prev && unicode.IsLetter(r) {
+			run++
+		} else {
+			prev, run = r, 1
+		}
+		if run <= n {
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
+// This maps letters with diacritics to plain letters where possible,
+// by decomposing them and removing the combining marks.
+// See https://blog.golang.org/normalization.
+func foldDiacritics(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	result, _, err := transform.String(t, s)
+	if err != nil {
+		return s
+	}
+	return result
+}
+
+// This returns a Replacer that applies collapse
+// (see Matcher.Collapse).
+// Longer keys take precedence over shorter ones.
+func collapser(collapse map[string]string) *strings.Replacer {
+	keys := make([]string, 0, len(collapse))
+	for k := range collapse {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	var oldnew []string
+	for _, k := range keys {
+		oldnew = append(oldnew, k, collapse[k])
+	}
+	return strings.NewReplacer(oldnew...)
+}
@@ -0,0 +1,390 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestNormalize(t *testing.T) {
+	const ref = "The Société Générale d'Investissement Co."
+
+	cases := []struct {
+		name string
+		opts []NormalizeOption
+		want []string
+	}{
+		{
+			name: "default",
+			want: []string{"the", "societe", "generale", "dinvestissement", "co"},
+		},
+		{
+			name: "stopper",
+			opts: []NormalizeOption{WithStopper(defaultStopper)},
+			want: []string{"societe", "generale", "dinvestissement"},
+		},
+		{
+			name: "no_folding",
+			opts: []NormalizeOption{WithDiacriticFolding(false)},
+			want: []string{"the", "société", "générale", "dinvestissement", "co"},
+		},
+		{
+			name: "stopper_no_folding",
+			opts: []NormalizeOption{WithStopper(defaultStopper), WithDiacriticFolding(false)},
+			want: []string{"société", "générale", "dinvestissement"},
+		},
+		{
+			name: "no_collapse",
+			opts: []NormalizeOption{WithCollapse(nil)},
+			want: []string{"the", "societe", "generale", "d", "investissement", "co"},
+		},
+		{
+			name: "all",
+			opts: []NormalizeOption{WithStopper(defaultStopper), WithDiacriticFolding(false), WithCollapse(nil)},
+			want: []string{"société", "générale", "d", "investissement"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := Normalize(ref, c.opts...)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestNormalizedRootPhrase(t *testing.T) {
+	matcher := NewMatcher()
+	got := matcher.normalizedRootPhrase("Société Générale")
+	want := Normalize("Société Générale", WithStopper(defaultStopper))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Diacritic folding lets this match.
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+	score, err := matcher.Match("Société Générale", "societegenerale.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if score < 0.8 {
+		t.Errorf("got score %f, want at least 0.8", score)
+	}
+}
+
+func TestLegalForms(t *testing.T) {
+	cases := []struct {
+		ref  string
+		want []string
+	}{
+		{ref: "Deutsche Bank AG", want: []string{"deutsche", "bank"}},
+		{ref: "Robert Bosch GmbH & Co. KG", want: []string{"robert", "bosch"}},
+		{ref: "Société Générale S.A.", want: []string{"societe", "generale"}},
+		{ref: "Dupont Frères S.à r.l.", want: []string{"dupont", "freres"}},
+		{ref: "Acme Widgets Ltd.", want: []string{"acme", "widgets"}},
+		{ref: "Coalition, Incorporated", want: []string{"coalition"}},
+		{ref: "Limited", want: []string{"limited"}}, // nothing else is left
+		{ref: "AG Barr", want: []string{"ag", "barr"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			got := Normalize(c.ref, WithStopper(defaultStopper), WithLegalForms(true))
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+	without, err := matcher.doMatch(context.Background(), "Deutsche Bank AG", "deutschebank.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matcher.LegalForms = true
+	with, err := matcher.doMatch(context.Background(), "Deutsche Bank AG", "deutschebank.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if with != 50 || without >= with {
+		t.Errorf("got %d with legal forms and %d without, want 50 and less", with, without)
+	}
+
+	// Legal forms are also ignorable affixes.
+	got, err := matcher.doMatch(context.Background(), "Coalition", "coalition-gmbh.de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 50 {
+		t.Errorf("got %d for coalition-gmbh.de, want 50", got)
+	}
+}
+
+func TestGeoTerms(t *testing.T) {
+	cases := []struct {
+		ref  string
+		want []string
+	}{
+		{ref: "Coalition USA", want: []string{"coalition"}},
+		{ref: "Coalition International", want: []string{"coalition"}},
+		{ref: "Coalition Asia Pacific", want: []string{"coalition"}},
+		{ref: "Coalition Europe Inc", want: []string{"coalition"}},
+		{ref: "American Express", want: []string{"american", "express"}}, // only at the end
+		{ref: "Global", want: []string{"global"}},                        // nothing else is left
+	}
+
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			got := Normalize(c.ref, WithStopper(defaultStopper), WithGeoTerms(DefaultGeoTerms))
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+
+	for _, ref := range []string{"Coalition USA", "Coalition International"} {
+		t.Run(ref, func(t *testing.T) {
+			matcher := NewMatcher()
+			delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+			without, err := matcher.doMatch(context.Background(), ref, "coalition.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			matcher.GeoTerms = DefaultGeoTerms
+			with, err := matcher.doMatch(context.Background(), ref, "coalition.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if with != 50 || without >= with {
+				t.Errorf("got %d with geographic terms and %d without, want 50 and less", with, without)
+			}
+		})
+	}
+
+	// Geographic terms are also ignorable affixes.
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef)
+	matcher.GeoTerms = DefaultGeoTerms
+	got, err := matcher.doMatch(context.Background(), "Coalition", "coalition-europe.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 50 {
+		t.Errorf("got %d for coalition-europe.com, want 50", got)
+	}
+}
+
+func TestCaseFolding(t *testing.T) {
+	cases := []struct {
+		ref  string
+		opts []NormalizeOption
+		want []string
+	}{
+		{ref: "İstanbul Bank", want: []string{"istanbul", "bank"}},
+		{ref: "İstanbul Bank", opts: []NormalizeOption{WithLanguage(language.Turkish)}, want: []string{"istanbul", "bank"}},
+		{ref: "İstanbul Bank", opts: []NormalizeOption{WithLanguage(language.Turkish), WithDiacriticFolding(false)}, want: []string{"istanbul", "bank"}},
+		{ref: "ISTANBUL BANK", opts: []NormalizeOption{WithLanguage(language.Turkish)}, want: []string{"ıstanbul", "bank"}},
+		{ref: "Straßenbau Müller", want: []string{"strassenbau", "muller"}},
+		{ref: "STRASSENBAU MÜLLER", want: []string{"strassenbau", "muller"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			got := Normalize(c.ref, c.opts...)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+	got, err := matcher.doMatch(context.Background(), "Schöne Straße", "schonestrasse.de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 50 {
+		t.Errorf("got %d, want 50", got)
+	}
+
+	matcher.Language = language.Turkish
+	got, err = matcher.doMatch(context.Background(), "İstanbul Bank", "istanbulbank.com.tr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 50 {
+		t.Errorf("got %d, want 50", got)
+	}
+}
+
+func TestCompatibilityFolding(t *testing.T) {
+	compat := WithCompatibilityFolding(true)
+
+	cases := []struct {
+		ref  string
+		opts []NormalizeOption
+		want []string
+	}{
+		{ref: "Ｃｏａｌｉｔｉｏｎ", opts: []NormalizeOption{compat}, want: []string{"coalition"}},
+		{ref: "Ｃｏａｌｉｔｉｏｎ", want: []string{"ｃｏａｌｉｔｉｏｎ"}},
+		{ref: "Ｃａｆé Ｓｏｃｉéｔé", opts: []NormalizeOption{compat}, want: []string{"cafe", "societe"}},
+		{ref: "Ｃａｆé", opts: []NormalizeOption{compat, WithDiacriticFolding(false)}, want: []string{"café"}},
+		{ref: "Ｏﬃce Ｄｅｐｏｔ", opts: []NormalizeOption{compat}, want: []string{"office", "depot"}},
+		{ref: "Coalition™ Services", opts: []NormalizeOption{compat}, want: []string{"coalition", "services"}},
+		{ref: "Coalition３６０", opts: []NormalizeOption{compat}, want: []string{"coalition"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			got := Normalize(c.ref, c.opts...)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+	matcher.CompatibilityFolding = true
+
+	for _, c := range []struct{ ref, domain string }{
+		{ref: "Ｃｏａｌｉｔｉｏｎ", domain: "coalition.com"},
+		{ref: "Coalition", domain: "ｃｏａｌｉｔｉｏｎ.com"},
+		{ref: "Ｅｆﬁｃｉｅｎｔ", domain: "efficient.com"},
+	} {
+		got, err := matcher.doMatch(context.Background(), c.ref, c.domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 50 {
+			t.Errorf("got %d for %s against %s, want 50", got, c.ref, c.domain)
+		}
+	}
+}
+
+func TestRefNoise(t *testing.T) {
+	cases := []struct {
+		ref  string
+		want []string
+	}{
+		{ref: "COALITION, INC. (Delaware, File #1234567)", want: []string{"coalition"}},
+		{ref: "Coalition Inc (Company No. 09876543)", want: []string{"coalition"}},
+		{ref: "Coalition (Reg. 4421) Holdings LLC", want: []string{"coalition", "holdings"}},
+		{ref: "Coalition Holdings (Registered in England and Wales)", want: []string{"coalition", "holdings"}},
+		{ref: "Coalition (Delaware, USA)", want: []string{"coalition"}},
+		{ref: "Toys (R) Us", want: []string{"toys", "r", "us"}},
+		{ref: "Coalition (Europe)", want: []string{"coalition", "europe"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			got := Normalize(c.ref, WithNoise(DefaultRefNoise), WithStopper(defaultStopper))
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+	const ref = "COALITION, INC. (Delaware, File #1234567)"
+	got, err := matcher.doMatch(context.Background(), ref, "coalitioninc.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 50 {
+		t.Errorf("got %d, want 50", got)
+	}
+
+	matcher.RefNoise = []*regexp.Regexp{}
+	got, err = matcher.doMatch(context.Background(), ref, "coalitioninc.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == 50 {
+		t.Errorf("got %d without removing noise, want less", got)
+	}
+}
+
+func TestMaxLetterRun(t *testing.T) {
+	cases := []struct {
+		ref    string
+		maxRun int
+		want   []string
+	}{
+		{ref: "Coooool Co", maxRun: 2, want: []string{"cool"}},
+		{ref: "Coooool Co", maxRun: 1, want: []string{"col"}},
+		{ref: "Coooool Co", want: []string{"coooool"}},
+		{ref: "Aaa Batteries 111", maxRun: 1, want: []string{"a", "bateries"}},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%s_%d", c.ref, c.maxRun), func(t *testing.T) {
+			got := Normalize(c.ref, WithStopper(defaultStopper), WithMaxLetterRun(c.maxRun))
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+
+	domainCases := []struct {
+		ref, domain string
+		maxRun      int
+		want        int
+	}{
+		{ref: "Coalition", domain: "coaliiiiition.com", maxRun: 1, want: 50},
+		{ref: "Coalition", domain: "www.coaliiiiition.com", maxRun: 1, want: 50},
+		{ref: "Coalition", domain: "coaliiiiition.com", maxRun: 2, want: 5}, // MisspelledRootPhrase
+		{ref: "Coalition", domain: "coaliiiiition.com", want: 0},
+		{ref: "Coooalition", domain: "coalition.com", maxRun: 1, want: 50},
+		{ref: "Coalition", domain: "cooalition.com", maxRun: 2, want: 5}, // MisspelledRootPhrase
+	}
+
+	for _, c := range domainCases {
+		t.Run(fmt.Sprintf("%s_%s_%d", c.ref, c.domain, c.maxRun), func(t *testing.T) {
+			matcher := NewMatcher()
+			delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+			matcher.MaxLetterRun = c.maxRun
+
+			got, err := matcher.doMatch(context.Background(), c.ref, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestDomainDiacritics(t *testing.T) {
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+	// An internationalized domain name,
+	// folded like the reference.
+	for _, domain := range []string{"sociétégénérale.fr", "SOCIÉTÉGÉNÉRALE.FR", "societegenerale.fr"} {
+		t.Run(domain, func(t *testing.T) {
+			got, err := matcher.doMatch(context.Background(), "Société Générale", domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != 50 {
+				t.Errorf("got %d, want 50", got)
+			}
+		})
+	}
+}
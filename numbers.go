@@ -0,0 +1,71 @@
+package coalition
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NumberPolicy says how a number at the end of a reference string or a domain label,
+// as in "Coalition 2.0" or "coalition247.com",
+// is treated
+// (see Matcher.TrailingNumbers).
+// Such a number is often a version or a marketing flourish,
+// but is sometimes part of the name.
+type NumberPolicy int
+
+const (
+	// NumbersIgnore drops a trailing number from the reference string,
+	// and the SignificantAffixes test disregards one
+	// after the root phrase in a domain label.
+	// So "Coalition 2.0" has the root phrase "coalition",
+	// and "coalition247.com" is not penalized for "247".
+	NumbersIgnore NumberPolicy = iota
+
+	// NumbersKeep makes a trailing number in the reference string part of its root phrase,
+	// so "Coalition 247" matches coalition247.com but not coalition.com.
+	// One in a domain label is an affix like any other
+	// (subject to Matcher.MinAffixLength).
+	NumbersKeep
+
+	// NumbersPenalize drops a trailing number from the reference string,
+	// as with NumbersIgnore,
+	// but one after the root phrase in a domain label is always a significant affix,
+	// so "coalition2.com" fails the SignificantAffixes test.
+	NumbersPenalize
+)
+
+// This splits the number off the end of s,
+// if there is one,
+// returning the rest of s and the digit runs of the number,
+// as in "Coalition" and {"2", "0"} for "Coalition 2.0".
+// Trailing punctuation is disregarded.
+// A number is trailing only if no letters follow it.
+func splitTrailingNumber(s string) (string, []string) {
+	i := strings.LastIndexFunc(s, unicode.IsLetter) + 1 // the index after the last letter, or 0
+	digits := strings.FieldsFunc(s[i:], func(r rune) bool {
+		return !unicode.IsDigit(r)
+	})
+	if len(digits) == 0 {
+		return s, nil
+	}
+	return s[:i], digits
+}
+
+// This reports whether the affix after the root phrase in a domain label
+// is significant to the SignificantAffixes test
+// because of a number at its end,
+// per m.TrailingNumbers,
+// and returns the affix to examine further.
+func (m Matcher) trailingNumberAffix(affix string) (string, bool) {
+	trimmed := strings.TrimRightFunc(affix, unicode.IsDigit)
+	if trimmed == affix {
+		return affix, false
+	}
+	switch m.TrailingNumbers {
+	case NumbersIgnore:
+		return trimmed, false
+	case NumbersPenalize:
+		return affix, true
+	}
+	return affix, false
+}
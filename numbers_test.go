@@ -0,0 +1,74 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSplitTrailingNumber(t *testing.T) {
+	cases := []struct {
+		inp, wantRest string
+		wantDigits    []string
+	}{
+		{inp: "Coalition", wantRest: "Coalition"},
+		{inp: "Coalition 2.0", wantRest: "Coalition", wantDigits: []string{"2", "0"}},
+		{inp: "Coalition247", wantRest: "Coalition", wantDigits: []string{"247"}},
+		{inp: "Coalition 247.", wantRest: "Coalition", wantDigits: []string{"247"}},
+		{inp: "Web 2.0 Inc", wantRest: "Web 2.0 Inc"},
+		{inp: "2020", wantRest: "", wantDigits: []string{"2020"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.inp, func(t *testing.T) {
+			rest, digits := splitTrailingNumber(c.inp)
+			if rest != c.wantRest {
+				t.Errorf("got rest %q, want %q", rest, c.wantRest)
+			}
+			if !reflect.DeepEqual(digits, c.wantDigits) {
+				t.Errorf("got digits %v, want %v", digits, c.wantDigits)
+			}
+		})
+	}
+}
+
+func TestTrailingNumbers(t *testing.T) {
+	cases := []struct {
+		ref, domain string
+		policy      NumberPolicy
+		want        int
+	}{
+		{ref: "Coalition", domain: "coalition247.com", policy: NumbersIgnore, want: 50},
+		{ref: "Coalition", domain: "coalition247.com", policy: NumbersKeep, want: 40},
+		{ref: "Coalition", domain: "coalition247.com", policy: NumbersPenalize, want: 40},
+		{ref: "Coalition", domain: "coalition2.com", policy: NumbersIgnore, want: 50},
+		{ref: "Coalition", domain: "coalition2.com", policy: NumbersKeep, want: 50},
+		{ref: "Coalition", domain: "coalition2.com", policy: NumbersPenalize, want: 40},
+		{ref: "Coalition", domain: "coalition-2.com", policy: NumbersPenalize, want: 40},
+		{ref: "Coalition", domain: "coalition247pro.com", policy: NumbersIgnore, want: 40},
+		{ref: "Coalition", domain: "247coalition.com", policy: NumbersIgnore, want: 40},
+		{ref: "Coalition 2.0", domain: "coalition.com", policy: NumbersIgnore, want: 50},
+		{ref: "Coalition 2.0", domain: "coalition20.com", policy: NumbersIgnore, want: 50},
+		{ref: "Coalition 2.0", domain: "coalition20.com", policy: NumbersKeep, want: 50},
+		{ref: "Coalition 2.0", domain: "coalition.com", policy: NumbersKeep, want: 10},
+		{ref: "Coalition 247", domain: "coalition247.com", policy: NumbersPenalize, want: 40},
+		{ref: "Coalition 247", domain: "coalition247.com", policy: NumbersKeep, want: 50},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%s_%s_%d", c.ref, c.domain, c.policy), func(t *testing.T) {
+			matcher := NewMatcher()
+			delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+			matcher.TrailingNumbers = c.policy
+
+			got, err := matcher.doMatch(context.Background(), c.ref, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
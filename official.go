@@ -0,0 +1,73 @@
+package coalition
+
+import (
+	"strings"
+)
+
+// OfficialDomainProvider supplies domains known to be official for organizations,
+// such as from a human-verified list.
+// See Matcher.Official.
+type OfficialDomainProvider interface {
+	// OfficialDomains returns the official domains of the organization
+	// whose normalized root phrase is norm.
+	OfficialDomains(norm []string) []string
+}
+
+// OfficialDomainMap is a simple OfficialDomainProvider.
+// Its keys are normalized root phrases,
+// with the words separated by spaces.
+type OfficialDomainMap map[string][]string
+
+// OfficialDomains implements OfficialDomainProvider.
+func (o OfficialDomainMap) OfficialDomains(norm []string) []string {
+	return o[strings.Join(norm, " ")]
+}
+
+// Add records that the given domains are official for the organization named by ref.
+// The key is normalized with a default Matcher's normalization.
+func (o OfficialDomainMap) Add(ref string, domains ...string) {
+	key := strings.Join(defaultMatcher.normalizedRootPhrase(ref), " ")
+	o[key] = append(o[key], domains...)
+}
+
+// This tells whether in.domain is one of the official domains,
+// according to m.Official,
+// for in.ref or any of its aliases
+// (see Matcher.Aliases).
+// Each is looked up by its normalization under m
+// and also by the default normalization,
+// which is how OfficialDomainMap.Add keys them.
+// Official domains are folded as in the string tests
+// (see Matcher.foldDomain),
+// so they compare case-insensitively, and without any leading "www." label.
+func (m Matcher) isOfficial(in *matchInput) bool {
+	if m.Official == nil {
+		return false
+	}
+
+	refs := []string{in.ref}
+	if m.Aliases != nil {
+		refs = append(refs, m.aliases(in)...)
+	}
+
+	seen := make(map[string]bool)
+	for i, ref := range refs {
+		norm := in.norm
+		if i > 0 {
+			norm = m.normalizedRootPhrase(ref)
+		}
+		for _, norm := range [][]string{norm, defaultMatcher.normalizedRootPhrase(ref)} {
+			key := strings.Join(norm, " ")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			for _, domain := range m.Official.OfficialDomains(norm) {
+				if m.foldDomain(hostname(domain), in.marks) == in.domain {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
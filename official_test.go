@@ -0,0 +1,90 @@
+package coalition
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOfficial(t *testing.T) {
+	official := make(OfficialDomainMap)
+	official.Add("Coalition, Inc.", "coalitioninc.com", "thecoalition.net")
+
+	cases := []struct {
+		domain string
+		boost  int
+		want   float32
+	}{
+		{domain: "thecoalition.net", want: 1},
+		{domain: "www.TheCoalition.net", want: 1},
+		{domain: "xyzzy.net", want: 0.14285715},                 // 0 on a scale of -10 to 60
+		{domain: "coalitioninc.com", boost: 5, want: 0.9285714}, // RootPhrase and the boost
+		{domain: "coalitioninc.com", boost: 20, want: 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.domain, func(t *testing.T) {
+			matcher := NewMatcher()
+			delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+			matcher.Official = official
+			matcher.OfficialBoost = c.boost
+
+			got, err := matcher.MatchContext(context.Background(), "Coalition", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+
+	t.Run("detail", func(t *testing.T) {
+		matcher := NewMatcher()
+		matcher.Official = official
+
+		// No tests run, so no network requests are made either.
+		detail, err := matcher.MatchDetail(context.Background(), "Coalition Inc", "thecoalition.net")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !detail.Official || detail.Score != 1 || len(detail.Outcomes) != 0 {
+			t.Errorf("got %+v, want official with score 1 and no outcomes", detail)
+		}
+	})
+
+	t.Run("alias", func(t *testing.T) {
+		aliases := make(AliasMap)
+		aliases.Add("Coalition", "Acme Widgets")
+
+		matcher := NewMatcher()
+		matcher.Official = official
+		matcher.Aliases = aliases
+
+		detail, err := matcher.MatchDetail(context.Background(), "Acme Widgets", "thecoalition.net")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !detail.Official {
+			t.Errorf("got %+v, want official through the alias", detail)
+		}
+	})
+
+	t.Run("folded", func(t *testing.T) {
+		official := make(OfficialDomainMap)
+		official.Add("Société Générale", "SOCIÉTÉGÉNÉRALE.fr")
+
+		matcher := NewMatcher()
+		matcher.Official = official
+		matcher.CompatibilityFolding = true
+
+		for _, domain := range []string{"societegenerale.fr", "ｓｏｃｉｅｔｅｇｅｎｅｒａｌｅ.fr"} {
+			detail, err := matcher.MatchDetail(context.Background(), "Societe Generale", domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !detail.Official {
+				t.Errorf("%s: got %+v, want official", domain, detail)
+			}
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package coalition
+
+// MatchPlan describes the tests a Matcher runs,
+// without running them.
+// See Matcher.Plan.
+type MatchPlan struct {
+	// Tests are all the tests the Matcher knows,
+	// enabled or not,
+	// in the order the Matcher defines them.
+	Tests []PlannedTest
+
+	// Min and Max are the lowest and highest possible raw scores
+	// (see Matcher.Score),
+	// before any TLD weight.
+	// Min maps to a result of 0.0 and Max to 1.0.
+	Min, Max int
+}
+
+// PlannedTest describes one of the tests in a MatchPlan.
+type PlannedTest struct {
+	// Test is the name of the test.
+	Test TestName
+
+	// Enabled tells whether the test runs,
+	// i.e. whether it has a non-zero score.
+	Enabled bool
+
+	// Network tells whether the test makes outbound requests
+	// (as opposed to examining only the reference and the domain name).
+	Network bool
+
+	// Score is the test's score in Matcher.Scores.
+	Score int
+}
+
+// Plan describes the tests m runs and the range of scores they can produce,
+// without running anything.
+func (m Matcher) Plan() MatchPlan {
+	var plan MatchPlan
+	for _, t := range builtinTests {
+		score := m.Scores[t.typ]
+		plan.Tests = append(plan.Tests, PlannedTest{
+			Test:    TestName(testNames[t.typ]),
+			Enabled: score != 0,
+			Network: t.network,
+			Score:   score,
+		})
+	}
+	plan.Min, plan.Max = m.scoreRange()
+	return plan
+}
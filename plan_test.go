@@ -0,0 +1,53 @@
+package coalition
+
+import "testing"
+
+func TestPlan(t *testing.T) {
+	matcher := NewMatcher().
+		WithScore(TestRootPhrase, 70).
+		WithScore(TestNewDomain, -20).
+		WithoutTest(TestWebPageRef)
+
+	plan := matcher.Plan()
+
+	if len(plan.Tests) != len(builtinTests) {
+		t.Fatalf("got %d tests, want %d", len(plan.Tests), len(builtinTests))
+	}
+
+	byName := make(map[TestName]PlannedTest)
+	for i, pt := range plan.Tests {
+		if want := TestName(testNames[builtinTests[i].typ]); pt.Test != want {
+			t.Errorf("test %d is %s, want %s", i, pt.Test, want)
+		}
+		byName[pt.Test] = pt
+	}
+
+	cases := []PlannedTest{
+		{Test: TestRootPhrase, Enabled: true, Score: 70},
+		{Test: TestAnyRootWord, Enabled: true, Score: matcher.Scores[testAnyRootWord]},
+		{Test: TestNewDomain, Enabled: true, Network: true, Score: -20},
+		{Test: TestWebPageRef, Network: true},
+		{Test: TestBrandAsset, Network: true},
+		{Test: TestHyphenated},
+	}
+	for _, want := range cases {
+		if got := byName[want.Test]; got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	}
+
+	var wantMin, wantMax int
+	for _, pt := range plan.Tests {
+		if pt.Score < 0 {
+			wantMin += pt.Score
+		} else {
+			wantMax += pt.Score
+		}
+	}
+	if plan.Min != wantMin || plan.Max != wantMax {
+		t.Errorf("got range [%d..%d], want [%d..%d]", plan.Min, plan.Max, wantMin, wantMax)
+	}
+	if plan.Min > -20 || plan.Max < 70 {
+		t.Errorf("got range [%d..%d], want it to include [-20..70]", plan.Min, plan.Max)
+	}
+}
@@ -0,0 +1,91 @@
+package coalition
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// ProxyProvider chooses the proxy for each web request a Matcher makes.
+// See Matcher.ProxyProvider.
+// Implementations must be safe for concurrent use.
+type ProxyProvider interface {
+	// Proxy returns the URL of the proxy to use for req,
+	// or nil for none.
+	// An error makes the request fail.
+	// The context is that of the request.
+	Proxy(ctx context.Context, req *http.Request) (*url.URL, error)
+}
+
+// RoundRobinProxies returns a ProxyProvider
+// that uses each of the given proxies in turn,
+// one request after another,
+// so that the requests of a batch like MatchMany are spread among them.
+// With no proxies it uses none.
+func RoundRobinProxies(proxies ...*url.URL) ProxyProvider {
+	return &roundRobin{proxies: proxies}
+}
+
+type roundRobin struct {
+	mu      sync.Mutex
+	proxies []*url.URL
+	next    int
+}
+
+func (r *roundRobin) Proxy(context.Context, *http.Request) (*url.URL, error) {
+	if len(r.proxies) == 0 {
+		return nil, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u := r.proxies[r.next]
+	r.next = (r.next + 1) % len(r.proxies)
+	return u, nil
+}
+
+// fixedProxy is a ProxyProvider that always chooses the same proxy
+// (or none, if u is nil).
+// It pins the proxy of a request whose response is cached
+// (see pageCacheKey).
+type fixedProxy struct {
+	u *url.URL
+}
+
+func (p fixedProxy) Proxy(context.Context, *http.Request) (*url.URL, error) {
+	return p.u, nil
+}
+
+// proxyKey is the context key for the ProxyProvider of a request.
+type proxyKey struct{}
+
+// This adds m.ProxyProvider, if any, to ctx,
+// for proxyFromContext.
+func (m Matcher) withProxyProvider(ctx context.Context) context.Context {
+	if m.ProxyProvider == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, proxyKey{}, m.ProxyProvider)
+}
+
+// This is the Proxy function of the default transport.
+// It consults the ProxyProvider in the request's context,
+// if there is one,
+// and otherwise the environment
+// (see http.ProxyFromEnvironment).
+// Keeping the provider in the context,
+// instead of in a transport of its own,
+// lets Matchers with different providers share one pool of idle connections.
+func proxyFromContext(req *http.Request) (*url.URL, error) {
+	ctx := req.Context()
+	p, ok := ctx.Value(proxyKey{}).(ProxyProvider)
+	if !ok {
+		return http.ProxyFromEnvironment(req)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return p.Proxy(ctx, req)
+}
@@ -0,0 +1,140 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// proxyLog records the hosts requested through each of several test proxies.
+type proxyLog struct {
+	mu    sync.Mutex
+	hosts map[string][]string // proxy name -> requested hosts
+}
+
+// This starts a test HTTP proxy that serves a page mentioning "coalition" for every request,
+// recording the request under name.
+// The caller must close the server.
+func (l *proxyLog) newProxy(name string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		l.mu.Lock()
+		l.hosts[name] = append(l.hosts[name], req.URL.Host)
+		l.mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><body>coalition</body></html>")
+	}))
+}
+
+// fakeProxyProvider alternates between its proxies,
+// recording the URL of each request it is asked about.
+type fakeProxyProvider struct {
+	mu       sync.Mutex
+	proxies  []*url.URL
+	requests []string
+}
+
+func (p *fakeProxyProvider) Proxy(_ context.Context, req *http.Request) (*url.URL, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u := p.proxies[len(p.requests)%len(p.proxies)]
+	p.requests = append(p.requests, req.URL.String())
+	return u, nil
+}
+
+func TestProxyProvider(t *testing.T) {
+	log := &proxyLog{hosts: make(map[string][]string)}
+	provider := new(fakeProxyProvider)
+	for _, name := range []string{"a", "b"} {
+		srv := log.newProxy(name)
+		defer srv.Close()
+
+		u, err := url.Parse(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		provider.proxies = append(provider.proxies, u)
+	}
+
+	matcher := NewMatcher()
+	matcher.ProxyProvider = provider
+
+	domains := []string{"coalition.example", "coalitioninc.example"}
+	scores, err := matcher.MatchMany(context.Background(), "Coalition", domains)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withoutWeb := NewMatcher()
+	delete(withoutWeb.Scores, testWebPageRef)
+	for i, domain := range domains {
+		// Passing WebPageRef shows that the page came through a proxy.
+		without, err := withoutWeb.Match("Coalition", domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if scores[i] <= without {
+			t.Errorf("%s: got %v, want more than %v", domain, scores[i], without)
+		}
+	}
+
+	if len(provider.requests) != 2 {
+		t.Fatalf("got %d proxied requests (%v), want 2", len(provider.requests), provider.requests)
+	}
+	if len(log.hosts["a"]) != 1 || len(log.hosts["b"]) != 1 {
+		t.Errorf("got requests %v, want one through each proxy", log.hosts)
+	}
+	if log.hosts["a"][0] == log.hosts["b"][0] {
+		t.Errorf("got the same host %s through both proxies", log.hosts["a"][0])
+	}
+}
+
+func TestRoundRobinProxies(t *testing.T) {
+	var proxies []*url.URL
+	for _, s := range []string{"http://proxy1:8080", "http://proxy2:8080", "http://proxy3:8080"} {
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		proxies = append(proxies, u)
+	}
+
+	provider := RoundRobinProxies(proxies...)
+	for i := 0; i < 7; i++ {
+		got, err := provider.Proxy(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := proxies[i%3]; got != want {
+			t.Errorf("request %d: got %v, want %v", i, got, want)
+		}
+	}
+
+	if got, err := RoundRobinProxies().Proxy(context.Background(), nil); got != nil || err != nil {
+		t.Errorf("got %v, %v with no proxies, want nil, nil", got, err)
+	}
+}
+
+func TestProxyCancellation(t *testing.T) {
+	provider := &fakeProxyProvider{proxies: []*url.URL{{Scheme: "http", Host: "proxy:8080"}}}
+	matcher := Matcher{ProxyProvider: provider}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, err := http.NewRequestWithContext(matcher.withProxyProvider(ctx), "GET", "http://coalition.example/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := proxyFromContext(req); err != context.Canceled {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+	if len(provider.requests) != 0 {
+		t.Errorf("got %d calls to the provider, want 0", len(provider.requests))
+	}
+}
@@ -0,0 +1,40 @@
+package coalition
+
+import "context"
+
+// QuickScore is a cheap pre-filter for long lists of candidate domains.
+// It runs only the tests that examine the reference and the domain name
+// (RootPhrase, AnyRootWord, MisspelledRootPhrase, SignificantAffixes,
+// and, if they have scores, Hyphenated, NegativeKeyword, and LeadWord),
+// never the network tests,
+// and maps the sum of their scores from the range of those tests alone to [0.0..1.0]
+// (see Calibration).
+// So a domain that can't match well by name alone gets a low QuickScore,
+// and can be discarded before spending a web fetch on it.
+//
+// Aliases, Official, TLDWeights, and MinPassingTests are not consulted,
+// and the call is not reported to Metrics.
+func (m Matcher) QuickScore(ref, domain string) (float32, error) {
+	in, err := m.newMatchInput(ref, domain)
+	if err != nil {
+		return 0, err
+	}
+	outcomes, err := m.runTestsDetail(context.Background(), in, stringTests)
+	if err != nil {
+		return 0, err
+	}
+
+	var min, max int
+	for _, t := range stringTests {
+		switch v := m.Scores[t.typ]; {
+		case v < 0:
+			min += v
+		case v > 0:
+			max += v
+		}
+	}
+	if min == max {
+		return 0, nil
+	}
+	return m.scaleRange(sumOutcomes(outcomes), min, max), nil
+}
@@ -0,0 +1,80 @@
+package coalition
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestQuickScore(t *testing.T) {
+	cases := []struct {
+		ref, domain string
+	}{
+		{ref: "Coalition", domain: "coalition.com"},
+		{ref: "Coalition, Inc", domain: "coalitioninc.com"},
+		{ref: "Coalition", domain: "coalition-rutabaga.com"},
+		{ref: "Coalition", domain: "colition.com"},
+		{ref: "Coalition Security", domain: "security.com"},
+		{ref: "Coalition", domain: "example.com"},
+	}
+
+	rec := new(RequestRecorder)
+	matcher := NewMatcher()
+	matcher.Client = &http.Client{Transport: rec}
+
+	isString := make(map[string]bool)
+	var min, max int
+	for _, st := range stringTests {
+		isString[testNames[st.typ]] = true
+		if v := matcher.Scores[st.typ]; v < 0 {
+			min += v
+		} else {
+			max += v
+		}
+	}
+
+	for _, c := range cases {
+		t.Run(c.domain, func(t *testing.T) {
+			got, err := matcher.QuickScore(c.ref, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Compare with the subtotal of the string tests in a full match.
+			_, detail, err := matcher.doMatchDetail(context.Background(), c.ref, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var subtotal int
+			for _, o := range detail.Outcomes {
+				if isString[o.Test] {
+					subtotal += o.Score
+				}
+			}
+			if want := LinearScale(subtotal, min, max); got != want {
+				t.Errorf("got %v, want %v (subtotal %d of [%d..%d])", got, want, subtotal, min, max)
+			}
+		})
+	}
+
+	// Only the full matches above should have made requests.
+	before := len(rec.Requests())
+	for _, c := range cases {
+		if _, err := matcher.QuickScore(c.ref, c.domain); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(rec.Requests()) - before; n != 0 {
+		t.Errorf("got %d requests from QuickScore, want 0", n)
+	}
+
+	t.Run("perfect", func(t *testing.T) {
+		got, err := matcher.QuickScore("Coalition", "coalition.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got < 0.85 {
+			t.Errorf("got %v, want at least 0.85", got)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package coalition
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// RequestRecorder is an http.RoundTripper that records the requests made through it
+// and serves canned responses instead of contacting any server.
+// It is for testing code that uses a Matcher.
+// Install it with:
+//
+//	rec := &RequestRecorder{...}
+//	m.Client = &http.Client{Transport: rec}
+//
+// It is safe for concurrent use.
+type RequestRecorder struct {
+	// Responses maps URLs to the responses to serve for them.
+	// A request for any other URL gets a 404 Not Found response.
+	Responses map[string]CannedResponse
+
+	mu       sync.Mutex
+	requests []RecordedRequest
+}
+
+// CannedResponse is a response served by a RequestRecorder.
+type CannedResponse struct {
+	// StatusCode is the HTTP status of the response.
+	// If zero, 200 (OK) is used.
+	StatusCode int
+
+	// ContentType is the value of the response's Content-Type header.
+	ContentType string
+
+	// Body is the body of the response.
+	Body string
+}
+
+// RecordedRequest is a request recorded by a RequestRecorder.
+type RecordedRequest struct {
+	Method string
+	URL    string
+	Header http.Header
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *RequestRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	url := req.URL.String()
+
+	r.mu.Lock()
+	r.requests = append(r.requests, RecordedRequest{
+		Method: req.Method,
+		URL:    url,
+		Header: req.Header.Clone(),
+	})
+	r.mu.Unlock()
+
+	canned, ok := r.Responses[url]
+	if !ok {
+		canned = CannedResponse{StatusCode: http.StatusNotFound, ContentType: "text/plain", Body: "not found"}
+	}
+	status := canned.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	header := make(http.Header)
+	if canned.ContentType != "" {
+		header.Set("Content-Type", canned.ContentType)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(canned.Body)),
+		ContentLength: int64(len(canned.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Requests returns the requests recorded so far, in the order they were made.
+func (r *RequestRecorder) Requests() []RecordedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]RecordedRequest, len(r.requests))
+	copy(result, r.requests)
+	return result
+}
+
+// Reset discards the requests recorded so far.
+func (r *RequestRecorder) Reset() {
+	r.mu.Lock()
+	r.requests = nil
+	r.mu.Unlock()
+}
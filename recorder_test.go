@@ -0,0 +1,118 @@
+package coalition
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func ExampleRequestRecorder() {
+	rec := &RequestRecorder{
+		Responses: map[string]CannedResponse{
+			"http://coalitioninc.com/": {
+				ContentType: "text/html",
+				Body:        "<html><body>Welcome to coalition</body></html>",
+			},
+		},
+	}
+
+	m := NewMatcher()
+	m.Client = &http.Client{Transport: rec}
+
+	for _, domain := range []string{"coalitioninc.com", "www.coalition-rutabaga.com"} {
+		score, err := m.Match("Coalition, Inc", domain)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("%s: %.2f\n", domain, score)
+	}
+
+	for _, req := range rec.Requests() {
+		fmt.Println(req.Method, req.URL)
+	}
+
+	// Output:
+	// coalitioninc.com: 0.92
+	// www.coalition-rutabaga.com: 0.42
+	// GET http://coalitioninc.com/
+	// GET http://www.coalition-rutabaga.com/
+}
+
+func TestRequestRecorder(t *testing.T) {
+	rec := &RequestRecorder{
+		Responses: map[string]CannedResponse{
+			"http://coalitioninc.com/": {
+				StatusCode:  http.StatusOK,
+				ContentType: "text/html",
+				Body:        `<html><head><script type="application/ld+json">{"@type": "Organization", "name": "Coalition, Inc."}</script></head></html>`,
+			},
+		},
+	}
+
+	m := NewMatcher()
+	m.Client = &http.Client{Transport: rec}
+	m.Scores = map[testType]int{
+		testWebPageRef:         50,
+		testJSONLDOrganization: 20,
+	}
+
+	score, err := m.Match("Coalition, Inc", "coalitioninc.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if score != float32(20)/70 {
+		t.Errorf("got score %f, want %f", score, float32(20)/70)
+	}
+
+	// The two web tests share a single request.
+	reqs := rec.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("got %d requests, want 1", len(reqs))
+	}
+	if reqs[0].URL != "http://coalitioninc.com/" {
+		t.Errorf("got URL %s, want http://coalitioninc.com/", reqs[0].URL)
+	}
+
+	rec.Reset()
+	if got := len(rec.Requests()); got != 0 {
+		t.Errorf("got %d requests after reset, want 0", got)
+	}
+}
+
+func TestResponseStatus(t *testing.T) {
+	rec := &RequestRecorder{
+		Responses: map[string]CannedResponse{
+			"http://coalitioninc.com/": {ContentType: "text/html", Body: "coalition"},
+		},
+	}
+	client := &http.Client{Transport: rec}
+
+	cases := []struct {
+		url, want string
+	}{
+		{url: "http://coalitioninc.com/", want: "200 OK"},
+		{url: "http://example.com/", want: "404 Not Found"},
+	}
+	for _, c := range cases {
+		t.Run(c.url, func(t *testing.T) {
+			resp, err := client.Get(c.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.Status != c.want {
+				t.Errorf("got status %q, want %q", resp.Status, c.want)
+			}
+
+			// Likewise for a response from a PageCache.
+			page := &CachedPage{URL: c.url, StatusCode: resp.StatusCode}
+			cached, err := page.response()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cached.Status != c.want {
+				t.Errorf("got cached status %q, want %q", cached.Status, c.want)
+			}
+		})
+	}
+}
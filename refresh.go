@@ -0,0 +1,88 @@
+package coalition
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"github.com/bobg/htree"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// DefaultMaxMetaRefreshes is the number of meta-refresh redirects followed
+// when Matcher.MaxMetaRefreshes is zero.
+const DefaultMaxMetaRefreshes = 3
+
+// metaRefreshTarget returns the URL that page redirects to
+// with a <meta http-equiv="refresh" content="N; url=..."> element,
+// resolved relative to base.
+// It returns nil if there is no such element.
+func metaRefreshTarget(page *webPage, base *url.URL) *url.URL {
+	var content string
+	if page.raw != nil {
+		// Don't parse a page read for streaming just for this.
+		var ok bool
+		if content, ok = streamMetaRefresh(bytes.NewReader(page.raw)); !ok {
+			return nil
+		}
+	} else {
+		if page.tree == nil {
+			return nil
+		}
+		meta := htree.FindEl(page.tree, isMetaRefresh)
+		if meta == nil {
+			return nil
+		}
+		content = htree.ElAttr(meta, "content")
+	}
+
+	// The content is a delay in seconds,
+	// optionally followed by a semicolon (or comma) and "url=" and the target.
+	i := strings.IndexAny(content, ";,")
+	if i < 0 {
+		return nil // refreshes the same page
+	}
+	target := strings.TrimSpace(content[i+1:])
+	if len(target) >= 4 && strings.EqualFold(target[:4], "url=") {
+		target = strings.TrimSpace(target[4:])
+	}
+	target = strings.Trim(target, `'"`)
+	if target == "" {
+		return nil
+	}
+
+	u, err := base.Parse(target)
+	if err != nil {
+		return nil
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil
+	}
+	return u
+}
+
+// This tells whether n is a <meta http-equiv="refresh"> element.
+func isMetaRefresh(n *html.Node) bool {
+	return n.DataAtom == atom.Meta && strings.EqualFold(htree.ElAttr(n, "http-equiv"), "refresh")
+}
+
+// sameSite tells whether a and b have the same registrable domain
+// (e.g. "example.com" for both "www.example.com" and "shop.example.com";
+// see Matcher.PublicSuffixList).
+// Hosts without one, such as IP addresses, must match exactly.
+func (m Matcher) sameSite(a, b *url.URL) bool {
+	aHost, bHost := strings.ToLower(a.Hostname()), strings.ToLower(b.Hostname())
+	if aHost == bHost {
+		return true
+	}
+	aSite, ok := m.effectiveTLDPlusOne(aHost)
+	if !ok {
+		return false
+	}
+	bSite, ok := m.effectiveTLDPlusOne(bHost)
+	if !ok {
+		return false
+	}
+	return aSite == bSite
+}
@@ -0,0 +1,92 @@
+package coalition
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestMetaRefresh(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/", pageHandler("text/html", `<html><head><meta http-equiv="refresh" content="0; url=/landing"></head><body></body></html>`))
+	mux.Handle("/landing", pageHandler("text/html", `<html><head><meta http-equiv="Refresh" content="0;URL='/home'"></head><body></body></html>`))
+	mux.Handle("/home", pageHandler("text/html", `<html><body>Welcome to coalition</body></html>`))
+	mux.Handle("/away", pageHandler("text/html", `<html><head><meta http-equiv="refresh" content="0; url=https://coalition.example.com/"></head><body></body></html>`))
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	domain := strings.TrimPrefix(srv.URL, "http://")
+
+	cases := []struct {
+		name             string
+		maxMetaRefreshes int
+		want             int
+	}{
+		{name: "default", want: 50},
+		{name: "enough", maxMetaRefreshes: 2, want: 50},
+		{name: "too_few", maxMetaRefreshes: 1, want: 0},
+		{name: "disabled", maxMetaRefreshes: -1, want: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			matcher := NewMatcher()
+			matcher.MaxMetaRefreshes = c.maxMetaRefreshes
+
+			got, err := matcher.doMatch(context.Background(), "Coalition", domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestMetaRefreshTarget(t *testing.T) {
+	base, err := url.Parse("http://www.coalitioninc.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		content, want string
+		wantSameSite  bool
+	}{
+		{content: "0; url=/home", want: "http://www.coalitioninc.com/home", wantSameSite: true},
+		{content: "5;URL='https://coalitioninc.com/'", want: "https://coalitioninc.com/", wantSameSite: true},
+		{content: `0, url="https://example.com/"`, want: "https://example.com/"},
+		{content: "30"},
+		{content: "0; url=javascript:alert(1)"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.content, func(t *testing.T) {
+			tree, err := parseHTML(`<html><head><meta http-equiv="refresh" content="` + strings.ReplaceAll(c.content, `"`, "&quot;") + `"></head></html>`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := metaRefreshTarget(&webPage{tree: tree}, base)
+			if c.want == "" {
+				if got != nil {
+					t.Errorf("got %s, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("got nil, want %s", c.want)
+			}
+			if got.String() != c.want {
+				t.Errorf("got %s, want %s", got, c.want)
+			}
+			if (Matcher{}).sameSite(got, base) != c.wantSameSite {
+				t.Errorf("got sameSite %v, want %v", !c.wantSameSite, c.wantSameSite)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package coalition
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRegionAffixes(t *testing.T) {
+	cases := []struct {
+		domain      string
+		with, nilRA int
+	}{
+		{domain: "coalition-us.com", with: 50, nilRA: 40},
+		{domain: "coalitionuk.com", with: 50, nilRA: 40},
+		{domain: "coalition-de.com", with: 50, nilRA: 40},
+		{domain: "emea-coalition.com", with: 50, nilRA: 40},
+		{domain: "coalitionusde.com", with: 40, nilRA: 40},
+		{domain: "coalition-rutabaga.com", with: 40, nilRA: 40},
+	}
+
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+	noRegions := matcher.Clone()
+	noRegions.RegionAffixes = nil
+
+	for _, c := range cases {
+		t.Run(c.domain, func(t *testing.T) {
+			got, err := matcher.doMatch(context.Background(), "Coalition", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.with {
+				t.Errorf("got %d, want %d", got, c.with)
+			}
+
+			got, err = noRegions.doMatch(context.Background(), "Coalition", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.nilRA {
+				t.Errorf("got %d without region affixes, want %d", got, c.nilRA)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package coalition
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Report matches ref against domain, as MatchDetail does,
+// and describes the result as a JSON document,
+// for logging:
+// the inputs,
+// the normalized root phrase,
+// the outcome of each test with its reason and any evidence URL,
+// the score,
+// and its Confidence level according to m.Thresholds.
+//
+// The document is deterministic for a given match:
+// its fields are always in the same order,
+// and it has no timestamps or other volatile fields.
+// The description of the home-page fetch
+// (see Detail.Fetch)
+// appears only when a test fetched the page,
+// so it is absent when the network tests are disabled.
+func (m Matcher) Report(ctx context.Context, ref, domain string) ([]byte, error) {
+	detail, err := m.MatchDetail(ctx, ref, domain)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(m.newReport(ref, domain, detail), "", "  ")
+}
+
+type report struct {
+	Ref          string          `json:"ref"`
+	Domain       string          `json:"domain"`
+	MatchedRef   string          `json:"matched_ref,omitempty"`
+	Normalized   []string        `json:"normalized"`
+	Official     bool            `json:"official,omitempty"`
+	Outcomes     []reportOutcome `json:"outcomes"`
+	TooFewPassed bool            `json:"too_few_passed,omitempty"`
+	Fetch        *reportFetch    `json:"fetch,omitempty"`
+	EvidenceURL  string          `json:"evidence_url,omitempty"`
+	Score        float32         `json:"score"`
+	Confidence   string          `json:"confidence"`
+}
+
+type reportOutcome struct {
+	Test       string  `json:"test"`
+	Passed     bool    `json:"passed"`
+	Skipped    bool    `json:"skipped,omitempty"`
+	Reason     string  `json:"reason"`
+	URL        string  `json:"url,omitempty"`
+	Score      int     `json:"score"`
+	Confidence float32 `json:"confidence"`
+}
+
+type reportFetch struct {
+	URL         string `json:"url,omitempty"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Err         string `json:"error,omitempty"`
+}
+
+func (m Matcher) newReport(ref, domain string, detail *Detail) *report {
+	r := &report{
+		Ref:          ref,
+		Domain:       domain,
+		Normalized:   m.normalizedRootPhrase(detail.Ref),
+		Official:     detail.Official,
+		Outcomes:     []reportOutcome{},
+		TooFewPassed: detail.TooFewPassed,
+		Score:        detail.Score,
+		Confidence:   m.thresholds().Classify(detail.Score).String(),
+	}
+	if detail.Ref != ref {
+		r.MatchedRef = detail.Ref
+	}
+	if r.Normalized == nil {
+		r.Normalized = []string{}
+	}
+	for _, o := range detail.Outcomes {
+		r.Outcomes = append(r.Outcomes, reportOutcome{
+			Test:       o.Test,
+			Passed:     o.Passed,
+			Skipped:    o.Skipped,
+			Reason:     o.Reason(),
+			URL:        o.URL,
+			Score:      o.Score,
+			Confidence: o.Confidence,
+		})
+	}
+	if f := detail.Fetch; f != nil {
+		r.Fetch = &reportFetch{
+			URL:         f.URL,
+			StatusCode:  f.StatusCode,
+			ContentType: f.ContentType,
+		}
+		if f.Err != nil {
+			r.Fetch.Err = f.Err.Error()
+		}
+	}
+	r.EvidenceURL, _ = detail.EvidenceURL()
+	return r
+}
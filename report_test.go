@@ -0,0 +1,45 @@
+package coalition
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestReport(t *testing.T) {
+	m := NewMatcher()
+	delete(m.Scores, testWebPageRef) // No network requests during unit tests.
+
+	got, err := m.Report(context.Background(), "The Coalition Inc.", "coalitioninc.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reports are deterministic.
+	again, err := m.Report(context.Background(), "The Coalition Inc.", "coalitioninc.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, again) {
+		t.Errorf("reports differ:\n%s\n%s", got, again)
+	}
+
+	golden := filepath.Join("testdata", "report.json")
+	if *updateGolden {
+		if err := ioutil.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,66 @@
+package coalition
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+)
+
+// securityTxtPath is the location of a site's security.txt file
+// (see RFC 9116).
+const securityTxtPath = "/.well-known/security.txt"
+
+// maxSecurityTxtLen is the most of a security.txt file
+// that the SecurityTxt test reads.
+// Real ones are a few hundred bytes.
+const maxSecurityTxtLen = 32 * 1024
+
+func runSecurityTxtTest(ctx context.Context, m Matcher, in *matchInput) (bool, error) {
+	text, err := m.securityTxt(ctx, in)
+	if err != nil {
+		return false, err
+	}
+	return m.textMatcher(in, in.re).match(text), nil
+}
+
+// securityTxtKey is the key in a domainMemo
+// for the domain's security.txt file.
+type securityTxtKey struct{}
+
+// This returns the text of the domain's security.txt file,
+// or "" if it has none,
+// fetching it only once for a reference and its aliases.
+func (m Matcher) securityTxt(ctx context.Context, in *matchInput) (string, error) {
+	if text, ok := in.memo.get(securityTxtKey{}); ok {
+		return text.(string), nil
+	}
+
+	u := *in.webURL
+	u.Path, u.RawPath, u.RawQuery, u.Fragment = securityTxtPath, "", "", ""
+
+	resp, err := m.get(ctx, &u)
+	if err != nil {
+		return "", &WebFetchError{URL: u.String(), Err: err}
+	}
+	defer resp.Body.Close()
+
+	// Most sites have no security.txt,
+	// and many serve an HTML page
+	// (often the home page)
+	// in its place.
+	// Either way the test simply does not pass.
+	// Otherwise the content is plain text,
+	// whatever the Content-Type header says.
+	var text string
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); resp.StatusCode == http.StatusOK && mediaType != "text/html" {
+		b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxSecurityTxtLen))
+		if err != nil {
+			return "", &WebFetchError{URL: u.String(), Err: err}
+		}
+		text = string(b)
+	}
+	in.memo.set(securityTxtKey{}, text)
+	return text, nil
+}
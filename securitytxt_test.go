@@ -0,0 +1,56 @@
+package coalition
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSecurityTxtTest(t *testing.T) {
+	const securityTxt = `# Security contact for Coalition, Inc.
+Contact: mailto:security@example.com
+Expires: 2030-01-01T00:00:00.000Z
+Preferred-Languages: en
+`
+
+	mux := http.NewServeMux()
+	mux.Handle("/", pageHandler("text/html", "<html><body>Cyber insurance from our partners at Acme</body></html>"))
+	mux.Handle(securityTxtPath, pageHandler("text/plain; charset=utf-8", securityTxt))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	// This one serves its home page for every path.
+	htmlSrv, htmlDomain := newTestServer("text/html", "<html><body>Coalition, Inc.</body></html>")
+	defer htmlSrv.Close()
+
+	// This one has no security.txt.
+	noneSrv := httptest.NewServer(http.NotFoundHandler())
+	defer noneSrv.Close()
+
+	cases := []struct {
+		name, ref, domain string
+		want              int
+	}{
+		{name: "match", ref: "Coalition, Inc", domain: strings.TrimPrefix(srv.URL, "http://"), want: 10},
+		{name: "home_page_only", ref: "Acme", domain: strings.TrimPrefix(srv.URL, "http://"), want: 0},
+		{name: "html", ref: "Coalition, Inc", domain: htmlDomain, want: 0},
+		{name: "none", ref: "Coalition, Inc", domain: strings.TrimPrefix(noneSrv.URL, "http://"), want: 0},
+	}
+
+	matcher := NewMatcher()
+	matcher.Scores = map[testType]int{testSecurityTxt: 10}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := matcher.doMatch(context.Background(), c.ref, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
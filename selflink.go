@@ -0,0 +1,80 @@
+package coalition
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bobg/htree"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxNearTextLen is the longest text of a link's parent element
+// that counts as being near the link
+// (see linkTexts).
+// Longer text is more likely an incidental mention.
+const maxNearTextLen = 200
+
+func runSelfLinkedRefTest(ctx context.Context, m Matcher, in *matchInput) (bool, error) {
+	page, err := in.homePage(ctx, m)
+	if err != nil {
+		return false, err
+	}
+	tree := page.htmlTree()
+	if tree == nil {
+		return false, nil
+	}
+
+	var found bool
+	tm := m.textMatcher(in, in.re)
+	isSelfLink := func(n *html.Node) bool {
+		if n.DataAtom != atom.A {
+			return false
+		}
+		target, err := page.url.Parse(strings.TrimSpace(htree.ElAttr(n, "href")))
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+			return false
+		}
+		return m.sameSite(target, page.url)
+	}
+	htree.FindAllEls(tree, isSelfLink, func(n *html.Node) error {
+		for _, text := range linkTexts(n) {
+			if !found && tm.match(text) {
+				found = true
+			}
+		}
+		return nil
+	})
+	return found, nil
+}
+
+// linkTexts returns the text in and near the link element n:
+// its own text,
+// its title and aria-label attributes,
+// the alt text of images inside it
+// (as in a logo linking home),
+// and the text of its parent element,
+// if that's short.
+func linkTexts(n *html.Node) []string {
+	var result []string
+	if text, err := htree.Text(n); err == nil {
+		result = append(result, text)
+	}
+	for _, attr := range []string{"title", "aria-label"} {
+		if v := htree.ElAttr(n, attr); v != "" {
+			result = append(result, v)
+		}
+	}
+	htree.FindAllEls(n, func(n *html.Node) bool { return n.DataAtom == atom.Img }, func(img *html.Node) error {
+		if alt := htree.ElAttr(img, "alt"); alt != "" {
+			result = append(result, alt)
+		}
+		return nil
+	})
+	if p := n.Parent; p != nil && p.Type == html.ElementNode {
+		if text, err := htree.Text(p); err == nil && len(text) <= maxNearTextLen {
+			result = append(result, text)
+		}
+	}
+	return result
+}
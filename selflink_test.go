@@ -0,0 +1,67 @@
+package coalition
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSelfLinkedRefTest(t *testing.T) {
+	cases := []struct {
+		name, page string
+		want       int
+	}{
+		{
+			name: "logo",
+			page: `<html><body><header><a href="/"><img src="logo.png" alt="Coalition"></a></header><main>Welcome</main></body></html>`,
+			want: 20,
+		},
+		{
+			name: "link_text",
+			page: `<html><body><nav><a href="/about">About Coalition</a></nav></body></html>`,
+			want: 20,
+		},
+		{
+			name: "near",
+			page: `<html><body><footer><p>Coalition, Inc. | <a href="/privacy">Privacy</a></p></footer></body></html>`,
+			want: 20,
+		},
+		{
+			name: "folded",
+			page: `<html><body><nav><a href="/">CÖALITION</a></nav></body></html>`,
+			want: 20,
+		},
+		{
+			name: "other_site",
+			page: `<html><body><p>Read about Coalition in <a href="https://news.example.org/story">the news</a></p></body></html>`,
+			want: 0,
+		},
+		{
+			name: "no_link",
+			page: `<html><body><main>Our partners at Coalition offer cyber insurance.</main></body></html>`,
+			want: 0,
+		},
+		{
+			name: "not_http",
+			page: `<html><body><p><a href="mailto:info@example.com">Coalition</a></p></body></html>`,
+			want: 0,
+		},
+	}
+
+	matcher := NewMatcher()
+	matcher.Scores = map[testType]int{testSelfLinkedRef: 20}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv, domain := newTestServer("text/html", c.page)
+			defer srv.Close()
+
+			got, err := matcher.doMatch(context.Background(), "Coalition", domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
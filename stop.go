@@ -1,18 +1,197 @@
 package coalition
 
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+)
+
 // TODO: some "stop words" only work as prefixes (like "the"),
 // some only as suffixes (like "inc"),
 // and some only as infixes (like "and").
 // Make the logic reflect this.
 
 // Stopper can report whether a string is a "stop word."
+//
+// Its lookups are always of words that have already been case-folded
+// and, by default, stripped of diacritics
+// (see Normalize),
+// as in "societe" for "Société".
+// A Stopper whose own words are not in that form,
+// like one loaded from a list containing "Inc" or "GmbH",
+// will not find them
+// unless it is wrapped with FoldingStopper.
 type Stopper interface {
 	// IsStopWord reports whether the given string is a stop word.
 	IsStopWord(string) bool
 }
 
+// CanonicalStopper is a Stopper that can also map a word
+// to a canonical form,
+// as in "inc" for "incorporated"
+// or "and" for "&".
+// Where a Matcher or Normalize finds that its Stopper is a CanonicalStopper,
+// each word of a reference is replaced with its canonical form
+// (and dropped if that is empty)
+// before stop words are stripped,
+// and the string tests judge the words of a domain by their canonical forms.
+//
+// Canonical is also asked about each symbol in a reference
+// that is neither a letter nor a space,
+// one at a time.
+// A symbol is normally a separator between words,
+// but one whose canonical form differs from it becomes a word itself.
+type CanonicalStopper interface {
+	Stopper
+
+	// Canonical returns the canonical form of the given string,
+	// which is the string itself if it has no other,
+	// or "" if it should be dropped.
+	Canonical(string) string
+}
+
+// This reports whether s treats word as a stop word:
+// if s is a CanonicalStopper,
+// when the canonical form of word is empty or is itself a stop word,
+// and otherwise when word is a stop word.
+// A nil s has no stop words,
+// as with Normalize.
+func isStopWord(s Stopper, word string) bool {
+	if s == nil {
+		return false
+	}
+	if cs, ok := s.(CanonicalStopper); ok {
+		if word = cs.Canonical(word); word == "" {
+			return true
+		}
+	}
+	return s.IsStopWord(word)
+}
+
+// This splits s into words,
+// as Normalize does,
+// replacing each with its canonical form according to cs
+// and dropping those whose canonical form is empty.
+// A symbol whose canonical form differs from it
+// becomes a word too.
+func canonicalWords(s string, cs CanonicalStopper) []string {
+	var (
+		result []string
+		start  = -1
+	)
+	add := func(word string) {
+		if word != "" {
+			result = append(result, word)
+		}
+	}
+	for i, r := range s {
+		if unicode.IsLetter(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			add(cs.Canonical(s[start:i]))
+			start = -1
+		}
+		if unicode.IsSpace(r) {
+			continue
+		}
+		if sym := string(r); cs.Canonical(sym) != sym {
+			add(cs.Canonical(sym))
+		}
+	}
+	if start >= 0 {
+		add(cs.Canonical(s[start:]))
+	}
+	return result
+}
+
+// FoldingStopper returns a Stopper that reports the stop words of s
+// regardless of the case of the words s contains,
+// for use with Matcher.Stop, Normalize, etc.
+// (see Stopper).
+//
+// If s is one of this package's own Stoppers
+// (as from a MatcherConfig),
+// its words are folded exactly as Normalize folds a reference.
+// Otherwise its words can't be enumerated,
+// so each lookup tries the folded word as given,
+// in upper case ("LLC"),
+// and capitalized ("Inc").
+// If s is a CanonicalStopper,
+// so is the result,
+// and its Canonical method tries the same variants.
+func FoldingStopper(s Stopper) Stopper {
+	switch s := s.(type) {
+	case simpleStopper:
+		result := make(simpleStopper)
+		for word, ok := range s {
+			if ok {
+				result[foldStopWord(word)] = true
+			}
+		}
+		return result
+
+	case multiStopper:
+		result := make(multiStopper, 0, len(s))
+		for _, stop := range s {
+			result = append(result, FoldingStopper(stop))
+		}
+		return result
+
+	case CanonicalStopper:
+		return foldingCanonicalStopper{foldingStopper: foldingStopper{s: s}, cs: s}
+	}
+	return foldingStopper{s: s}
+}
+
+type foldingStopper struct {
+	s Stopper
+}
+
+func (s foldingStopper) IsStopWord(inp string) bool {
+	if s.s.IsStopWord(inp) || s.s.IsStopWord(strings.ToUpper(inp)) {
+		return true
+	}
+	r, n := utf8.DecodeRuneInString(inp)
+	return s.s.IsStopWord(string(unicode.ToUpper(r)) + inp[n:])
+}
+
+type foldingCanonicalStopper struct {
+	foldingStopper
+	cs CanonicalStopper
+}
+
+func (s foldingCanonicalStopper) Canonical(inp string) string {
+	r, n := utf8.DecodeRuneInString(inp)
+	for _, variant := range []string{inp, strings.ToUpper(inp), string(unicode.ToUpper(r)) + inp[n:]} {
+		if c := s.cs.Canonical(variant); c != variant {
+			return foldStopWord(c)
+		}
+	}
+	return inp
+}
+
+// This folds word the way Normalize folds the words of a reference,
+// by default.
+func foldStopWord(word string) string {
+	return foldDiacritics(foldCase(word, language.Und))
+}
+
 type simpleStopper map[string]bool
 
+// A leading "the" is stripped from references
+// whether it's an article ("The Coalition")
+// or part of the brand ("The North Face").
+// Either way the domain may include it or not:
+// "northface" is found in "thenorthface.com",
+// and the extra "the" is an ignorable affix
+// (see Matcher.isIgnorableAffix),
+// so both that and "northface.com" fully match the root phrase.
 var defaultStopper = simpleStopper{
 	"the": true,
 	"inc": true,
@@ -26,3 +205,16 @@ var defaultStopper = simpleStopper{
 func (s simpleStopper) IsStopWord(inp string) bool {
 	return s[inp]
 }
+
+// multiStopper reports a word as a stop word
+// if any of its Stoppers does.
+type multiStopper []Stopper
+
+func (s multiStopper) IsStopWord(inp string) bool {
+	for _, stop := range s {
+		if stop.IsStopWord(inp) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package coalition
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// listStopper is a Stopper that is not one of this package's own,
+// so FoldingStopper can't enumerate its words.
+type listStopper []string
+
+func (s listStopper) IsStopWord(inp string) bool {
+	for _, word := range s {
+		if word == inp {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFoldingStopper(t *testing.T) {
+	words := []string{"The", "Inc", "GmbH", "LLC", "Société"}
+
+	simple := make(simpleStopper)
+	for _, word := range words {
+		simple[word] = true
+	}
+
+	cases := []struct {
+		name string
+		stop Stopper
+		want []string
+	}{
+		{name: "simple", stop: FoldingStopper(simple), want: []string{"coalition"}},
+		{name: "multi", stop: FoldingStopper(multiStopper{simpleStopper{"co": true}, simple}), want: []string{"coalition"}},
+
+		// "GmbH" and "Société" are neither upper case nor capitalized.
+		{name: "other", stop: FoldingStopper(listStopper(words)), want: []string{"societe", "coalition", "gmbh"}},
+
+		{name: "unfolded", stop: simple, want: []string{"the", "societe", "coalition", "gmbh", "inc", "llc"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := Normalize("The Société Coalition GmbH Inc LLC", WithStopper(c.stop))
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+
+	t.Run("config", func(t *testing.T) {
+		m, err := LoadMatcherConfig(strings.NewReader(`{"stop_words": ["The", "Inc"]}`), "json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		delete(m.Scores, testWebPageRef) // No network requests during unit tests.
+
+		got, err := m.doMatch(context.Background(), "The Coalition Inc", "coalition.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 50 {
+			t.Errorf("got %d, want 50", got)
+		}
+	})
+}
+
+// canonicalStopper is a CanonicalStopper
+// that drops "incorporated" and spells out "&".
+type canonicalStopper struct {
+	Stopper
+}
+
+func (s canonicalStopper) Canonical(inp string) string {
+	switch inp {
+	case "incorporated":
+		return ""
+	case "&":
+		return "and"
+	}
+	return inp
+}
+
+func TestCanonicalStopper(t *testing.T) {
+	stop := canonicalStopper{Stopper: simpleStopper{"the": true}}
+
+	cases := []struct {
+		ref  string
+		want []string
+	}{
+		{ref: "Coalition Incorporated", want: []string{"coalition"}},
+		{ref: "Incorporated Coalition Research", want: []string{"coalition", "research"}},
+		{ref: "Smith & Wesson", want: []string{"smith", "and", "wesson"}},
+		{ref: "Smith&Wesson", want: []string{"smith", "and", "wesson"}},
+		{ref: "The Smith-Wesson Co.", want: []string{"smith", "wesson", "co"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			got := Normalize(c.ref, WithStopper(stop))
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+
+	t.Run("folding", func(t *testing.T) {
+		upper := upperCanonicalStopper{}
+		got := Normalize("Coalition Incorporated", WithStopper(upper))
+		if want := []string{"coalition", "incorporated"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("unfolded: got %v, want %v", got, want)
+		}
+		got = Normalize("Coalition Incorporated", WithStopper(FoldingStopper(upper)))
+		if want := []string{"coalition"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("folded: got %v, want %v", got, want)
+		}
+	})
+
+	matchCases := []struct {
+		ref, domain string
+		want        int
+	}{
+		{ref: "Smith & Wesson", domain: "smithandwesson.com", want: 50},
+		{ref: "Coalition Incorporated", domain: "coalition.com", want: 50},
+
+		// The affix "incorporated" is dropped, so is ignorable.
+		{ref: "Coalition", domain: "coalitionincorporated.com", want: 50},
+	}
+
+	for _, c := range matchCases {
+		t.Run(c.domain, func(t *testing.T) {
+			m := NewMatcher()
+			m.Stop = canonicalStopper{Stopper: defaultStopper}
+			delete(m.Scores, testWebPageRef) // No network requests during unit tests.
+
+			got, err := m.doMatch(context.Background(), c.ref, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+// upperCanonicalStopper is a CanonicalStopper
+// whose words are in upper case,
+// so it needs FoldingStopper.
+type upperCanonicalStopper struct {
+	listStopper
+}
+
+func (s upperCanonicalStopper) Canonical(inp string) string {
+	if inp == "INCORPORATED" {
+		return ""
+	}
+	return inp
+}
@@ -0,0 +1,119 @@
+package coalition
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// streamWindow is the amount of a page's text,
+// in bytes,
+// that streamMatch keeps for matching.
+// A match spanning more than this is missed.
+const streamWindow = 4096
+
+// streamMatch reports whether tm matches the text of the HTML in r,
+// as doWebPageRefTest does with the parsed page,
+// but without parsing it into a tree:
+// the text is extracted token by token
+// and matched a window at a time,
+// each window overlapping the one before by streamWindow bytes,
+// stopping at the first match.
+// Each window is folded (see textMatcher) before it is matched.
+// As with htree.Text,
+// <script> and <style> content is skipped
+// and <br> is a newline.
+func streamMatch(r io.Reader, tm textMatcher) bool {
+	var (
+		z    = html.NewTokenizer(r)
+		buf  []byte
+		skip atom.Atom // the <script> or <style> element being skipped, if any
+	)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			// EOF or a read error.
+			return tm.match(string(buf))
+
+		case html.TextToken:
+			if skip != 0 {
+				continue
+			}
+			buf = append(buf, z.Text()...)
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			switch a := atom.Lookup(name); a {
+			case atom.Script, atom.Style:
+				skip = a
+			case atom.Br:
+				buf = append(buf, '\n')
+			}
+			continue
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if atom.Lookup(name) == skip {
+				skip = 0
+			}
+			continue
+
+		default:
+			continue
+		}
+
+		if len(buf) < 2*streamWindow {
+			continue
+		}
+		if tm.match(string(buf)) {
+			return true
+		}
+		keep := len(buf) - streamWindow
+		for keep > 0 && !utf8.RuneStart(buf[keep]) {
+			keep--
+		}
+		buf = append(buf[:0], buf[keep:]...)
+	}
+}
+
+// streamMetaRefresh returns the content attribute
+// of the first <meta http-equiv="refresh"> element
+// in the HTML in r,
+// without parsing it into a tree.
+// It looks no further than the start of the <body>.
+// It is false if there is none.
+func streamMetaRefresh(r io.Reader) (string, bool) {
+	z := html.NewTokenizer(r)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return "", false
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Body:
+				return "", false
+			case atom.Meta:
+				var refresh bool
+				var content string
+				for hasAttr {
+					var key, val []byte
+					key, val, hasAttr = z.TagAttr()
+					switch string(key) {
+					case "http-equiv":
+						refresh = strings.EqualFold(string(val), "refresh")
+					case "content":
+						content = string(val)
+					}
+				}
+				if refresh {
+					return content, true
+				}
+			}
+		}
+	}
+}
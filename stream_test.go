@@ -0,0 +1,123 @@
+package coalition
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// This returns the textMatcher that WebPageRef uses for ref.
+func refTextMatcher(tb testing.TB, ref string) textMatcher {
+	m := NewMatcher()
+	in, err := m.newMatchInput(ref, "example.com")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return m.textMatcher(in, in.re)
+}
+
+func TestStreamMatch(t *testing.T) {
+	tm := refTextMatcher(t, "Coalition, Inc.")
+
+	cases := []struct {
+		name, page string
+		want       bool
+	}{
+		{name: "plain", page: `<html><body><p>Welcome to Coalition</p></body></html>`, want: true},
+		{name: "absent", page: `<html><body><p>Welcome</p></body></html>`},
+		{name: "split", page: `<html><body><p>Co<b>ali</b>tion</p></body></html>`, want: true},
+		{name: "entity", page: `<html><body><p>&#67;oalition</p></body></html>`, want: true},
+		{name: "script", page: `<html><head><script>var coalition = 1;</script></head><body></body></html>`},
+		{name: "style", page: `<html><head><style>.coalition {}</style></head><body></body></html>`},
+		{name: "br", page: `<html><body>Welcome<br>Coalition</body></html>`, want: true},
+		{name: "attribute", page: `<html><body><a title="Coalition">Welcome</a></body></html>`},
+		{name: "unclosed", page: `<p>Coalition`, want: true},
+		{name: "accented", page: `<html><body><p>Welcome to CÖALITION</p></body></html>`, want: true},
+		{name: "far", page: `<html><body>` + strings.Repeat("<p>Welcome</p>", 1000) + `<p>Coalition</p></body></html>`, want: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tree, err := html.Parse(strings.NewReader(c.page))
+			if err != nil {
+				t.Fatal(err)
+			}
+			text, err := extractText(tree)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tm.match(text); got != c.want {
+				t.Errorf("parsed: got %v, want %v", got, c.want)
+			}
+			if got := streamMatch(strings.NewReader(c.page), tm); got != c.want {
+				t.Errorf("streamed: got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestStreamPageText(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/", pageHandler("text/html", `<html><head><meta http-equiv="refresh" content="0; url=/home"></head><body></body></html>`))
+	mux.Handle("/home", pageHandler("text/html", `<html><head><title>Home</title></head><body>Welcome to Coalition</body></html>`))
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	domain := strings.TrimPrefix(srv.URL, "http://")
+
+	for _, stream := range []bool{false, true} {
+		t.Run(fmt.Sprintf("stream_%v", stream), func(t *testing.T) {
+			matcher := NewMatcher()
+			matcher.StreamPageText = stream
+
+			got, err := matcher.doMatch(context.Background(), "Coalition", domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != 50 {
+				t.Errorf("got %d, want 50", got)
+			}
+		})
+	}
+}
+
+func BenchmarkWebPageRef(b *testing.B) {
+	var buf bytes.Buffer
+	buf.WriteString(`<html><head><title>Welcome</title></head><body>`)
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&buf, `<div class="item"><a href="/item/%d">Item %d</a> <span>in stock</span></div>`, i, i)
+	}
+	buf.WriteString(`<footer>Copyright Coalition, Inc.</footer></body></html>`)
+	raw := buf.Bytes()
+
+	tm := refTextMatcher(b, "Coalition, Inc.")
+
+	b.Run("tree", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			tree, err := html.Parse(bytes.NewReader(raw))
+			if err != nil {
+				b.Fatal(err)
+			}
+			if !doWebPageRefTest(&webPage{tree: tree}, nil, tm) {
+				b.Fatal("no match")
+			}
+		}
+	})
+
+	b.Run("stream", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if !doWebPageRefTest(&webPage{raw: raw}, nil, tm) {
+				b.Fatal("no match")
+			}
+		}
+	})
+}
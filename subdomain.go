@@ -0,0 +1,119 @@
+package coalition
+
+import (
+	"net"
+	"net/http/cookiejar"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// SubdomainPolicy says how the string tests
+// (RootPhrase, AnyRootWord, MisspelledRootPhrase, and SignificantAffixes)
+// treat the labels of a domain below its registrable part
+// (see Matcher.SubdomainPolicy).
+// The registrable part is the public suffix plus one label,
+// as in "example.co.uk" for "coalition.example.co.uk"
+// (see Matcher.PublicSuffixList).
+type SubdomainPolicy int
+
+const (
+	// SubdomainInclude makes subdomain labels eligible for matching,
+	// like the registrable label.
+	// So "coalition.example.com" passes RootPhrase for "Coalition".
+	SubdomainInclude SubdomainPolicy = iota
+
+	// SubdomainIgnore restricts the string tests to the registrable part of the domain.
+	// So "coalition.example.com" does not pass RootPhrase for "Coalition",
+	// but "www.coalition.com" does.
+	SubdomainIgnore
+
+	// SubdomainIncludeButPenalize makes subdomain labels eligible for matching,
+	// but a positive test that passes only because of one
+	// earns just Matcher.SubdomainWeight of its score.
+	// SignificantAffixes examines the whole domain, as with SubdomainInclude.
+	SubdomainIncludeButPenalize
+)
+
+// DefaultSubdomainWeight is the fraction of their scores
+// that the RootPhrase, AnyRootWord, and MisspelledRootPhrase tests earn
+// for a match in a subdomain label
+// under SubdomainIncludeButPenalize,
+// when Matcher.SubdomainWeight is zero.
+const DefaultSubdomainWeight = 0.5
+
+// This grades a test that reports, with found,
+// whether it passes for a given domain,
+// by calling it on in.domain or on the registrable part of it
+// as m.SubdomainPolicy requires.
+func (m Matcher) subdomainGrade(in *matchInput, found func(domain string) bool) float32 {
+	return m.subdomainScale(in, func(domain string) float32 {
+		if found(domain) {
+			return 1
+		}
+		return 0
+	})
+}
+
+// This is like subdomainGrade
+// but for a test that grades a given domain itself,
+// from 0 to 1.
+func (m Matcher) subdomainScale(in *matchInput, grade func(domain string) float32) float32 {
+	if m.SubdomainPolicy == SubdomainInclude {
+		return grade(in.domain)
+	}
+
+	if g := grade(m.registrable(in.domain)); g > 0 {
+		return g
+	}
+	if m.SubdomainPolicy == SubdomainIncludeButPenalize {
+		weight := m.SubdomainWeight
+		if weight == 0 {
+			weight = DefaultSubdomainWeight
+		}
+		return weight * grade(in.domain)
+	}
+	return 0
+}
+
+// This returns the registrable part of domain,
+// or domain itself if it has none
+// (as with an IP address).
+func (m Matcher) registrable(domain string) string {
+	if net.ParseIP(domain) != nil {
+		return domain
+	}
+	if site, ok := m.effectiveTLDPlusOne(domain); ok {
+		return site
+	}
+	return domain
+}
+
+// This returns the public suffix of domain plus one more label,
+// as in "example.co.uk" for "www.example.co.uk",
+// according to m.PublicSuffixList.
+// It is false if domain has no such part,
+// as when it is itself a public suffix.
+// This is publicsuffix.EffectiveTLDPlusOne
+// for an arbitrary list.
+func (m Matcher) effectiveTLDPlusOne(domain string) (string, bool) {
+	if strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") || strings.Contains(domain, "..") {
+		return "", false
+	}
+	suffix := m.publicSuffixList().PublicSuffix(domain)
+	if len(domain) <= len(suffix) {
+		return "", false
+	}
+	i := len(domain) - len(suffix) - 1
+	if domain[i] != '.' {
+		return "", false
+	}
+	return domain[1+strings.LastIndex(domain[:i], "."):], true
+}
+
+func (m Matcher) publicSuffixList() cookiejar.PublicSuffixList {
+	if m.PublicSuffixList != nil {
+		return m.PublicSuffixList
+	}
+	return publicsuffix.List
+}
@@ -0,0 +1,115 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSubdomainPolicy(t *testing.T) {
+	cases := []struct {
+		policy SubdomainPolicy
+		weight float32
+		domain string
+		scores map[testType]int
+		want   int
+	}{
+		{policy: SubdomainInclude, domain: "coalition.example.com", want: 50},
+		{policy: SubdomainIgnore, domain: "coalition.example.com", want: 0},
+		{policy: SubdomainIncludeButPenalize, domain: "coalition.example.com", want: 25},
+		{policy: SubdomainIncludeButPenalize, weight: 0.2, domain: "coalition.example.com", want: 10},
+
+		// A match in the registrable label is never penalized.
+		{policy: SubdomainIgnore, domain: "coalitioninc.com", want: 50},
+		{policy: SubdomainIgnore, domain: "shop.coalitioninc.com", want: 50},
+		{policy: SubdomainIgnore, domain: "coalition.co.uk", want: 50},
+		{policy: SubdomainIncludeButPenalize, domain: "www.coalitioninc.com", want: 50},
+
+		// SignificantAffixes ignores subdomain labels under SubdomainIgnore.
+		{policy: SubdomainInclude, domain: "coalitionrutabaga.coalitioninc.com", want: 40},
+		{policy: SubdomainIgnore, domain: "coalitionrutabaga.coalitioninc.com", want: 50},
+
+		// AnyRootWord follows the policy too.
+		{policy: SubdomainInclude, domain: "coalitionhq.example.com", scores: map[testType]int{testAnyRootWord: 10}, want: 10},
+		{policy: SubdomainIgnore, domain: "coalitionhq.example.com", scores: map[testType]int{testAnyRootWord: 10}, want: 0},
+		{policy: SubdomainIncludeButPenalize, domain: "coalitionhq.example.com", scores: map[testType]int{testAnyRootWord: 10}, want: 5},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case_%02d", i+1), func(t *testing.T) {
+			matcher := NewMatcher()
+			delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+			if c.scores != nil {
+				matcher.Scores = c.scores
+			}
+			matcher.SubdomainPolicy = c.policy
+			matcher.SubdomainWeight = c.weight
+
+			got, err := matcher.doMatch(context.Background(), "Coalition, Inc", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+// suffixList is a public suffix list
+// containing only its own entries
+// plus every top-level domain.
+type suffixList []string
+
+func (l suffixList) PublicSuffix(domain string) string {
+	for _, suffix := range l {
+		if domain == suffix || strings.HasSuffix(domain, "."+suffix) {
+			return suffix
+		}
+	}
+	return domain[strings.LastIndex(domain, ".")+1:]
+}
+
+func (l suffixList) String() string {
+	return "test list"
+}
+
+func TestPublicSuffixList(t *testing.T) {
+	cases := []struct {
+		name   string
+		list   suffixList
+		domain string
+		want   int
+	}{
+		// The default list has github.io as a public suffix,
+		// so "coalition" is the registrable label.
+		{name: "default", domain: "coalition.github.io", want: 50},
+
+		// Without it, "coalition" is just a subdomain label of github.io.
+		{name: "no_github_io", list: suffixList{}, domain: "coalition.github.io", want: 0},
+
+		{name: "no_github_io_www", list: suffixList{}, domain: "www.coalition.github.io", want: 0},
+		{name: "example_com", list: suffixList{"example.com"}, domain: "coalition.example.com", want: 50},
+		{name: "example_com_deeper", list: suffixList{"example.com"}, domain: "coalition.shop.example.com", want: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			matcher := NewMatcher()
+			delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+			matcher.SubdomainPolicy = SubdomainIgnore
+			if c.list != nil {
+				matcher.PublicSuffixList = c.list
+			}
+
+			got, err := matcher.doMatch(context.Background(), "Coalition", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
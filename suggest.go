@@ -0,0 +1,71 @@
+package coalition
+
+import "strings"
+
+// suggestionPrefixes and suggestionSuffixes are the words SuggestLabels adds to a root phrase,
+// as in "getcoalition" or "coalitioninc".
+// Only those that the Matcher treats as stop words are used,
+// so that the results are ones it would match.
+var (
+	suggestionPrefixes = []string{"get", "try", "the"}
+	suggestionSuffixes = []string{"inc", "co", "llc"}
+)
+
+// SuggestLabels returns candidate registrable labels
+// (domain names without their TLDs)
+// for the organization named by ref,
+// most likely first.
+// They are built from the normalized root phrase of ref
+// (e.g. {"coalition", "security"} for "Coalition Security, Inc."):
+// the words joined together ("coalitionsecurity")
+// and with hyphens ("coalition-security");
+// the joined words with stop words added,
+// as in "getcoalitionsecurity" and "coalitionsecurityinc";
+// and, if there are several words,
+// their acronym ("cs").
+// This is useful for deciding which domains to check.
+func (m Matcher) SuggestLabels(ref string) []string {
+	norm := m.normalizedRootPhrase(ref)
+	if len(norm) == 0 {
+		return nil
+	}
+
+	var (
+		result []string
+		seen   = make(map[string]bool)
+	)
+	add := func(label string) {
+		if !seen[label] {
+			seen[label] = true
+			result = append(result, label)
+		}
+	}
+
+	joined := strings.Join(norm, "")
+	add(joined)
+	if len(norm) > 1 {
+		add(strings.Join(norm, "-"))
+	}
+	for _, prefix := range suggestionPrefixes {
+		if m.isStopWord(prefix) {
+			add(prefix + joined)
+		}
+	}
+	for _, suffix := range suggestionSuffixes {
+		if m.isStopWord(suffix) {
+			add(joined + suffix)
+		}
+	}
+	if len(norm) > 1 {
+		var acronym strings.Builder
+		for _, word := range norm {
+			for _, r := range word {
+				acronym.WriteRune(r)
+				break
+			}
+		}
+		add(acronym.String())
+	}
+
+	return result
+}
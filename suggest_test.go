@@ -0,0 +1,56 @@
+package coalition
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSuggestLabels(t *testing.T) {
+	matcher := NewMatcher()
+
+	got := matcher.SuggestLabels("Coalition Security, Inc.")
+	want := []string{
+		"coalitionsecurity",
+		"coalition-security",
+		"getcoalitionsecurity",
+		"trycoalitionsecurity",
+		"thecoalitionsecurity",
+		"coalitionsecurityinc",
+		"coalitionsecurityco",
+		"coalitionsecurityllc",
+		"cs",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Apart from the acronym,
+	// the suggestions all match the reference.
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+	for _, label := range got[:len(got)-1] {
+		score, err := matcher.doMatch(context.Background(), "Coalition Security, Inc.", label+".com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if score <= 0 {
+			t.Errorf("got score %d for %s.com, want a positive one", score, label)
+		}
+	}
+
+	t.Run("one_word", func(t *testing.T) {
+		matcher := NewMatcher()
+		matcher.Stop = simpleStopper{"get": true}
+		got := matcher.SuggestLabels("Coalition")
+		want := []string{"coalition", "getcoalition"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		if got := matcher.SuggestLabels("..."); len(got) != 0 {
+			t.Errorf("got %v, want nothing", got)
+		}
+	})
+}
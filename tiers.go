@@ -0,0 +1,108 @@
+package coalition
+
+import "strings"
+
+// RootPhraseTiers gives the fraction of its score that the RootPhrase test earns
+// for each way the root phrase can appear in a domain,
+// from the strongest to the weakest.
+// See Matcher.RootPhraseTiers.
+type RootPhraseTiers struct {
+	// Exact is for a label that consists of the root phrase and nothing else,
+	// apart from separators,
+	// as in "coalition.com",
+	// or "coalition-security.com" for "Coalition Security".
+	Exact float32
+
+	// Word is for a label in which the root phrase is one or more whole words
+	// (see DomainTokenizer)
+	// among others,
+	// as in "coalition-group.com".
+	Word float32
+
+	// Substring is for the root phrase appearing anywhere else:
+	// inside a longer word,
+	// as in "getcoalitionnow.com",
+	// or split across labels,
+	// as in "coalition.security.example".
+	Substring float32
+}
+
+// DefaultRootPhraseTiers are the tiers used by a Matcher whose RootPhraseTiers field is zero.
+// Every tier earns the full score.
+var DefaultRootPhraseTiers = RootPhraseTiers{
+	Exact:     1,
+	Word:      1,
+	Substring: 1,
+}
+
+func (m Matcher) rootPhraseTiers() RootPhraseTiers {
+	if m.RootPhraseTiers == (RootPhraseTiers{}) {
+		return DefaultRootPhraseTiers
+	}
+	return m.RootPhraseTiers
+}
+
+// This grades the RootPhrase test for domain
+// according to the strongest tier
+// (see RootPhraseTiers)
+// in which the root phrase appears,
+// in one pass over its labels.
+func (m Matcher) gradeRootPhrase(domain string, in *matchInput) float32 {
+	if in.joined == "" {
+		return 0
+	}
+	tiers := m.rootPhraseTiers()
+
+	var word bool
+	for _, label := range strings.Split(domain, ".") {
+		tokens := m.domainTokens(label)
+		if isPhraseSpan(tokens, in) {
+			return tiers.Exact
+		}
+		if !word && hasPhraseSpan(tokens, in) {
+			word = true
+		}
+	}
+	if word {
+		return tiers.Word
+	}
+	if m.containsWord(domain, in.joined) || m.containsSeparatedPhrase(domain, in.norm) {
+		return tiers.Substring
+	}
+	return 0
+}
+
+// This reports whether some run of consecutive tokens
+// is the root phrase
+// (see isPhraseSpan).
+func hasPhraseSpan(tokens []string, in *matchInput) bool {
+	for i := range tokens {
+		if tokens[i] == in.joined {
+			return true
+		}
+		if end := i + len(in.norm); end <= len(tokens) && isPhraseSpan(tokens[i:end], in) {
+			return true
+		}
+	}
+	return false
+}
+
+// This reports whether tokens are the root phrase:
+// either its words run together in a single token,
+// as in "coalitionsecurity",
+// or one token per word,
+// as in "coalition-security".
+func isPhraseSpan(tokens []string, in *matchInput) bool {
+	if len(tokens) == 1 {
+		return tokens[0] == in.joined
+	}
+	if len(tokens) != len(in.norm) {
+		return false
+	}
+	for i, token := range tokens {
+		if token != in.norm[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestRootPhraseTiers(t *testing.T) {
+	tiers := RootPhraseTiers{Exact: 1, Word: 0.6, Substring: 0.2}
+
+	cases := []struct {
+		ref, domain string
+		tiers       RootPhraseTiers
+		want        int
+	}{
+		{ref: "Coalition", domain: "coalition.com", want: 50},
+		{ref: "Coalition", domain: "coalition-group.com", want: 50},
+		{ref: "Coalition", domain: "getcoalitionnow.com", want: 50},
+
+		{ref: "Coalition", domain: "coalition.com", tiers: tiers, want: 50},
+		{ref: "Coalition", domain: "www.coalition.com", tiers: tiers, want: 50},
+		{ref: "Coalition", domain: "coalition-group.com", tiers: tiers, want: 30},
+		{ref: "Coalition", domain: "getcoalitionnow.com", tiers: tiers, want: 10},
+		{ref: "Coalition", domain: "coalition-group.coalition.com", tiers: tiers, want: 50},
+
+		{ref: "Coalition Security", domain: "coalitionsecurity.com", tiers: tiers, want: 50},
+		{ref: "Coalition Security", domain: "coalition-security.com", tiers: tiers, want: 50},
+		{ref: "Coalition Security", domain: "coalition-security-group.com", tiers: tiers, want: 30},
+		{ref: "Coalition Security", domain: "thecoalitionsecurity.com", tiers: tiers, want: 10},
+		{ref: "Coalition Security", domain: "coalition.security.example", tiers: tiers, want: 10},
+		{ref: "Coalition Security", domain: "coali-tionsecurity.com", tiers: tiers, want: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%s_%s_%v", c.ref, c.domain, c.tiers), func(t *testing.T) {
+			matcher := NewMatcher()
+			delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+			matcher.RootPhraseTiers = c.tiers
+
+			_, detail, err := matcher.doMatchDetail(context.Background(), c.ref, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, o := range detail.Outcomes {
+				if o.Test == string(TestRootPhrase) && o.Score != c.want {
+					t.Errorf("got RootPhrase score %d, want %d", o.Score, c.want)
+				}
+			}
+		})
+	}
+}
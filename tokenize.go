@@ -0,0 +1,43 @@
+package coalition
+
+import "strings"
+
+// DomainTokenizer splits a domain label,
+// or part of one,
+// into words.
+// The label is already lowercased and contains no dots.
+type DomainTokenizer interface {
+	Tokens(label string) []string
+}
+
+// DefaultSeparators are the characters that separate words in a domain label
+// when Matcher.Separators is empty.
+// Underscores are not permitted in host names,
+// but they do appear in other DNS names,
+// and some registrars accept them.
+const DefaultSeparators = "-_"
+
+// defaultTokenizer splits labels on the characters in separators
+// (or, if that is empty, DefaultSeparators),
+// so "coalition-security" and "coalition_security" become {"coalition", "security"}.
+// It makes no attempt to split runs of letters like "coalitionsecurity".
+type defaultTokenizer struct {
+	separators string
+}
+
+func (t defaultTokenizer) Tokens(label string) []string {
+	separators := t.separators
+	if separators == "" {
+		separators = DefaultSeparators
+	}
+	return strings.FieldsFunc(label, func(r rune) bool {
+		return strings.ContainsRune(separators, r)
+	})
+}
+
+func (m Matcher) domainTokens(label string) []string {
+	if m.DomainTokenizer != nil {
+		return m.DomainTokenizer.Tokens(label)
+	}
+	return defaultTokenizer{separators: m.Separators}.Tokens(label)
+}
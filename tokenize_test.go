@@ -0,0 +1,112 @@
+package coalition
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// digitTokenizer splits labels at letter/digit boundaries as well as on separators,
+// discarding the digits.
+type digitTokenizer struct{}
+
+func (digitTokenizer) Tokens(label string) []string {
+	return strings.FieldsFunc(label, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
+
+func TestDomainTokenizer(t *testing.T) {
+	cases := []struct {
+		tokenizer DomainTokenizer
+		want      int
+	}{
+		{tokenizer: nil, want: 40},              // "inc2" is a significant affix
+		{tokenizer: digitTokenizer{}, want: 50}, // "inc" is a stop word
+	}
+
+	for _, c := range cases {
+		matcher := NewMatcher()
+		delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+		matcher.DomainTokenizer = c.tokenizer
+		matcher.TrailingNumbers = NumbersKeep // Don't let NumbersIgnore drop the "2" in "inc2".
+
+		got, err := matcher.doMatch(context.Background(), "Coalition", "coalition-inc2.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != c.want {
+			t.Errorf("with tokenizer %T: got %d, want %d", c.tokenizer, got, c.want)
+		}
+	}
+}
+
+func TestDefaultTokenizer(t *testing.T) {
+	got := defaultTokenizer{}.Tokens("the-coalition_group2")
+	want := []string{"the", "coalition", "group2"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSeparators(t *testing.T) {
+	t.Run("tokens", func(t *testing.T) {
+		cases := []struct {
+			separators, label string
+			want              []string
+		}{
+			{label: "coalition_security", want: []string{"coalition", "security"}},
+			{label: "coalition-security", want: []string{"coalition", "security"}},
+			{separators: "-", label: "coalition_security", want: []string{"coalition_security"}},
+			{separators: "-", label: "coalition-security", want: []string{"coalition", "security"}},
+		}
+		for _, c := range cases {
+			matcher := NewMatcher()
+			matcher.Separators = c.separators
+			got := matcher.domainTokens(c.label)
+			if strings.Join(got, ",") != strings.Join(c.want, ",") {
+				t.Errorf("with separators %q: got %v for %s, want %v", c.separators, got, c.label, c.want)
+			}
+		}
+	})
+
+	cases := []struct {
+		separators string
+		ref        string
+		domain     string
+		scores     map[testType]int
+		want       int
+	}{
+		// "_the" is an ignorable affix only if "_" separates words.
+		{ref: "Coalition, Inc", domain: "coalition_the.com", want: 50},
+		{separators: "-", ref: "Coalition, Inc", domain: "coalition_the.com", want: 40},
+
+		// Underscore-separated words.
+		{ref: "Coalition Rutabaga", domain: "xyzzy_coalition.com", scores: map[testType]int{testLeadWord: 10}, want: 10},
+		{separators: "-", ref: "Coalition Rutabaga", domain: "xyzzy_coalition.com", scores: map[testType]int{testLeadWord: 10}, want: 0},
+
+		// Dot-separated words are in separate labels.
+		{ref: "Coalition Rutabaga", domain: "xyzzy.coalition.com", scores: map[testType]int{testLeadWord: 10}, want: 10},
+		{ref: "Coalition Rutabaga", domain: "rutabaga.coalition.com", scores: map[testType]int{testAnyRootWord: 10}, want: 10},
+	}
+
+	for _, c := range cases {
+		t.Run(c.domain, func(t *testing.T) {
+			matcher := NewMatcher()
+			delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+			if c.scores != nil {
+				matcher.Scores = c.scores
+			}
+			matcher.Separators = c.separators
+
+			got, err := matcher.doMatch(context.Background(), c.ref, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("with separators %q: got %d, want %d", c.separators, got, c.want)
+			}
+		})
+	}
+}
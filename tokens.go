@@ -0,0 +1,41 @@
+package coalition
+
+import (
+	"context"
+	"strings"
+)
+
+// MatchTokens is like Match
+// but takes the root phrase of the organization's name
+// as a list of tokens,
+// which it uses as is,
+// without normalization.
+// The caller is responsible for lowercasing them,
+// removing stop words and punctuation,
+// and so on,
+// as Normalize would
+// (e.g. {"coalition"} for "Coalition, Inc.").
+// Aliases (see Matcher.Aliases) are still normalized as usual.
+func (m Matcher) MatchTokens(tokens []string, domain string) (float32, error) {
+	return m.MatchTokensContext(context.Background(), tokens, domain)
+}
+
+// MatchTokensContext is like MatchTokens but takes a context
+// (see MatchContext).
+func (m Matcher) MatchTokensContext(ctx context.Context, tokens []string, domain string) (float32, error) {
+	result, err := m.matchTokens(ctx, tokens, domain)
+	m.metrics().Match(result, err)
+	return result, err
+}
+
+func (m Matcher) matchTokens(ctx context.Context, tokens []string, domain string) (float32, error) {
+	in, err := m.newMatchInputNorm(strings.Join(tokens, " "), tokens, domain)
+	if err != nil {
+		return 0, err
+	}
+	score, _, err := m.doMatchInput(ctx, in, domain)
+	if err != nil {
+		return 0, err
+	}
+	return m.scale(score), nil
+}
@@ -0,0 +1,58 @@
+package coalition
+
+import "testing"
+
+func TestMatchTokens(t *testing.T) {
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+
+	cases := []struct {
+		ref    string
+		tokens []string
+		domain string
+	}{
+		{ref: "Coalition, Inc.", tokens: []string{"coalition"}, domain: "coalitioninc.com"},
+		{ref: "The Société Générale", tokens: []string{"societe", "generale"}, domain: "societegenerale.fr"},
+		{ref: "Sanford and Son", tokens: []string{"sanford", "and", "son"}, domain: "sanford-son.com"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			fromRef, err := matcher.Match(c.ref, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fromTokens, err := matcher.MatchTokens(c.tokens, c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fromTokens != fromRef {
+				t.Errorf("got %v from tokens, want %v (from reference string)", fromTokens, fromRef)
+			}
+		})
+	}
+
+	t.Run("preserved", func(t *testing.T) {
+		// The tokens keep their diacritic,
+		// so the domain must too.
+		got, err := matcher.MatchTokens([]string{"société"}, "société.fr")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := matcher.Match("Société", "société.fr")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("got %v from tokens, want %v (from reference string)", got, want)
+		}
+
+		got, err = matcher.MatchTokens([]string{"société"}, "societe.fr")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got >= want {
+			t.Errorf("got %v from tokens without the diacritic in the domain, want less than %v", got, want)
+		}
+	})
+}
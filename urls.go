@@ -0,0 +1,120 @@
+package coalition
+
+import (
+	"context"
+	"errors"
+)
+
+// MatchURLs is like MatchContext
+// but runs the tests that examine a web page
+// (WebPageRef, JSONLDOrganization, etc.)
+// against each of the given URLs,
+// instead of against the domain's home page,
+// and uses the best of their results.
+// This is useful when the relevant pages are already known,
+// as from a sitemap.
+// The other tests run once,
+// against the host of the first URL.
+// A URL without a scheme defaults to http,
+// as in "coalition.com/about".
+//
+// The pages are fetched one at a time,
+// each counting as a separate request against MaxRequestsPerMatch.
+// Aliases and Official are not consulted.
+func (m Matcher) MatchURLs(ctx context.Context, ref string, urls []string) (float32, error) {
+	detail, err := m.MatchURLsDetail(ctx, ref, urls)
+	if err != nil {
+		return 0, err
+	}
+	return detail.Score, nil
+}
+
+// MatchURLsDetail is like MatchURLs
+// but explains its result with the outcome of each test,
+// as MatchDetail does.
+// The outcomes of the page tests are those for the best of urls,
+// and Detail.EvidenceURL tells which one that was,
+// if any page test passed.
+func (m Matcher) MatchURLsDetail(ctx context.Context, ref string, urls []string) (*Detail, error) {
+	detail, err := m.matchURLs(ctx, ref, urls)
+	if err != nil {
+		m.metrics().Match(0, err)
+		return nil, err
+	}
+	m.metrics().Match(detail.Score, nil)
+	return detail, nil
+}
+
+func (m Matcher) matchURLs(ctx context.Context, ref string, urls []string) (*Detail, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no URLs")
+	}
+
+	var hostTests, pageTests []testDef
+	for _, t := range builtinTests {
+		if t.homePage {
+			pageTests = append(pageTests, t)
+		} else {
+			hostTests = append(hostTests, t)
+		}
+	}
+
+	in, err := m.newMatchInput(ref, urls[0])
+	if err != nil {
+		return nil, err
+	}
+	hostOutcomes, err := m.runTestsDetail(ctx, in, hostTests)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		bestOutcomes []TestOutcome
+		bestFetch    *FetchInfo
+		best         int
+	)
+	for i, u := range urls {
+		pageIn := in
+		if i > 0 {
+			pageIn, err = m.newMatchInput(ref, u)
+			if err != nil {
+				return nil, err
+			}
+			pageIn.budget = in.budget
+			in.budget.nextPage()
+		}
+		// The fetch of the page
+		// (see matchInput.startHomePage)
+		// is stopped, if it's still going,
+		// when its tests are done.
+		pageCtx, cancel := context.WithCancel(ctx)
+		pageOutcomes, err := m.runTestsDetail(pageCtx, pageIn, pageTests)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		fetched := pageIn.fetch.wait()
+		if pageScore := sumOutcomes(pageOutcomes); i == 0 || pageScore > best {
+			best = pageScore
+			bestOutcomes = pageOutcomes
+			bestFetch = fetched
+		}
+	}
+
+	// Report the outcomes in the usual order.
+	byName := make(map[string]TestOutcome)
+	for _, o := range append(hostOutcomes, bestOutcomes...) {
+		byName[o.Test] = o
+	}
+	detail := &Detail{Ref: ref, Fetch: bestFetch}
+	for _, t := range builtinTests {
+		if o, ok := byName[testNames[t.typ]]; ok {
+			detail.Outcomes = append(detail.Outcomes, o)
+		}
+	}
+
+	score, tooFew := m.passingSum(detail.Outcomes)
+	detail.TooFewPassed = tooFew
+	detail.Score = m.scale(m.weighScore(score, in, detail))
+	return detail, nil
+}
@@ -0,0 +1,114 @@
+package coalition
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMatchURLs(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/", pageHandler("text/html", "<html><body>Welcome</body></html>"))
+	mux.Handle("/contact", pageHandler("text/html", "<html><body>Write to us</body></html>"))
+	mux.Handle("/about", pageHandler("text/html", "<html><body>about coalition</body></html>"))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	domain := strings.TrimPrefix(srv.URL, "http://")
+
+	cases := []struct {
+		name                string
+		urls                []string
+		maxRequestsPerMatch int
+		wantWeb             bool
+	}{
+		{name: "one_has_brand", urls: []string{srv.URL + "/", srv.URL + "/about", srv.URL + "/contact"}, wantWeb: true},
+		{name: "none_has_brand", urls: []string{srv.URL + "/", srv.URL + "/contact"}},
+		{name: "no_scheme", urls: []string{domain + "/contact", domain + "/about"}, wantWeb: true},
+		{name: "over_budget", urls: []string{srv.URL + "/", srv.URL + "/contact", srv.URL + "/about"}, maxRequestsPerMatch: 2},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// Only the web test can pass for a host like 127.0.0.1.
+			matcher := NewMatcher()
+			matcher.MaxRequestsPerMatch = c.maxRequestsPerMatch
+
+			want := matcher.scale(0)
+			if c.wantWeb {
+				want = matcher.scale(matcher.Scores[testWebPageRef])
+			}
+
+			got, err := matcher.MatchURLs(context.Background(), "Coalition", c.urls)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+
+	t.Run("no_urls", func(t *testing.T) {
+		if _, err := NewMatcher().MatchURLs(context.Background(), "Coalition", nil); err == nil {
+			t.Error("got no error, want one")
+		}
+	})
+}
+
+func TestEvidenceURL(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/", pageHandler("text/html", "<html><body>Welcome</body></html>"))
+	mux.Handle("/contact", pageHandler("text/html", "<html><body>Write to us</body></html>"))
+	mux.Handle("/about", pageHandler("text/html", "<html><body>about coalition</body></html>"))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	matcher := NewMatcher()
+
+	t.Run("about", func(t *testing.T) {
+		detail, err := matcher.MatchURLsDetail(context.Background(), "Coalition", []string{srv.URL + "/", srv.URL + "/about", srv.URL + "/contact"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := detail.EvidenceURL()
+		if !ok {
+			t.Fatal("got no evidence URL")
+		}
+		if want := srv.URL + "/about"; got != want {
+			t.Errorf("got evidence URL %s, want %s", got, want)
+		}
+		if detail.Fetch == nil || detail.Fetch.URL != srv.URL+"/about" {
+			t.Errorf("got fetch %+v, want one for /about", detail.Fetch)
+		}
+	})
+
+	t.Run("none", func(t *testing.T) {
+		detail, err := matcher.MatchURLsDetail(context.Background(), "Coalition", []string{srv.URL + "/", srv.URL + "/contact"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, ok := detail.EvidenceURL(); ok {
+			t.Errorf("got evidence URL %s, want none", got)
+		}
+	})
+
+	t.Run("home_page", func(t *testing.T) {
+		srv, domain := newTestServer("text/html", "<html><body>coalition</body></html>")
+		defer srv.Close()
+
+		detail, err := matcher.MatchDetail(context.Background(), "Coalition", domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := detail.EvidenceURL()
+		if !ok {
+			t.Fatal("got no evidence URL")
+		}
+		if want := srv.URL + "/"; got != want {
+			t.Errorf("got evidence URL %s, want %s", got, want)
+		}
+	})
+}
@@ -0,0 +1,667 @@
+package coalition
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/bobg/htree"
+	"golang.org/x/net/html"
+)
+
+// webPage is the fetched home page of a domain.
+type webPage struct {
+	// Tree is the parsed HTML of the page.
+	// It is nil if the page is not HTML.
+	// Use htmlTree to get it,
+	// since the page may not be parsed yet
+	// (see raw).
+	tree *html.Node
+
+	// Raw is the unparsed HTML of the page,
+	// read in full,
+	// when it was read for streaming
+	// (see Matcher.StreamPageText).
+	// It is parsed into tree only when some test needs that.
+	raw       []byte
+	parseOnce sync.Once
+
+	// Text is the content of the page if it is plain text,
+	// or the text extracted from it if it is a document
+	// (see ContentText and ContentDocument).
+	text string
+
+	// Header is the header of the HTTP response that delivered the page.
+	header http.Header
+
+	// URL is the final URL of the page,
+	// after any redirects.
+	url *url.URL
+
+	// Status is the HTTP status code of the response.
+	status int
+
+	// ContentType is the media type of the page,
+	// without parameters.
+	contentType string
+}
+
+// ContentKind tells how the web tests treat a page of a given media type.
+// See Matcher.ContentTypes.
+type ContentKind int
+
+const (
+	// ContentIgnored pages contribute nothing to the web tests,
+	// apart from their response headers.
+	ContentIgnored ContentKind = iota
+
+	// ContentHTML pages are parsed as HTML.
+	ContentHTML
+
+	// ContentText pages are matched directly as plain text.
+	ContentText
+
+	// ContentDocument pages are converted to plain text
+	// by the Matcher's DocumentExtractor
+	// (and ignored if it has none).
+	ContentDocument
+)
+
+// DocumentExtractor extracts plain text from documents,
+// like PDFs,
+// for the web tests.
+// See Matcher.DocumentExtractor.
+type DocumentExtractor interface {
+	// ExtractText returns the text of the document in r,
+	// whose media type is contentType
+	// (e.g. "application/pdf").
+	ExtractText(contentType string, r io.Reader) (string, error)
+}
+
+// DefaultContentTypes is the value used when Matcher.ContentTypes is nil.
+var DefaultContentTypes = map[string]ContentKind{
+	"text/html":             ContentHTML,
+	"application/xhtml+xml": ContentHTML,
+	"text/plain":            ContentText,
+}
+
+// errBudgetExceeded is the result of a home-page fetch
+// that was cut short by the time budget of the test performing it.
+// Other tests sharing the fetch do not pass either.
+// It is also the result of a request that the rate limits
+// could not permit within that budget
+// (see Matcher.waitToFetch).
+var errBudgetExceeded = errors.New("time budget exceeded")
+
+// sniffLen is the number of bytes that http.DetectContentType examines.
+const sniffLen = 512
+
+var (
+	// ErrWebFetch matches (using errors.Is) any WebFetchError.
+	ErrWebFetch = errors.New("web fetch failed")
+
+	// ErrContentType is wrapped by the WebFetchError for a page
+	// whose Content-Type header can't be parsed,
+	// and whose content is not recognizable either.
+	ErrContentType = errors.New("bad content type")
+)
+
+// WebFetchError is the error produced when a domain's home page can't be fetched or read,
+// as when the host can't be resolved or reached,
+// the TLS handshake fails,
+// or the response is malformed.
+// A match that gets one may be worth retrying,
+// or treating as though the web tests did not pass.
+// Use errors.Is(err, ErrWebFetch) to detect it,
+// and errors.As to inspect it.
+type WebFetchError struct {
+	// URL is the URL that could not be fetched.
+	URL string
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *WebFetchError) Error() string {
+	return fmt.Sprintf("fetching %s: %s", e.URL, e.Err)
+}
+
+// Unwrap returns e.Err.
+func (e *WebFetchError) Unwrap() error {
+	return e.Err
+}
+
+// Is tells whether target is ErrWebFetch.
+func (e *WebFetchError) Is(target error) bool {
+	return target == ErrWebFetch
+}
+
+// pageFetch is a fetch of a domain's home page,
+// performed at most once
+// and shared by all the tests that need it.
+type pageFetch struct {
+	mu      sync.Mutex
+	started bool
+	ready   chan struct{} // closed when page and err are set
+	page    *webPage
+	err     error
+}
+
+func newPageFetch() *pageFetch {
+	return &pageFetch{ready: make(chan struct{})}
+}
+
+// info describes the outcome of f,
+// or returns nil if f never started or has not finished.
+func (f *pageFetch) info() *FetchInfo {
+	f.mu.Lock()
+	started := f.started
+	f.mu.Unlock()
+	if !started {
+		return nil
+	}
+
+	select {
+	case <-f.ready:
+	default:
+		return nil
+	}
+
+	if f.err != nil {
+		return &FetchInfo{Err: f.err}
+	}
+	return &FetchInfo{
+		URL:         f.page.url.String(),
+		StatusCode:  f.page.status,
+		ContentType: f.page.contentType,
+	}
+}
+
+// wait waits for f to finish,
+// if it has started,
+// and returns its info.
+// The context of the fetch should be done
+// (see matchInput.startHomePage).
+func (f *pageFetch) wait() *FetchInfo {
+	f.mu.Lock()
+	started := f.started
+	f.mu.Unlock()
+	if started {
+		<-f.ready
+	}
+	return f.info()
+}
+
+// pageURL returns the final URL of the page f fetched,
+// or the empty string if f has not successfully fetched one.
+func (f *pageFetch) pageURL() string {
+	if info := f.info(); info != nil {
+		return info.URL
+	}
+	return ""
+}
+
+// homePage returns the page at in.webURL,
+// starting the fetch under ctx if it hasn't started yet
+// (see startHomePage).
+// Each caller waits for the fetch to finish,
+// or for its own ctx to expire.
+func (in *matchInput) homePage(ctx context.Context, m Matcher) (*webPage, error) {
+	f := in.fetch
+	in.startHomePage(ctx, m)
+
+	select {
+	case <-f.ready:
+		return f.page, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// startHomePage starts fetching the page at in.webURL in the background,
+// under ctx,
+// unless the fetch has already started.
+// RunTestsDetail starts it under the context of the whole match,
+// not of any one test,
+// so that a test with a short time budget
+// (see Matcher.TestTimeouts)
+// doesn't cut it short for the others waiting for it.
+func (in *matchInput) startHomePage(ctx context.Context, m Matcher) {
+	f := in.fetch
+
+	f.mu.Lock()
+	first := !f.started
+	f.started = true
+	f.mu.Unlock()
+
+	if !first {
+		return
+	}
+	go func() {
+		f.page, f.err = m.fetchHomePage(ctx, in.webURL, in.budget)
+		if f.err != nil && ctx.Err() != nil {
+			f.err = errBudgetExceeded
+		}
+		close(f.ready)
+	}()
+}
+
+// This fetches the home page at u.
+// If its host begins with "www." but can't be reached,
+// the apex domain is tried instead.
+// If the page is a stub with a <meta http-equiv="refresh"> element
+// pointing elsewhere in the same site,
+// that target is fetched instead
+// (up to m.MaxMetaRefreshes times).
+// The initial request is assumed to be reserved in budget already;
+// the others are taken from it
+// and skipped when it runs out.
+// Errors are reported as *WebFetchError.
+func (m Matcher) fetchHomePage(ctx context.Context, u *url.URL, budget *requestBudget) (*webPage, error) {
+	resp, err := m.get(ctx, u)
+	if err != nil && ctx.Err() == nil && hasWWW(u.Host) && budget.take() {
+		apex := *u
+		apex.Host = u.Host[len("www."):]
+		resp, err = m.get(ctx, &apex)
+		if err != nil {
+			return nil, &WebFetchError{URL: apex.String(), Err: err}
+		}
+	}
+	if err != nil {
+		return nil, &WebFetchError{URL: u.String(), Err: err}
+	}
+
+	maxRefreshes := m.MaxMetaRefreshes
+	if maxRefreshes == 0 {
+		maxRefreshes = DefaultMaxMetaRefreshes
+	}
+
+	for refreshes := 0; ; refreshes++ {
+		page, err := m.readPage(resp)
+		if err != nil {
+			return nil, &WebFetchError{URL: resp.Request.URL.String(), Err: err}
+		}
+		if refreshes >= maxRefreshes {
+			return page, nil
+		}
+		target := metaRefreshTarget(page, resp.Request.URL)
+		if target == nil || !m.sameSite(target, resp.Request.URL) || !budget.take() {
+			return page, nil
+		}
+		resp, err = m.get(ctx, target)
+		if err != nil {
+			return nil, &WebFetchError{URL: target.String(), Err: err}
+		}
+	}
+}
+
+// This reads and parses the body of resp, then closes it.
+// How it does so depends on the media type of resp
+// (see Matcher.ContentTypes).
+// If the Content-Type header is missing or malformed,
+// as it is from many misconfigured servers,
+// the media type is determined by sniffing the content
+// (see http.DetectContentType).
+// Only if that finds nothing recognizable
+// for a malformed header
+// is it an error.
+func (m Matcher) readPage(resp *http.Response) (*webPage, error) {
+	defer resp.Body.Close()
+
+	body := bufio.NewReaderSize(resp.Body, sniffLen)
+
+	ctField := resp.Header.Get("Content-Type")
+	contentType, _, err := mime.ParseMediaType(ctField)
+	if err != nil {
+		// Peek's error, if any, recurs when the body is read below.
+		head, _ := body.Peek(sniffLen)
+		contentType, _, _ = mime.ParseMediaType(http.DetectContentType(head))
+		if ctField != "" && contentType == "application/octet-stream" {
+			return nil, fmt.Errorf("%w %q: %s", ErrContentType, ctField, err)
+		}
+	}
+
+	contentTypes := m.ContentTypes
+	if contentTypes == nil {
+		contentTypes = DefaultContentTypes
+	}
+
+	page := &webPage{
+		header:      resp.Header,
+		url:         resp.Request.URL,
+		status:      resp.StatusCode,
+		contentType: contentType,
+	}
+
+	switch contentTypes[contentType] {
+	case ContentHTML:
+		var r io.Reader = body
+		if m.HeadOnly {
+			head, err := readHead(body)
+			if err != nil {
+				return nil, err
+			}
+			r = bytes.NewReader(head)
+		}
+		if m.StreamPageText {
+			// The other tests examining the page need it too,
+			// so it can't be matched as it arrives.
+			page.raw, err = ioutil.ReadAll(r)
+		} else {
+			page.tree, err = html.Parse(r)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+	case ContentText:
+		b, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		page.text = string(b)
+
+	case ContentDocument:
+		if m.DocumentExtractor == nil {
+			break
+		}
+		// As with HTML text extraction,
+		// a document whose text can't be extracted simply doesn't match.
+		if text, err := m.DocumentExtractor.ExtractText(contentType, body); err == nil {
+			page.text = text
+		}
+	}
+
+	return page, nil
+}
+
+// This requests u,
+// subject to m's rate limits,
+// or gets the response from m.PageCache.
+// Only successful (2xx) responses,
+// no larger than maxCachedPageLen,
+// are cached.
+func (m Matcher) get(ctx context.Context, u *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(m.withConnectAddresses(m.withProxyProvider(ctx)), "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var key string
+	if m.PageCache != nil {
+		key, req, err = pageCacheKey(req)
+		if err != nil {
+			return nil, err
+		}
+		if page, ok := m.PageCache.Get(key); ok {
+			m.metrics().CacheHit()
+			return page.response()
+		}
+		m.metrics().CacheMiss()
+	}
+
+	if err := m.waitToFetch(ctx, u.Host); err != nil {
+		return nil, err
+	}
+
+	resp, err := m.httpClient().Do(req)
+	m.metrics().Fetch(err)
+	if err != nil || m.PageCache == nil || resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp, err
+	}
+
+	page, err := newCachedPage(resp)
+	if err != nil {
+		return nil, err
+	}
+	if page == nil {
+		return resp, nil // too large to cache
+	}
+	m.PageCache.Put(key, page)
+	return page.response()
+}
+
+// This returns the key in a PageCache for req:
+// its URL,
+// plus the address it connects to,
+// if that is overridden (see Matcher.ConnectAddresses),
+// and the proxy it goes through,
+// if that is chosen by a ProxyProvider,
+// since either may change the response.
+// The proxy is chosen now,
+// and the resulting request uses it.
+func pageCacheKey(req *http.Request) (string, *http.Request, error) {
+	key := req.URL.String()
+
+	ctx := req.Context()
+	if addr := hostPort(req.URL); connectAddress(ctx, addr) != addr {
+		key += " connect=" + connectAddress(ctx, addr)
+	}
+
+	if _, ok := ctx.Value(proxyKey{}).(ProxyProvider); ok {
+		proxy, err := proxyFromContext(req)
+		if err != nil {
+			return "", nil, err
+		}
+		req = req.WithContext(context.WithValue(ctx, proxyKey{}, fixedProxy{u: proxy}))
+		if proxy != nil {
+			p := *proxy
+			p.User = nil // no credentials in cache keys
+			key += " proxy=" + p.String()
+		}
+	}
+
+	return key, req, nil
+}
+
+// htmlTree returns the parsed HTML of page,
+// parsing it now if it was read for streaming
+// (see Matcher.StreamPageText).
+// It is nil if the page is not HTML,
+// or can't be parsed.
+func (page *webPage) htmlTree() *html.Node {
+	page.parseOnce.Do(func() {
+		if page.tree == nil && page.raw != nil {
+			page.tree, _ = html.Parse(bytes.NewReader(page.raw))
+		}
+	})
+	return page.tree
+}
+
+// This tells whether domain begins with a "www." label.
+func hasWWW(domain string) bool {
+	return strings.HasPrefix(strings.ToLower(domain), "www.")
+}
+
+// extractText extracts plain text from HTML.
+// This comes from my htree package.
+// See https://godoc.org/github.com/bobg/htree#Text.
+// It is a variable so tests can replace it.
+var extractText = htree.Text
+
+func runWebPageRefTest(ctx context.Context, m Matcher, in *matchInput) (bool, error) {
+	page, err := in.homePage(ctx, m)
+	if err != nil {
+		return false, err
+	}
+	if doWebPageRefTest(page, m.PageRegions, m.textMatcher(in, in.re)) {
+		return true, nil
+	}
+	if m.CrawlDepth == 0 {
+		return false, nil
+	}
+	u := m.crawlForRef(ctx, in, page)
+	if u == nil {
+		return false, nil
+	}
+	in.crawledURL = u.String()
+	return true, nil
+}
+
+// This reports whether tm matches the text of page,
+// or of the given regions of it,
+// if there are any
+// (see Matcher.PageRegions).
+// A page read for streaming
+// (see Matcher.StreamPageText)
+// is matched without parsing it,
+// unless there are regions.
+// A page whose text can't be extracted simply doesn't pass:
+// one malformed page should not cause an otherwise-good match to fail.
+func doWebPageRefTest(page *webPage, regions []PageRegion, tm textMatcher) bool {
+	if page.raw != nil && len(regions) == 0 {
+		return streamMatch(bytes.NewReader(page.raw), tm)
+	}
+
+	tree := page.htmlTree()
+	if tree == nil {
+		// Plain text has no regions.
+		return len(regions) == 0 && tm.match(page.text)
+	}
+
+	if len(regions) == 0 {
+		text, err := extractText(tree)
+		if err != nil {
+			return false
+		}
+		return tm.match(text) // TODO: inspect submatches for significant words.
+	}
+
+	var found bool
+	inRegion := func(n *html.Node) bool {
+		for _, r := range regions {
+			if r.matches(n) {
+				return true
+			}
+		}
+		return false
+	}
+	htree.FindAllEls(tree, inRegion, func(n *html.Node) error {
+		if found {
+			return nil
+		}
+		if text, err := extractText(n); err == nil && tm.match(text) {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
+// PageRegion selects elements of a web page
+// by tag name, class, or both.
+// See Matcher.PageRegions.
+type PageRegion struct {
+	// Tag is the element's tag name, like "footer".
+	// If empty, any tag matches.
+	Tag string
+
+	// Class is one of the element's classes, like "site-footer".
+	// If empty, any class (or none) matches.
+	Class string
+}
+
+// IdentityRegions are the parts of a web page
+// that typically contain the identity of the organization behind it.
+var IdentityRegions = []PageRegion{{Tag: "header"}, {Tag: "nav"}, {Tag: "footer"}}
+
+// This tells whether n is an element in region r.
+func (r PageRegion) matches(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	if r.Tag == "" && r.Class == "" {
+		return false
+	}
+	if r.Tag != "" && !strings.EqualFold(n.Data, r.Tag) {
+		return false
+	}
+	if r.Class == "" {
+		return true
+	}
+	for _, class := range strings.Fields(htree.ElAttr(n, "class")) {
+		if class == r.Class {
+			return true
+		}
+	}
+	return false
+}
+
+func runWebPageShortNameTest(ctx context.Context, m Matcher, in *matchInput) (bool, error) {
+	if len(in.norm) < 2 || len(in.significant) == 0 {
+		return false, nil
+	}
+
+	page, err := in.homePage(ctx, m)
+	if err != nil {
+		return false, err
+	}
+
+	// A single word is more prone to false positives than a whole phrase,
+	// so require it to appear as a whole word.
+	re, err := regexp.Compile(`\b` + regexp.QuoteMeta(in.significant[0]) + `\b`)
+	if err != nil {
+		return false, err
+	}
+
+	return doWebPageRefTest(page, m.PageRegions, m.textMatcher(in, re)), nil
+}
+
+// This returns the URL of the home page for domain.
+// The domain may carry a port, as in "example.com:8443",
+// and may be an IPv6 literal, with or without brackets
+// (which are required in the URL).
+func homePageURL(domain string) *url.URL {
+	host := domain
+	if h, port, err := net.SplitHostPort(domain); err == nil {
+		host = net.JoinHostPort(h, port)
+	} else if addr := strings.Trim(domain, "[]"); strings.Contains(addr, ":") && net.ParseIP(addr) != nil {
+		host = "[" + addr + "]"
+	}
+	return &url.URL{Scheme: "http", Host: host, Path: "/"} // TODO: try other URLs in the same domain, like /about
+}
+
+// This returns the URL the web tests fetch for domain.
+// Usually that's the home page (see homePageURL),
+// but callers may pass a full URL instead of a domain,
+// like "https://coalition.com/about",
+// in which case it is used as is
+// (minus any fragment).
+// A missing scheme defaults to http,
+// as in "coalition.com/about".
+func pageURL(domain string) *url.URL {
+	s := domain
+	if !strings.Contains(s, "://") {
+		if !strings.Contains(s, "/") {
+			return homePageURL(domain)
+		}
+		s = "http://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return homePageURL(domain)
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.Fragment = ""
+	return u
+}
+
+// This returns domain without any port,
+// and with the brackets removed from an IPv6 literal.
+func hostname(domain string) string {
+	u := url.URL{Host: homePageURL(domain).Host}
+	return u.Hostname()
+}
@@ -0,0 +1,520 @@
+package coalition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// pageHandler returns an HTTP handler that serves body with the given content type.
+func pageHandler(contentType, body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		fmt.Fprint(w, body)
+	})
+}
+
+// newTestServer starts an HTTP server that serves body with the given content type.
+// It returns the server and its "domain" (e.g. "127.0.0.1:12345").
+// The caller must close the server.
+func newTestServer(contentType, body string) (*httptest.Server, string) {
+	srv := httptest.NewServer(pageHandler(contentType, body))
+	return srv, strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestHomePageURL(t *testing.T) {
+	cases := []struct {
+		domain, wantURL, wantHostname string
+	}{
+		{domain: "coalitioninc.com", wantURL: "http://coalitioninc.com/", wantHostname: "coalitioninc.com"},
+		{domain: "example.com:8443", wantURL: "http://example.com:8443/", wantHostname: "example.com"},
+		{domain: "192.0.2.1", wantURL: "http://192.0.2.1/", wantHostname: "192.0.2.1"},
+		{domain: "2001:db8::1", wantURL: "http://[2001:db8::1]/", wantHostname: "2001:db8::1"},
+		{domain: "[2001:db8::1]", wantURL: "http://[2001:db8::1]/", wantHostname: "2001:db8::1"},
+		{domain: "[2001:db8::1]:8443", wantURL: "http://[2001:db8::1]:8443/", wantHostname: "2001:db8::1"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.domain, func(t *testing.T) {
+			if got := homePageURL(c.domain).String(); got != c.wantURL {
+				t.Errorf("got URL %s, want %s", got, c.wantURL)
+			}
+			if got := hostname(c.domain); got != c.wantHostname {
+				t.Errorf("got hostname %s, want %s", got, c.wantHostname)
+			}
+		})
+	}
+}
+
+func TestURLAsDomain(t *testing.T) {
+	rec := &RequestRecorder{
+		Responses: map[string]CannedResponse{
+			"https://coalitioninc.com/about": {ContentType: "text/html", Body: "<html><body>About coalition</body></html>"},
+			"http://coalitioninc.com/about":  {ContentType: "text/html", Body: "<html><body>About coalition</body></html>"},
+		},
+	}
+
+	cases := []struct {
+		domain, wantURL string
+		want            int
+	}{
+		{domain: "https://coalitioninc.com/about", wantURL: "https://coalitioninc.com/about", want: 100},
+		{domain: "https://www.coalitioninc.com/about#team", wantURL: "https://www.coalitioninc.com/about", want: 50},
+		{domain: "coalitioninc.com/about", wantURL: "http://coalitioninc.com/about", want: 100},
+		{domain: "HTTPS://CoalitionInc.com", wantURL: "https://CoalitionInc.com/", want: 50},
+	}
+
+	for _, c := range cases {
+		t.Run(c.domain, func(t *testing.T) {
+			rec.Reset()
+
+			matcher := NewMatcher()
+			matcher.Client = &http.Client{Transport: rec}
+
+			got, err := matcher.doMatch(context.Background(), "Coalition", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+
+			reqs := rec.Requests()
+			if len(reqs) == 0 {
+				t.Fatal("no requests")
+			}
+			if reqs[0].URL != c.wantURL {
+				t.Errorf("got request for %s, want %s", reqs[0].URL, c.wantURL)
+			}
+		})
+	}
+}
+
+func TestWebPageRefHostPort(t *testing.T) {
+	handler := pageHandler("text/html", "<html><body>Welcome to coalition</body></html>")
+
+	for _, network := range []string{"tcp4", "tcp6"} {
+		t.Run(network, func(t *testing.T) {
+			addr := "127.0.0.1:0"
+			if network == "tcp6" {
+				addr = "[::1]:0"
+			}
+			l, err := net.Listen(network, addr)
+			if err != nil {
+				t.Skipf("cannot listen on %s: %s", network, err)
+			}
+			srv := httptest.NewUnstartedServer(handler)
+			srv.Listener.Close()
+			srv.Listener = l
+			srv.Start()
+			defer srv.Close()
+
+			// E.g. "127.0.0.1:12345" or "[::1]:12345".
+			domain := strings.TrimPrefix(srv.URL, "http://")
+
+			matcher := NewMatcher()
+			got, err := matcher.doMatch(context.Background(), "Coalition", domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := matcher.Scores[testWebPageRef]; got != want {
+				t.Errorf("got %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestContentTypes(t *testing.T) {
+	cases := []struct {
+		name, contentType, body string
+		contentTypes            map[string]ContentKind
+		want                    int
+	}{
+		{
+			name:        "xhtml",
+			contentType: "application/xhtml+xml; charset=utf-8",
+			body:        `<?xml version="1.0" encoding="UTF-8"?><html xmlns="http://www.w3.org/1999/xhtml"><body><p>Welcome to coalition</p></body></html>`,
+			want:        50,
+		},
+		{
+			name:        "plain",
+			contentType: "text/plain",
+			body:        "Welcome to coalition",
+			want:        50,
+		},
+		{
+			name:        "image",
+			contentType: "image/png",
+			body:        "coalition",
+			want:        0,
+		},
+		{
+			name:         "plain_ignored",
+			contentType:  "text/plain",
+			body:         "Welcome to coalition",
+			contentTypes: map[string]ContentKind{"text/html": ContentHTML},
+			want:         0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv, domain := newTestServer(c.contentType, c.body)
+			defer srv.Close()
+
+			matcher := NewMatcher()
+			matcher.ContentTypes = c.contentTypes
+
+			got, err := matcher.doMatch(context.Background(), "Coalition", domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+type fakeExtractor map[string]string
+
+func (f fakeExtractor) ExtractText(contentType string, r io.Reader) (string, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	text, ok := f[string(b)]
+	if !ok {
+		return "", fmt.Errorf("cannot extract %s", contentType)
+	}
+	return text, nil
+}
+
+func TestDocumentExtractor(t *testing.T) {
+	const pdf = "%PDF-1.4 ..."
+
+	srv, domain := newTestServer("application/pdf", pdf)
+	defer srv.Close()
+
+	contentTypes := map[string]ContentKind{
+		"text/html":       ContentHTML,
+		"application/pdf": ContentDocument,
+	}
+
+	cases := []struct {
+		name         string
+		contentTypes map[string]ContentKind
+		extractor    DocumentExtractor
+		want         int
+	}{
+		{name: "extracted", contentTypes: contentTypes, extractor: fakeExtractor{pdf: "Welcome to coalition"}, want: 50},
+		{name: "extraction_error", contentTypes: contentTypes, extractor: fakeExtractor{}, want: 0},
+		{name: "no_extractor", contentTypes: contentTypes, want: 0},
+		{name: "not_configured", extractor: fakeExtractor{pdf: "Welcome to coalition"}, want: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			matcher := NewMatcher()
+			matcher.ContentTypes = c.contentTypes
+			matcher.DocumentExtractor = c.extractor
+
+			got, err := matcher.doMatch(context.Background(), "Coalition", domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestPageRegions(t *testing.T) {
+	const page = `<html>
+<body>
+<header><a href="/">Home</a></header>
+<main>Cyber insurance for everyone</main>
+<footer><div class="legal">&copy; 2020 coalition</div></footer>
+</body>
+</html>`
+
+	srv, domain := newTestServer("text/html", page)
+	defer srv.Close()
+
+	cases := []struct {
+		name    string
+		regions []PageRegion
+		want    int
+	}{
+		{name: "whole_page", want: 50},
+		{name: "identity", regions: IdentityRegions, want: 50},
+		{name: "header_nav", regions: []PageRegion{{Tag: "header"}, {Tag: "nav"}}, want: 0},
+		{name: "class", regions: []PageRegion{{Class: "legal"}}, want: 50},
+		{name: "tag_and_class", regions: []PageRegion{{Tag: "span", Class: "legal"}}, want: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			matcher := NewMatcher()
+			matcher.PageRegions = c.regions
+
+			got, err := matcher.doMatch(context.Background(), "Coalition", domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestWebPageRefTextError(t *testing.T) {
+	srv, domain := newTestServer("text/html", "<html><body>coalition</body></html>")
+	defer srv.Close()
+
+	// htree.Text fails only when writing to its buffer fails,
+	// which real HTML can't trigger,
+	// so simulate the failure.
+	orig := extractText
+	defer func() { extractText = orig }()
+	extractText = func(*html.Node) (string, error) {
+		return "", errors.New("simulated extraction failure")
+	}
+
+	matcher := NewMatcher()
+	got, err := matcher.doMatch(context.Background(), "Coalition", domain)
+	if err != nil {
+		t.Fatalf("got error %v, want none", err)
+	}
+	if got != 0 {
+		t.Errorf("got %d, want 0", got)
+	}
+}
+
+func TestWebPageRefTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-time.After(200 * time.Millisecond):
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><script type="application/ld+json">{"@type": "Organization", "name": "Coalition"}</script></head><body>Coalition</body></html>`)
+	}))
+	defer srv.Close()
+
+	domain := strings.TrimPrefix(srv.URL, "http://")
+
+	matcher := NewMatcher()
+	matcher.Scores[testJSONLDOrganization] = 20
+	matcher.TestTimeouts = map[testType]time.Duration{testWebPageRef: 50 * time.Millisecond}
+
+	// The web test exceeds its budget and does not pass.
+	// The JSON-LD test, which shares its fetch,
+	// still passes within its own budget,
+	// whichever of them started the fetch.
+	for i := 0; i < 3; i++ {
+		got, err := matcher.doMatch(context.Background(), "Coalition", domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 20 {
+			t.Errorf("got %d, want 20", got)
+		}
+	}
+}
+
+func TestWWWFallback(t *testing.T) {
+	srv, domain := newTestServer("text/html", "<html><body>coalition</body></html>")
+	defer srv.Close()
+
+	// There is no host named www.127.0.0.1,
+	// so the fetch falls back to 127.0.0.1.
+	matcher := NewMatcher()
+	got, err := matcher.doMatch(context.Background(), "Coalition", "www."+domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := matcher.Scores[testWebPageRef]; got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+}
+
+func TestWebFetchError(t *testing.T) {
+	t.Run("connection_refused", func(t *testing.T) {
+		srv, domain := newTestServer("text/html", "<html><body>coalition</body></html>")
+		srv.Close() // Nothing is listening now.
+
+		matcher := NewMatcher()
+		_, err := matcher.Match("Coalition", domain)
+		if !errors.Is(err, ErrWebFetch) {
+			t.Fatalf("got error %v, want ErrWebFetch", err)
+		}
+		var fetchErr *WebFetchError
+		if !errors.As(err, &fetchErr) {
+			t.Fatalf("got error of type %T, want *WebFetchError", err)
+		}
+		if want := "http://" + domain + "/"; fetchErr.URL != want {
+			t.Errorf("got URL %s, want %s", fetchErr.URL, want)
+		}
+		var opErr *net.OpError
+		if !errors.As(err, &opErr) {
+			t.Errorf("got error %v, want it to wrap a *net.OpError", err)
+		}
+		if errors.Is(err, ErrContentType) {
+			t.Errorf("got error %v, want it not to be ErrContentType", err)
+		}
+	})
+
+	t.Run("bad_content_type", func(t *testing.T) {
+		// Neither the header nor the content says what this is.
+		srv, domain := newTestServer("text/html; charset", "\x00\x01\x02coalition")
+		defer srv.Close()
+
+		matcher := NewMatcher()
+		_, err := matcher.Match("Coalition", domain)
+		if !errors.Is(err, ErrWebFetch) {
+			t.Fatalf("got error %v, want ErrWebFetch", err)
+		}
+		if !errors.Is(err, ErrContentType) {
+			t.Errorf("got error %v, want ErrContentType", err)
+		}
+	})
+}
+
+func TestContentSniffing(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType []string // nil means no Content-Type header
+		body        string
+		wantWeb     bool
+	}{
+		{name: "missing_html", body: "<html><body>coalition</body></html>", wantWeb: true},
+		{name: "missing_text", body: "coalition", wantWeb: true},
+		{name: "missing_binary", body: "\x00\x01\x02coalition"},
+		{name: "malformed_html", contentType: []string{"text/html; charset"}, body: "<!DOCTYPE html><html><body>coalition</body></html>", wantWeb: true},
+		{name: "empty_html", contentType: []string{""}, body: "<html><body>coalition</body></html>", wantWeb: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				// Setting the header to nil keeps the server from supplying its own.
+				w.Header()["Content-Type"] = c.contentType
+				fmt.Fprint(w, c.body)
+			}))
+			defer srv.Close()
+
+			matcher := NewMatcher()
+			got, err := matcher.doMatch(context.Background(), "Coalition", strings.TrimPrefix(srv.URL, "http://"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want int
+			if c.wantWeb {
+				want = matcher.Scores[testWebPageRef]
+			}
+			if got != want {
+				t.Errorf("got %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestWebPageShortNameTest(t *testing.T) {
+	cases := []struct {
+		name, ref, page string
+		want            int
+	}{
+		{
+			name: "short_name",
+			ref:  "Coalition Insurance Solutions, Inc.",
+			page: "<html><body>Welcome to Coalition. We make cyber insurance simple.</body></html>",
+			want: 20,
+		},
+		{
+			name: "full_name",
+			ref:  "Coalition Insurance Solutions, Inc.",
+			page: "<html><body>Welcome to coalition insurance solutions</body></html>",
+			want: 50, // WebPageRef passes, so WebPageShortName doesn't run
+		},
+		{
+			name: "not_whole_word",
+			ref:  "Coalition Insurance Solutions, Inc.",
+			page: "<html><body>Join the coalitions for cyber insurance</body></html>",
+			want: 0,
+		},
+		{
+			name: "single_word",
+			ref:  "Coalition, Inc.",
+			page: "<html><body>Welcome to Coalition</body></html>",
+			want: 50, // WebPageRef passes, and the short name is the full name anyway
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv, domain := newTestServer("text/html", c.page)
+			defer srv.Close()
+
+			matcher := NewMatcher()
+			matcher.Scores[testWebPageShortName] = 20
+
+			got, err := matcher.doMatch(context.Background(), c.ref, domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func parseHTML(s string) (*html.Node, error) {
+	return html.Parse(strings.NewReader(s))
+}
+
+func TestWebPageRefFolding(t *testing.T) {
+	cases := []struct {
+		ref, page string
+		want      int
+	}{
+		{ref: "Coalition, Inc.", page: "<html><body>Welcome to Coalition</body></html>", want: 50},
+		{ref: "Coalition, Inc.", page: "<html><body>WELCOME TO COALITION</body></html>", want: 50},
+		{ref: "Société Générale", page: "<html><body>Bienvenue chez Société Générale</body></html>", want: 50},
+		{ref: "Societe Generale", page: "<html><body>SOCIÉTÉ GÉNÉRALE</body></html>", want: 50},
+		{ref: "Coalition, Inc.", page: "<html><body>Welcome to Acme</body></html>", want: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.page, func(t *testing.T) {
+			srv, domain := newTestServer("text/html", c.page)
+			defer srv.Close()
+
+			for _, stream := range []bool{false, true} {
+				matcher := NewMatcher()
+				matcher.StreamPageText = stream
+
+				got, err := matcher.doMatch(context.Background(), c.ref, domain)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != c.want {
+					t.Errorf("stream %v: got %d, want %d", stream, got, c.want)
+				}
+			}
+		})
+	}
+}
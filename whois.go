@@ -0,0 +1,78 @@
+package coalition
+
+import (
+	"context"
+	"time"
+)
+
+// WhoisProvider can look up the registration data for a domain.
+// Implementations typically query WHOIS or RDAP servers.
+type WhoisProvider interface {
+	// CreationDate returns the time at which domain was registered.
+	CreationDate(ctx context.Context, domain string) (time.Time, error)
+}
+
+// DefaultNewDomainAge is the age below which the NewDomain test considers a domain newly registered,
+// when Matcher.NewDomainAge is zero.
+const DefaultNewDomainAge = 90 * 24 * time.Hour
+
+// DomainAgeWeight is the fraction of its score
+// that the NewDomain test earns
+// for a domain younger than MaxAge
+// (see Matcher.NewDomainWeights).
+type DomainAgeWeight struct {
+	MaxAge time.Duration
+	Weight float32
+}
+
+// This grades NewDomain
+// using the registration data of the registrable part of the domain
+// (see Matcher.registrable),
+// since a subdomain has none of its own.
+// If the WhoisProvider fails,
+// as when it is rate-limited or knows nothing of the domain's TLD,
+// the test does not pass.
+func runNewDomainTest(ctx context.Context, m Matcher, in *matchInput) (float32, error) {
+	if m.Whois == nil {
+		return 0, nil
+	}
+	created, err := m.Whois.CreationDate(ctx, m.registrable(in.domain))
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, err
+		}
+		return 0, nil
+	}
+	return m.newDomainWeight(m.now().Sub(created)), nil
+}
+
+// This is the fraction of its score that NewDomain earns
+// for a domain of the given age,
+// according to m.NewDomainWeights,
+// or else m.NewDomainAge.
+func (m Matcher) newDomainWeight(age time.Duration) float32 {
+	if len(m.NewDomainWeights) > 0 {
+		for _, w := range m.NewDomainWeights {
+			if age < w.MaxAge {
+				return w.Weight
+			}
+		}
+		return 0
+	}
+
+	threshold := m.NewDomainAge
+	if threshold == 0 {
+		threshold = DefaultNewDomainAge
+	}
+	if age < threshold {
+		return 1
+	}
+	return 0
+}
+
+func (m Matcher) now() time.Time {
+	if m.Now != nil {
+		return m.Now()
+	}
+	return time.Now()
+}
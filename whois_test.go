@@ -0,0 +1,118 @@
+package coalition
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type fakeWhois map[string]time.Time
+
+func (w fakeWhois) CreationDate(_ context.Context, domain string) (time.Time, error) {
+	if created, ok := w[domain]; ok {
+		return created, nil
+	}
+	return time.Time{}, fmt.Errorf("no WHOIS data for %s", domain)
+}
+
+func TestNewDomainTest(t *testing.T) {
+	now := time.Now()
+
+	whois := fakeWhois{
+		"coalitioninc.com":   now.Add(-10 * 365 * 24 * time.Hour),
+		"coalition-inc.com":  now.Add(-60 * 24 * time.Hour),
+		"coalition-help.com": now.Add(-3 * 24 * time.Hour),
+	}
+
+	weights := []DomainAgeWeight{
+		{MaxAge: 7 * 24 * time.Hour, Weight: 1},
+		{MaxAge: 90 * 24 * time.Hour, Weight: 0.5},
+	}
+
+	cases := []struct {
+		domain       string
+		newDomainAge time.Duration
+		weights      []DomainAgeWeight
+		want         int
+	}{
+		{domain: "coalitioninc.com", want: 50},
+		{domain: "coalition-inc.com", want: 30},
+		{domain: "coalition-inc.com", newDomainAge: 30 * 24 * time.Hour, want: 50},
+		{domain: "coalition-help.com", want: 20},
+		{domain: "coalition-help.com", newDomainAge: 24 * time.Hour, want: 40},
+		{domain: "shop.coalition-help.com", want: 20}, // the registrable domain is new
+		{domain: "unknown.com", want: 0},              // WHOIS fails: not new
+		{domain: "coalitioninc.com", weights: weights, want: 50},
+		{domain: "coalition-inc.com", weights: weights, want: 40},
+		{domain: "coalition-help.com", weights: weights, want: 20},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case_%02d", i+1), func(t *testing.T) {
+			matcher := NewMatcher()
+			delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+			matcher.Scores[testNewDomain] = -20
+			matcher.Whois = whois
+			matcher.NewDomainAge = c.newDomainAge
+			matcher.NewDomainWeights = c.weights
+
+			got, err := matcher.doMatch(context.Background(), "Coalition, Inc", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+
+	t.Run("no_provider", func(t *testing.T) {
+		matcher := NewMatcher()
+		delete(matcher.Scores, testWebPageRef)
+		matcher.Scores[testNewDomain] = -20
+
+		got, err := matcher.doMatch(context.Background(), "Coalition, Inc", "coalition-help.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 40 {
+			t.Errorf("got %d, want 40", got)
+		}
+	})
+}
+
+func TestNow(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	whois := fakeWhois{
+		"coalition.com": now.Add(-DefaultNewDomainAge),
+		"coalition.net": now.Add(-DefaultNewDomainAge + time.Second),
+	}
+
+	cases := []struct {
+		domain string
+		want   int
+	}{
+		{domain: "coalition.com", want: 50}, // exactly DefaultNewDomainAge old: not new
+		{domain: "coalition.net", want: 30}, // a second younger: new
+	}
+
+	matcher := NewMatcher()
+	delete(matcher.Scores, testWebPageRef) // No network requests during unit tests.
+	matcher.Scores[testNewDomain] = -20
+	matcher.Whois = whois
+	matcher.Now = func() time.Time { return now }
+
+	for _, c := range cases {
+		t.Run(c.domain, func(t *testing.T) {
+			got, err := matcher.doMatch(context.Background(), "Coalition", c.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}